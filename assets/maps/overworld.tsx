@@ -0,0 +1,9 @@
+<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" tiledversion="1.10.2" name="overworld" tilewidth="32" tileheight="32" tilecount="2" columns="2">
+ <image source="../sprites/tiles/overworld.png" width="64" height="32"/>
+ <tile id="1">
+  <properties>
+   <property name="collides" type="bool" value="true"/>
+  </properties>
+ </tile>
+</tileset>
@@ -1,4 +1,6 @@
 #version 410 core
+#include "include/spriteColor.glsl"
+
 in vec2 TexCoord;
 
 uniform sampler2D tex;
@@ -7,8 +9,5 @@ out vec4 FragColor;
 
 void main()
 {
-    vec4 texColor = texture(tex, TexCoord);
-    if (texColor.a <= 0.01)
-        discard;
-    FragColor = texColor;
-}
\ No newline at end of file
+    FragColor = spriteColor(texture(tex, TexCoord));
+}
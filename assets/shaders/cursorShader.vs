@@ -1,16 +1,13 @@
 #version 410 core
-// pos2D, tex2D
-layout (location = 0) in vec4 vPos;
+#include "include/spriteTransform.glsl"
 
-uniform mat4 transform;
-uniform mat4 scale;
-uniform mat4 projection;
-
-out vec2 TexCoord;
+// fixed depth layer. Can be overridden with ShaderFiles.Defines
+#ifndef LAYER_DEPTH
+#define LAYER_DEPTH -1.0f
+#endif
 
 void main()
 {
-    gl_Position = projection * (transform * (scale * vec4(vPos.x, vPos.y, -vPos.y, 1.0f)));
-    gl_Position.z = -1.0f;
-    TexCoord = vPos.zw;
+    gl_Position = spritePosition(-vPos.y);
+    gl_Position.z = LAYER_DEPTH;
 }
\ No newline at end of file
@@ -0,0 +1,17 @@
+#version 410 core
+#include "include/spriteColor.glsl"
+
+in vec2 TexCoord;
+
+uniform sampler2D tex;
+// set per sprite with a Material. 0 (the default) means no flash
+uniform vec4 flashColor;
+uniform float flashAmount;
+
+out vec4 FragColor;
+
+void main()
+{
+    vec4 color = spriteColor(texture(tex, TexCoord));
+    FragColor = vec4(mix(color.rgb, flashColor.rgb, flashAmount), color.a);
+}
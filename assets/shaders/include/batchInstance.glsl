@@ -0,0 +1,28 @@
+// Shared by the vertex shaders of batch sprites (see batch.go). Every instance is a quad placed
+// by its own attributes instead of the transform and scale uniforms.
+// pos2D, tex2D
+layout (location = 0) in vec4 vPos;
+// screen x, screen y, width, height
+layout (location = 1) in vec4 instancePlacement;
+layout (location = 2) in vec4 instanceColor;
+// x, y, width, height of the part of the texture to draw
+layout (location = 3) in vec4 instanceRegion;
+layout (location = 4) in float instanceRotation;
+
+uniform mat4 projection;
+
+out vec2 TexCoord;
+out vec4 InstanceColor;
+
+// Where this vertex of the instance ends up on screen. z is the depth before projection.
+vec4 instancePosition(float z)
+{
+    vec2 corner = (vPos.xy - 0.5f) * instancePlacement.zw;
+    float s = sin(instanceRotation);
+    float c = cos(instanceRotation);
+    vec2 pos = instancePlacement.xy + vec2(c * corner.x - s * corner.y, s * corner.x + c * corner.y);
+
+    TexCoord = instanceRegion.xy + vPos.zw * instanceRegion.zw;
+    InstanceColor = instanceColor;
+    return projection * vec4(pos, z, 1.0f);
+}
@@ -0,0 +1,13 @@
+// Shared by all the sprite fragment shaders. Set every draw from the sprite's Tint and Opacity.
+uniform vec4 tint;
+uniform float opacity;
+
+// Final color of the sprite, before any effects. Discards (nearly) see-through pixels.
+vec4 spriteColor(vec4 texColor)
+{
+    vec4 color = texColor * tint;
+    color.a *= opacity;
+    if (color.a <= 0.01)
+        discard;
+    return color;
+}
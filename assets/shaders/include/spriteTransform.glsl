@@ -0,0 +1,16 @@
+// Shared by all the sprite vertex shaders.
+// pos2D, tex2D
+layout (location = 0) in vec4 vPos;
+
+uniform mat4 transform;
+uniform mat4 scale;
+uniform mat4 projection;
+
+out vec2 TexCoord;
+
+// Where this vertex of the sprite ends up on screen. z is the depth before projection.
+vec4 spritePosition(float z)
+{
+    TexCoord = vPos.zw;
+    return projection * (transform * (scale * vec4(vPos.x, vPos.y, z, 1.0f)));
+}
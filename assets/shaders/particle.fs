@@ -0,0 +1,14 @@
+#version 410 core
+#include "include/spriteColor.glsl"
+
+in vec2 TexCoord;
+in vec4 InstanceColor;
+
+uniform sampler2D tex;
+
+out vec4 FragColor;
+
+void main()
+{
+    FragColor = spriteColor(texture(tex, TexCoord) * InstanceColor);
+}
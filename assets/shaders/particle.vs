@@ -0,0 +1,9 @@
+#version 410 core
+#include "include/batchInstance.glsl"
+
+void main()
+{
+    // same depth as a world sprite whose bottom is at the particle's middle
+    gl_Position = instancePosition(instancePlacement.y);
+    gl_Position.z = gl_Position.z * 0.8f + 0.2f;
+}
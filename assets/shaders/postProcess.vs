@@ -0,0 +1,11 @@
+#version 410 core
+// Full screen quad for post process passes, already in clip space
+layout (location = 0) in vec4 vPos;
+
+out vec2 TexCoord;
+
+void main()
+{
+    gl_Position = vec4(vPos.xy, 0.0f, 1.0f);
+    TexCoord = vPos.zw;
+}
@@ -0,0 +1,24 @@
+#version 410 core
+
+in vec2 TexCoord;
+
+uniform sampler2D tex;
+// all 0 (the default) leaves the colors as they are
+uniform float brightness;
+uniform float contrast;
+uniform float saturation;
+uniform vec4 tintColor;
+uniform float tintAmount;
+
+out vec4 FragColor;
+
+void main()
+{
+    vec3 color = texture(tex, TexCoord).rgb;
+    color += brightness;
+    color = (color - 0.5f) * (1.0f + contrast) + 0.5f;
+    float luminance = dot(color, vec3(0.299f, 0.587f, 0.114f));
+    color = mix(vec3(luminance), color, 1.0f + saturation);
+    color = mix(color, color * tintColor.rgb, tintAmount);
+    FragColor = vec4(clamp(color, 0.0f, 1.0f), 1.0f);
+}
@@ -0,0 +1,29 @@
+#version 410 core
+
+in vec2 TexCoord;
+
+uniform sampler2D tex;
+uniform vec2 screenSize;
+uniform float time;
+// size of the big pixels in screen pixels. 0 or 1 is no pixelation
+uniform float pixelSize;
+// 0 (the default) is no scanlines
+uniform float scanlineStrength;
+
+out vec4 FragColor;
+
+void main()
+{
+    vec2 coord = TexCoord;
+    if (pixelSize > 1.0f)
+    {
+        vec2 cells = screenSize / pixelSize;
+        coord = (floor(coord * cells) + 0.5f) / cells;
+    }
+    vec3 color = texture(tex, coord).rgb;
+
+    // slowly rolling dark lines, 2 screen pixels apart
+    float scanline = 0.5f + 0.5f * sin((TexCoord.y * screenSize.y + time * 8.0f) * 3.14159265f);
+    color *= 1.0f - scanlineStrength * scanline;
+    FragColor = vec4(color, 1.0f);
+}
@@ -0,0 +1,16 @@
+#version 410 core
+
+in vec2 TexCoord;
+
+uniform sampler2D tex;
+uniform vec4 fadeColor;
+// 0 is no fade, 1 is only fadeColor
+uniform float fadeAmount;
+
+out vec4 FragColor;
+
+void main()
+{
+    vec4 color = texture(tex, TexCoord);
+    FragColor = vec4(mix(color.rgb, fadeColor.rgb, fadeAmount), 1.0f);
+}
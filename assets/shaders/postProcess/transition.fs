@@ -0,0 +1,41 @@
+#version 410 core
+
+in vec2 TexCoord;
+
+// the live frame (incoming scene)
+uniform sampler2D tex;
+// the outgoing scene
+uniform sampler2D outgoing;
+// 0 is crossfade, 1 is wipe, 2 is slide
+uniform float effect;
+// 0 is only the outgoing scene, 1 is only the live frame
+uniform float progress;
+// the way the live frame comes in, in texture coords
+uniform vec2 direction;
+
+out vec4 FragColor;
+
+bool inside(vec2 coord)
+{
+    return all(greaterThanEqual(coord, vec2(0.0f))) && all(lessThanEqual(coord, vec2(1.0f)));
+}
+
+void main()
+{
+    vec3 color;
+    if (effect < 0.5f) {
+        color = mix(texture(outgoing, TexCoord).rgb, texture(tex, TexCoord).rgb, progress);
+    } else if (effect < 1.5f) {
+        // 0.0 on the edge the wipe starts from, 1.0 on the opposite one
+        float along = dot(TexCoord - 0.5f, direction) + 0.5f;
+        color = along < progress ? texture(tex, TexCoord).rgb : texture(outgoing, TexCoord).rgb;
+    } else {
+        // the live frame follows right behind the outgoing one
+        vec2 incomingCoord = TexCoord - direction * (progress - 1.0f);
+        vec2 outgoingCoord = TexCoord - direction * progress;
+        color = inside(incomingCoord)
+            ? texture(tex, incomingCoord).rgb
+            : texture(outgoing, outgoingCoord).rgb;
+    }
+    FragColor = vec4(color, 1.0f);
+}
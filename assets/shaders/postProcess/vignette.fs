@@ -0,0 +1,19 @@
+#version 410 core
+
+in vec2 TexCoord;
+
+uniform sampler2D tex;
+// 0 (the default) is no vignette
+uniform float vignetteStrength;
+// how far from the center (0 to 1) the darkening starts
+uniform float vignetteRadius;
+
+out vec4 FragColor;
+
+void main()
+{
+    vec3 color = texture(tex, TexCoord).rgb;
+    float dist = length(TexCoord - vec2(0.5f)) * 1.41421356f;
+    float darkening = smoothstep(vignetteRadius, 1.0f, dist) * vignetteStrength;
+    FragColor = vec4(color * (1.0f - darkening), 1.0f);
+}
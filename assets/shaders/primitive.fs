@@ -0,0 +1,10 @@
+#version 410 core
+
+in vec4 Color;
+
+out vec4 FragColor;
+
+void main()
+{
+    FragColor = Color;
+}
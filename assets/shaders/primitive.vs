@@ -0,0 +1,16 @@
+#version 410 core
+
+// pos2D (screen pixels) and depth, color
+layout (location = 0) in vec3 vPos;
+layout (location = 1) in vec4 vColor;
+
+uniform mat4 projection;
+
+out vec4 Color;
+
+void main()
+{
+    Color = vColor;
+    gl_Position = projection * vec4(vPos.xy, 0.0f, 1.0f);
+    gl_Position.z = vPos.z;
+}
@@ -0,0 +1,11 @@
+#version 410 core
+#include "include/batchInstance.glsl"
+
+// Tile layers from the bottom (0) up, set with a Material. All of them are behind world sprites.
+uniform float layerIndex;
+
+void main()
+{
+    gl_Position = instancePosition(0.0f);
+    gl_Position.z = 0.9999f - layerIndex * 0.00001f;
+}
@@ -0,0 +1,139 @@
+package assets
+
+// Background asset loading for loading screens.
+// Decoding (PNG, mp3) happens on worker goroutines. Uploading textures to the graphics card has
+// to happen on the main thread, so that is done a little bit every frame by calling Step.
+
+import (
+	"image"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/PatrickKoch07/game-proj/internal/audio"
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+	"github.com/PatrickKoch07/game-proj/internal/sprites"
+)
+
+// What a scene needs loaded before it is created. Paths are the same ones given to
+// sprites.SpriteInitParams.TextureRelPath and audio.CreatePlayer.
+type Manifest struct {
+	Textures []string
+	Audio    []string
+}
+
+type decodedTexture struct {
+	relativePath string
+	img          *image.RGBA
+}
+
+type LoadJob struct {
+	totalSteps int32
+	doneSteps  atomic.Int32
+	// decoded textures waiting for the main thread to upload them
+	decoded chan decodedTexture
+	// number of textures that still need uploading (main thread only)
+	texturesLeft int
+	workers      sync.WaitGroup
+	errCount     atomic.Int32
+}
+
+// Starts decoding everything in the manifest on worker goroutines. Can be called from any thread.
+func Load(manifest Manifest) *LoadJob {
+	logger.LOG.Info().Msgf(
+		"Loading %v textures and %v audio files in the background",
+		len(manifest.Textures),
+		len(manifest.Audio),
+	)
+	job := new(LoadJob)
+	// a texture counts twice: once decoded and once uploaded
+	job.totalSteps = int32(2*len(manifest.Textures) + len(manifest.Audio))
+	job.texturesLeft = len(manifest.Textures)
+	job.decoded = make(chan decodedTexture, len(manifest.Textures))
+
+	work := make(chan func(), len(manifest.Textures)+len(manifest.Audio))
+	for _, relativePath := range manifest.Textures {
+		work <- func() { job.decodeTexture(relativePath) }
+	}
+	for _, filePath := range manifest.Audio {
+		work <- func() { job.decodeAudio(filePath) }
+	}
+	close(work)
+
+	numWorkers := min(runtime.NumCPU(), cap(work))
+	for range numWorkers {
+		job.workers.Add(1)
+		go func() {
+			defer job.workers.Done()
+			for doWork := range work {
+				doWork()
+			}
+		}()
+	}
+
+	return job
+}
+
+func (job *LoadJob) decodeTexture(relativePath string) {
+	img, err := sprites.DecodeTexture(relativePath)
+	if err != nil {
+		logger.LOG.Error().Err(err).Msgf("Failed to decode texture %v", relativePath)
+		job.errCount.Add(1)
+		// nothing to upload, skip that step too
+		job.doneSteps.Add(1)
+	}
+	job.doneSteps.Add(1)
+	job.decoded <- decodedTexture{relativePath: relativePath, img: img}
+}
+
+func (job *LoadJob) decodeAudio(filePath string) {
+	err := audio.PreloadAudio(filePath)
+	if err != nil {
+		logger.LOG.Error().Err(err).Msgf("Failed to decode audio %v", filePath)
+		job.errCount.Add(1)
+	}
+	job.doneSteps.Add(1)
+}
+
+// Uploads decoded textures until the budget runs out (at least one per call if any are ready).
+// Returns if everything in the job is done.
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func (job *LoadJob) Step(budget time.Duration) bool {
+	start := time.Now()
+	for job.texturesLeft > 0 {
+		select {
+		case tex := <-job.decoded:
+			job.texturesLeft--
+			if tex.img != nil {
+				sprites.UploadTexture(tex.relativePath, tex.img)
+				job.doneSteps.Add(1)
+			}
+		default:
+			// nothing decoded yet
+			return job.Done()
+		}
+		if time.Since(start) > budget {
+			break
+		}
+	}
+	return job.Done()
+}
+
+// From 0.0 to 1.0. Safe to read from any thread.
+func (job *LoadJob) Progress() float32 {
+	if job.totalSteps == 0 {
+		return 1.0
+	}
+	return float32(job.doneSteps.Load()) / float32(job.totalSteps)
+}
+
+func (job *LoadJob) Done() bool {
+	return job.doneSteps.Load() >= job.totalSteps
+}
+
+// Number of assets that failed to load. They will be loaded (and fail again) the old way when the
+// scene asks for them.
+func (job *LoadJob) ErrCount() int {
+	return int(job.errCount.Load())
+}
@@ -0,0 +1,130 @@
+package assets
+
+// Development only file watcher. Polls the assets folder for changed files and reloads the
+// matching shaders, textures and sounds into the objects that already use them.
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PatrickKoch07/game-proj/internal/audio"
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+	"github.com/PatrickKoch07/game-proj/internal/sprites"
+)
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+type Watcher struct {
+	root     string
+	interval time.Duration
+	// last seen stamp of every file (polling goroutine only)
+	stamps map[string]fileStamp
+	// changed files waiting one more poll to make sure the editor finished writing them
+	settling map[string]fileStamp
+
+	// changed files waiting for the main thread
+	changed map[string]struct{}
+	mu      sync.Mutex
+	stop    chan struct{}
+}
+
+// Starts polling every file under root (ex. "assets"). Changes are only applied when
+// ApplyChanges is called.
+func WatchForChanges(root string, interval time.Duration) *Watcher {
+	logger.LOG.Info().Msgf("Watching %v for changes", root)
+	w := new(Watcher)
+	w.root = filepath.ToSlash(filepath.Clean(root))
+	w.interval = interval
+	w.settling = make(map[string]fileStamp)
+	w.changed = make(map[string]struct{})
+	w.stop = make(chan struct{})
+	w.stamps = w.scan()
+
+	go w.poll()
+	return w
+}
+
+func (w *Watcher) Stop() {
+	close(w.stop)
+}
+
+func (w *Watcher) poll() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		}
+
+		newStamps := w.scan()
+		for path, stamp := range newStamps {
+			settlingStamp, isSettling := w.settling[path]
+			if isSettling && settlingStamp == stamp {
+				delete(w.settling, path)
+				w.mu.Lock()
+				w.changed[path] = struct{}{}
+				w.mu.Unlock()
+			} else if oldStamp, ok := w.stamps[path]; !ok || oldStamp != stamp {
+				w.settling[path] = stamp
+			}
+		}
+		w.stamps = newStamps
+	}
+}
+
+func (w *Watcher) scan() map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	err := filepath.WalkDir(w.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// a file can disappear between listing and reading (editors swap files on save)
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		stamps[filepath.ToSlash(path)] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	if err != nil {
+		logger.LOG.Warn().Err(err).Msgf("Failed scanning %v for changes", w.root)
+	}
+	return stamps
+}
+
+// Reloads everything that changed since the last call. Errors (ex. a shader that doesn't compile)
+// are logged and the old version is kept.
+// should only be called from the main thread while no game objects are updating
+func (w *Watcher) ApplyChanges() {
+	w.mu.Lock()
+	changed := w.changed
+	w.changed = make(map[string]struct{})
+	w.mu.Unlock()
+
+	for path := range changed {
+		logger.LOG.Debug().Msgf("Asset changed: %v", path)
+		var err error
+		switch {
+		case strings.HasPrefix(path, w.root+"/shaders/"):
+			err = sprites.ReloadShaderFile(strings.TrimPrefix(path, w.root+"/shaders/"))
+		case strings.HasPrefix(path, w.root+"/sprites/"):
+			err = sprites.ReloadTexture(strings.TrimPrefix(path, w.root+"/sprites/"))
+		case strings.HasPrefix(path, w.root+"/audio/"):
+			err = audio.ReloadAudio(path)
+		}
+		if err != nil {
+			logger.LOG.Error().Err(err).Msgf("Failed to reload %v", path)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package audio
 
 import (
 	"bytes"
+	"io"
 	"os"
+	"sync"
 	"sync/atomic"
 
 	"github.com/ebitengine/oto/v3"
@@ -23,18 +25,80 @@ type Player interface {
 	IsNil() bool
 }
 
+// The oto player is behind a lock so hot reloading can swap it out while it's in use (see
+// audioReload.go). All methods are thread safe by locking.
 type StaticPlayer struct {
-	*oto.Player
+	player      *oto.Player
+	mp3FilePath string
+	mu          sync.Mutex
 
 	hasBeenClosed atomic.Bool
 }
 
+func (p *StaticPlayer) BufferedSize() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.player.BufferedSize()
+}
+
+func (p *StaticPlayer) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.player.Err()
+}
+
+func (p *StaticPlayer) IsPlaying() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.player.IsPlaying()
+}
+
+func (p *StaticPlayer) Pause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.player.Pause()
+}
+
+func (p *StaticPlayer) Play() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.player.Play()
+}
+
+func (p *StaticPlayer) Seek(offset int64, whence int) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.player.Seek(offset, whence)
+}
+
+func (p *StaticPlayer) SetBufferSize(bufferSize int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.player.SetBufferSize(bufferSize)
+}
+
+func (p *StaticPlayer) SetVolume(volume float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.player.SetVolume(volume)
+}
+
+func (p *StaticPlayer) Volume() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.player.Volume()
+}
+
 func (p *StaticPlayer) Clear() error {
-	err := p.Player.Close()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	err := p.player.Close()
 	if err != nil {
 		return err
 	}
-	p.hasBeenClosed.Store(true)
+	if !p.hasBeenClosed.Swap(true) {
+		releaseAudio(p.mp3FilePath)
+	}
 	return nil
 }
 
@@ -42,19 +106,108 @@ func (p *StaticPlayer) IsNil() bool {
 	return p.hasBeenClosed.Load()
 }
 
-func CreatePlayer(mp3FilePath string) (Player, error) {
+// mp3 files already decoded to raw PCM by PreloadAudio, keyed by file path.
+// Players made from these skip decoding while playing.
+var decodedAudio map[string][]byte = make(map[string][]byte)
+
+// How many players not yet cleared were made from each file. Also guarded by decodedAudioMu.
+var audioUsers map[string]int = make(map[string]int)
+var decodedAudioMu sync.RWMutex
+
+// Fully decodes the mp3 into memory so CreatePlayer can use it later. Safe to call from any
+// goroutine, meant for loading screens.
+func PreloadAudio(mp3FilePath string) error {
+	decodedAudioMu.RLock()
+	_, ok := decodedAudio[mp3FilePath]
+	decodedAudioMu.RUnlock()
+	if ok {
+		return nil
+	}
+
 	fileBytes, err := os.ReadFile(mp3FilePath)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	decodedMp3, err := mp3.NewDecoder(bytes.NewReader(fileBytes))
+	if err != nil {
+		return err
 	}
-	fileBytesReader := bytes.NewReader(fileBytes)
-	decodedMp3, err := mp3.NewDecoder(fileBytesReader)
+	pcm, err := io.ReadAll(decodedMp3)
+	if err != nil {
+		return err
+	}
+
+	decodedAudioMu.Lock()
+	decodedAudio[mp3FilePath] = pcm
+	decodedAudioMu.Unlock()
+	return nil
+}
+
+// Drops the preloaded data. Players already made keep their own reader.
+func UnloadAudio(mp3FilePath string) {
+	decodedAudioMu.Lock()
+	delete(decodedAudio, mp3FilePath)
+	decodedAudioMu.Unlock()
+}
+
+// Drops the preloaded data of every file no player is using anymore (ex. the last scene's sounds,
+// or ones a loading screen preloaded that the scene never played). Call once the next scene has
+// made its players.
+// thread safe by locking
+func UnloadUnusedAudio() {
+	decodedAudioMu.Lock()
+	defer decodedAudioMu.Unlock()
+	for mp3FilePath := range decodedAudio {
+		if audioUsers[mp3FilePath] == 0 {
+			delete(decodedAudio, mp3FilePath)
+		}
+	}
+}
+
+func useAudio(mp3FilePath string) {
+	decodedAudioMu.Lock()
+	audioUsers[mp3FilePath]++
+	decodedAudioMu.Unlock()
+}
+
+func releaseAudio(mp3FilePath string) {
+	decodedAudioMu.Lock()
+	audioUsers[mp3FilePath]--
+	if audioUsers[mp3FilePath] <= 0 {
+		delete(audioUsers, mp3FilePath)
+	}
+	decodedAudioMu.Unlock()
+}
+
+func CreatePlayer(mp3FilePath string) (Player, error) {
+	audioReader, err := newAudioReader(mp3FilePath)
 	if err != nil {
 		return nil, err
 	}
-	player := GetAudioContext().NewPlayer(decodedMp3)
-	playerWrapper := StaticPlayer{Player: player}
+	player := GetAudioContext().NewPlayer(audioReader)
+	playerWrapper := StaticPlayer{player: player, mp3FilePath: mp3FilePath}
 	playerWrapper.hasBeenClosed.Store(false)
+	useAudio(mp3FilePath)
+	trackPlayer(mp3FilePath, &playerWrapper)
 	publicPlayer := Player(&playerWrapper)
 	return publicPlayer, nil
 }
+
+func newAudioReader(mp3FilePath string) (io.Reader, error) {
+	decodedAudioMu.RLock()
+	pcm, ok := decodedAudio[mp3FilePath]
+	decodedAudioMu.RUnlock()
+	if ok {
+		return bytes.NewReader(pcm), nil
+	}
+
+	fileBytes, err := os.ReadFile(mp3FilePath)
+	if err != nil {
+		return nil, err
+	}
+	decodedMp3, err := mp3.NewDecoder(bytes.NewReader(fileBytes))
+	if err != nil {
+		return nil, err
+	}
+	return decodedMp3, nil
+}
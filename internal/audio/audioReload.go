@@ -0,0 +1,87 @@
+package audio
+
+// Hot reloading of static players. Players are tracked weakly by file path so the reload can
+// swap in the new sound without anyone holding a player having to know.
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"weak"
+
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+
+	"github.com/ebitengine/oto/v3"
+)
+
+var trackedPlayers map[string][]weak.Pointer[StaticPlayer] = make(map[string][]weak.Pointer[StaticPlayer])
+var trackedPlayersMu sync.Mutex
+
+func trackPlayer(mp3FilePath string, p *StaticPlayer) {
+	trackedPlayersMu.Lock()
+	defer trackedPlayersMu.Unlock()
+
+	trackedPlayers[mp3FilePath] = slices.DeleteFunc(
+		trackedPlayers[mp3FilePath],
+		func(w weak.Pointer[StaticPlayer]) bool { return w.Value() == nil || w.Value().IsNil() },
+	)
+	trackedPlayers[mp3FilePath] = append(trackedPlayers[mp3FilePath], weak.Make(p))
+}
+
+// Reloads the mp3 for every live player made from it (and the preloaded data, if any).
+// Streaming players are not reloaded, they will pick up the change the next time they are made.
+// Each player is swapped under its own lock, so owners can keep using it meanwhile.
+func ReloadAudio(mp3FilePath string) error {
+	decodedAudioMu.RLock()
+	_, wasPreloaded := decodedAudio[mp3FilePath]
+	decodedAudioMu.RUnlock()
+	if wasPreloaded {
+		UnloadAudio(mp3FilePath)
+		err := PreloadAudio(mp3FilePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	trackedPlayersMu.Lock()
+	defer trackedPlayersMu.Unlock()
+
+	var errs []error
+	reloadCount := 0
+	for _, w := range trackedPlayers[mp3FilePath] {
+		p := w.Value()
+		if p == nil || p.IsNil() {
+			continue
+		}
+		audioReader, err := newAudioReader(mp3FilePath)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		newPlayer := GetAudioContext().NewPlayer(audioReader)
+		if p.swap(newPlayer) {
+			reloadCount++
+		}
+	}
+	logger.LOG.Info().Msgf("Reloaded %v players for %v", reloadCount, mp3FilePath)
+	return errors.Join(errs...)
+}
+
+// Keeps the volume of the player being replaced. Returns false (closing newPlayer instead) if p
+// was cleared since it was looked up.
+// thread safe by locking
+func (p *StaticPlayer) swap(newPlayer *oto.Player) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.IsNil() {
+		newPlayer.Close()
+		return false
+	}
+	newPlayer.SetVolume(p.player.Volume())
+	err := p.player.Close()
+	if err != nil {
+		logger.LOG.Warn().Err(err).Msg("Trying to continue anyway")
+	}
+	p.player = newPlayer
+	return true
+}
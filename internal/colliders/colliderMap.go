@@ -3,9 +3,6 @@ package colliders
 import (
 	"slices"
 	"sync"
-
-	"github.com/PatrickKoch07/game-proj/internal/gameState"
-	"github.com/PatrickKoch07/game-proj/internal/logger"
 )
 
 type colliderMapCoords struct {
@@ -19,19 +16,40 @@ type ColliderMap2D struct {
 	ChunkY int
 }
 
-// Objects must read in the whole state of all collidermaps and make changes to the whole state
-type colliderMapLayers struct {
-	FlagToMap map[gameState.Flag]*ColliderMap2D
-	Mu        sync.Mutex
+// One spatial hash for every collider, whatever its layers. Moves must read in the whole state
+// of the map (and the collision matrix) and make changes to the whole state
+type colliderWorld struct {
+	Map    *ColliderMap2D
+	matrix collisionMatrix
+	// recent blocked moves, for debug drawing
+	contacts []contact
+	Mu       sync.Mutex
 }
 
-var colliderFlagToMap *colliderMapLayers
-var onceFlagToMap sync.Once
+var activeColliderWorld *colliderWorld
+var onceColliderWorld sync.Once
 
 func (cm *ColliderMap2D) worldCoordsToColliderCoords(xy WorldCoords) colliderMapCoords {
 	return colliderMapCoords{X: int(xy.X) / cm.ChunkX, Y: int(xy.Y) / cm.ChunkY}
 }
 
+// World area a cell covers. Coords are truncated towards zero, so the cells on either side of 0
+// reach into the negatives too (ex. cell 0 covers -ChunkX to ChunkX).
+func (cm *ColliderMap2D) cellBounds(coords colliderMapCoords) (WorldCoords, WorldCoords) {
+	cellRange := func(i int, size int) (float32, float32) {
+		switch {
+		case i > 0:
+			return float32(i * size), float32((i + 1) * size)
+		case i < 0:
+			return float32((i - 1) * size), float32(i * size)
+		}
+		return float32(-size), float32(size)
+	}
+	minX, maxX := cellRange(coords.X, cm.ChunkX)
+	minY, maxY := cellRange(coords.Y, cm.ChunkY)
+	return WorldCoords{X: minX, Y: minY}, WorldCoords{X: maxX, Y: maxY}
+}
+
 func (cm *ColliderMap2D) getColliderCoords(collider *Collider2D) []colliderMapCoords {
 	topWorldY := collider.CenterCoords.Y + collider.Height/2.0
 	bottomWorldY := collider.CenterCoords.Y - collider.Height/2.0
@@ -90,73 +108,47 @@ func (cm *ColliderMap2D) getPrevColliderCoords(
 	return mapCoordsColliderTouches
 }
 
-// not safe
-func updateColliderInMap(collider *Collider2D, prevWorldCoord WorldCoords) {
-	for _, flag := range collider.Tags {
-		colliderMap, ok := getColliderMap(flag)
-		if !ok {
-			continue
-		}
-
-		// remove
-		colliderMapCoords := colliderMap.getPrevColliderCoords(collider, prevWorldCoord)
-		for _, colliderMapCoord := range colliderMapCoords {
-			colliderMap.Map[colliderMapCoord] = slices.DeleteFunc(
-				colliderMap.Map[colliderMapCoord],
-				func(mappedCollider *Collider2D) bool { return equals(collider, mappedCollider) },
-			)
-		}
+// Cells covered by any box, ex. the area swept by a move
+func (cm *ColliderMap2D) getBoxCoords(center WorldCoords, width, height float32) []colliderMapCoords {
+	bottomLeft := cm.worldCoordsToColliderCoords(
+		WorldCoords{X: center.X - width/2.0, Y: center.Y - height/2.0},
+	)
+	topRight := cm.worldCoordsToColliderCoords(
+		WorldCoords{X: center.X + width/2.0, Y: center.Y + height/2.0},
+	)
 
-		// add
-		colliderMapCoords = colliderMap.getColliderCoords(collider)
-		for _, colliderMapCoord := range colliderMapCoords {
-			colliderMap.Map[colliderMapCoord] = append(colliderMap.Map[colliderMapCoord], collider)
+	mapCoords := make([]colliderMapCoords, 0, (topRight.X-bottomLeft.X+1)*(topRight.Y-bottomLeft.Y+1))
+	for x := bottomLeft.X; x <= topRight.X; x++ {
+		for y := bottomLeft.Y; y <= topRight.Y; y++ {
+			mapCoords = append(mapCoords, colliderMapCoords{X: x, Y: y})
 		}
 	}
-	colliderMap, ok := getColliderMap(gameState.AllColliders)
-	if !ok {
-		logger.LOG.Error().Msg("Something wrong collider maps: no base allcollider map")
+	return mapCoords
+}
+
+// not safe
+func updateColliderInMap(collider *Collider2D, prevWorldCoord WorldCoords) {
+	if collider.removed {
 		return
 	}
-	colliderMapCoords := colliderMap.getColliderCoords(collider)
-
+	colliderMap := getColliderWorld().Map
 	// remove
-	colliderMapCoords = colliderMap.getPrevColliderCoords(collider, prevWorldCoord)
-	for _, colliderMapCoord := range colliderMapCoords {
+	for _, colliderMapCoord := range colliderMap.getPrevColliderCoords(collider, prevWorldCoord) {
 		colliderMap.Map[colliderMapCoord] = slices.DeleteFunc(
 			colliderMap.Map[colliderMapCoord],
 			func(mappedCollider *Collider2D) bool { return equals(collider, mappedCollider) },
 		)
 	}
-
 	// add
-	for _, colliderMapCoord := range colliderMapCoords {
+	for _, colliderMapCoord := range colliderMap.getColliderCoords(collider) {
 		colliderMap.Map[colliderMapCoord] = append(colliderMap.Map[colliderMapCoord], collider)
 	}
 }
 
 // not safe
 func removeColliderFromMaps(collider *Collider2D) {
-	for _, flag := range collider.Tags {
-		colliderMap, ok := getColliderMap(flag)
-		if !ok {
-			continue
-		}
-		colliderMapCoords := colliderMap.getColliderCoords(collider)
-		for _, colliderMapCoord := range colliderMapCoords {
-			colliderMap.Map[colliderMapCoord] = slices.DeleteFunc(
-				colliderMap.Map[colliderMapCoord],
-				func(mappedCollider *Collider2D) bool { return equals(collider, mappedCollider) },
-			)
-		}
-	}
-	colliderMap, ok := getColliderMap(gameState.AllColliders)
-	if !ok {
-		logger.LOG.Error().Msg("Something wrong collider maps: no base allcollider map")
-		return
-	}
-	colliderMapCoords := colliderMap.getColliderCoords(collider)
-	for _, colliderMapCoord := range colliderMapCoords {
+	colliderMap := getColliderWorld().Map
+	for _, colliderMapCoord := range colliderMap.getColliderCoords(collider) {
 		colliderMap.Map[colliderMapCoord] = slices.DeleteFunc(
 			colliderMap.Map[colliderMapCoord],
 			func(mappedCollider *Collider2D) bool { return equals(collider, mappedCollider) },
@@ -164,61 +156,48 @@ func removeColliderFromMaps(collider *Collider2D) {
 	}
 }
 
-func getColliderMapLayers() *colliderMapLayers {
-	if colliderFlagToMap == nil {
-		onceFlagToMap.Do(createColliderFlagToMap)
-	}
-	return colliderFlagToMap
-}
-
-// not safe
-func getColliderMap(flag gameState.Flag) (*ColliderMap2D, bool) {
-	colliderMap, ok := getColliderMapLayers().FlagToMap[flag]
-	return colliderMap, ok
+func getColliderWorld() *colliderWorld {
+	onceColliderWorld.Do(createColliderWorld)
+	return activeColliderWorld
 }
 
-func createColliderFlagToMap() {
-	colliderFlagToMap = new(colliderMapLayers)
-	colliderFlagToMap.FlagToMap = make(map[gameState.Flag]*ColliderMap2D)
-	colliderMap := &ColliderMap2D{
-		Map:    make(map[colliderMapCoords][]*Collider2D),
-		ChunkX: 64,
-		ChunkY: 64,
-	}
-	colliderFlagToMap.FlagToMap[gameState.AllColliders] = colliderMap
-
-	// TODO technically this stuff should be the game side of things, rather than game engine since
-	// this can and should be changed for each game. I guess I could be lazy and use fixed layers
-	// like unreal
-
-	colliderMap = &ColliderMap2D{
+func createColliderWorld() {
+	activeColliderWorld = new(colliderWorld)
+	activeColliderWorld.Map = &ColliderMap2D{
 		Map:    make(map[colliderMapCoords][]*Collider2D),
 		ChunkX: 64,
 		ChunkY: 64,
 	}
-	colliderFlagToMap.FlagToMap[gameState.EnvironmentCollider] = colliderMap
-	// etc.
 }
 
-// not safe
+// thread safe by locking
 func AddColliderToMaps(collider *Collider2D) {
-	for _, flag := range collider.Tags {
-		colliderMap, ok := getColliderMap(flag)
-		if !ok {
-			continue
-		}
-		allColliderMapCoords := colliderMap.getColliderCoords(collider)
-		for _, colliderMapCoord := range allColliderMapCoords {
-			colliderMap.Map[colliderMapCoord] = append(colliderMap.Map[colliderMapCoord], collider)
-		}
+	getColliderWorld().Mu.Lock()
+	defer getColliderWorld().Mu.Unlock()
+	if collider.id == 0 {
+		collider.id = lastColliderId.Add(1)
 	}
-	colliderMap, ok := getColliderMap(gameState.AllColliders)
-	if !ok {
-		logger.LOG.Error().Msg("Something wrong collider maps: no base allcollider map")
-		return
+	collider.removed = false
+	if collider.Layers == 0 {
+		// otherwise it would collide with nothing
+		collider.Layers = DefaultLayer
+	}
+	if collider.Shape != nil {
+		// the maps only know about bounding boxes
+		collider.Width, collider.Height = collider.Shape.BoundingBox()
 	}
-	allColliderMapCoords := colliderMap.getColliderCoords(collider)
-	for _, colliderMapCoord := range allColliderMapCoords {
+	colliderMap := getColliderWorld().Map
+	for _, colliderMapCoord := range colliderMap.getColliderCoords(collider) {
 		colliderMap.Map[colliderMapCoord] = append(colliderMap.Map[colliderMapCoord], collider)
 	}
 }
+
+// For colliders that go away without their game object moving them (ex. a tilemap being killed)
+// thread safe by locking
+func RemoveColliderFromMaps(collider *Collider2D) {
+	getMoveQueue().cancel(collider)
+	getColliderWorld().Mu.Lock()
+	removeColliderFromMaps(collider)
+	collider.removed = true
+	getColliderWorld().Mu.Unlock()
+}
@@ -0,0 +1,103 @@
+package colliders
+
+// A copy of what the collider map holds, for drawing it (see the debugOverlay package). Copies so
+// nothing outside the package reads colliders while they are being moved.
+
+import (
+	"time"
+)
+
+// how long contact points stay in snapshots after a blocked move
+const contactShowTime time.Duration = time.Second
+
+// contact points kept at most, oldest dropped first
+const maxRecentContacts int = 64
+
+type DebugCollider struct {
+	Layers  Layer
+	Outline []WorldCoords
+}
+
+type DebugCell struct {
+	Min WorldCoords
+	Max WorldCoords
+	// colliders in the cell
+	Count int
+}
+
+type DebugSnapshot struct {
+	Colliders []DebugCollider
+	// only cells with colliders in them
+	Cells []DebugCell
+	// where recent blocked moves touched what blocked them
+	Contacts []WorldCoords
+}
+
+type contact struct {
+	point WorldCoords
+	at    time.Time
+}
+
+// thread safe by locking
+func GetDebugSnapshot() DebugSnapshot {
+	world := getColliderWorld()
+	world.Mu.Lock()
+	defer world.Mu.Unlock()
+
+	var snapshot DebugSnapshot
+	seen := make(map[*Collider2D]bool)
+	for mapCoords, cellColliders := range world.Map.Map {
+		if len(cellColliders) == 0 {
+			continue
+		}
+		cellMin, cellMax := world.Map.cellBounds(mapCoords)
+		snapshot.Cells = append(snapshot.Cells, DebugCell{
+			Min: cellMin, Max: cellMax, Count: len(cellColliders),
+		})
+		for _, collider := range cellColliders {
+			if seen[collider] {
+				continue
+			}
+			seen[collider] = true
+			snapshot.Colliders = append(snapshot.Colliders, DebugCollider{
+				Layers: collider.Layers, Outline: collider.outlineAt(collider.CenterCoords),
+			})
+		}
+	}
+	for _, recent := range world.contacts {
+		if time.Since(recent.at) <= contactShowTime {
+			snapshot.Contacts = append(snapshot.Contacts, recent.point)
+		}
+	}
+	return snapshot
+}
+
+// Where c, centered at center, touches something whose surface faces normal: the middle of c's
+// outline furthest against the normal.
+func contactPoint(c *Collider2D, center WorldCoords, normal WorldCoords) WorldCoords {
+	const tolerance float32 = 0.01
+	var furthest float32
+	var sum WorldCoords
+	count := 0
+	for _, point := range c.outlineAt(center) {
+		reach := -((point.X-center.X)*normal.X + (point.Y-center.Y)*normal.Y)
+		if count == 0 || reach > furthest+tolerance {
+			furthest = reach
+			sum = point
+			count = 1
+		} else if reach >= furthest-tolerance {
+			sum.X += point.X
+			sum.Y += point.Y
+			count++
+		}
+	}
+	return WorldCoords{X: sum.X / float32(count), Y: sum.Y / float32(count)}
+}
+
+// not safe
+func (w *colliderWorld) addContact(point WorldCoords) {
+	if len(w.contacts) == maxRecentContacts {
+		w.contacts = w.contacts[1:]
+	}
+	w.contacts = append(w.contacts, contact{point: point, at: time.Now()})
+}
@@ -0,0 +1,94 @@
+package colliders
+
+// Collision layers. Each collider is on one or more layers (bits of a mask), and a global matrix
+// says what happens between two layers: they block each other, overlap (only notify), or ignore
+// each other. Pairs not in the matrix overlap.
+//
+// The engine only knows the layers it uses itself. Games add their own starting at
+// FirstGameLayer and set the whole matrix at startup, ex.
+//
+//	const PlayerLayer = colliders.FirstGameLayer
+//	colliders.SetCollisionRules([]colliders.CollisionRule{
+//		{A: PlayerLayer, B: colliders.EnvironmentLayer, Response: colliders.Block},
+//	})
+
+import (
+	"math/bits"
+)
+
+type Layer uint32
+
+const (
+	// what colliders added with no layers are put on
+	DefaultLayer Layer = 1 << iota
+	// static level geometry, ex. tilemap colliders
+	EnvironmentLayer
+	FirstGameLayer
+)
+
+type Response int8
+
+const (
+	Overlap Response = iota
+	Block
+	Ignore
+)
+
+// Applies both ways: A blocks B is the same as B blocks A. A and B can have several layers each.
+type CollisionRule struct {
+	A        Layer
+	B        Layer
+	Response Response
+}
+
+// per layer bit, the layers it blocks and the layers it ignores
+type collisionMatrix struct {
+	blocks  [32]Layer
+	ignores [32]Layer
+}
+
+// Replaces the whole matrix. Later rules win over earlier ones for the same pair.
+// thread safe by locking
+func SetCollisionRules(rules []CollisionRule) {
+	getColliderWorld().Mu.Lock()
+	defer getColliderWorld().Mu.Unlock()
+
+	matrix := &getColliderWorld().matrix
+	*matrix = collisionMatrix{}
+	for _, rule := range rules {
+		matrix.set(rule.A, rule.B, rule.Response)
+		matrix.set(rule.B, rule.A, rule.Response)
+	}
+}
+
+func (m *collisionMatrix) set(from Layer, to Layer, response Response) {
+	for layers := from; layers != 0; layers &= layers - 1 {
+		i := bits.TrailingZeros32(uint32(layers))
+		m.blocks[i] &^= to
+		m.ignores[i] &^= to
+		switch response {
+		case Block:
+			m.blocks[i] |= to
+		case Ignore:
+			m.ignores[i] |= to
+		}
+	}
+}
+
+// Every layer blocked by any of the given layers
+func (m *collisionMatrix) blockMask(layers Layer) Layer {
+	var mask Layer
+	for ; layers != 0; layers &= layers - 1 {
+		mask |= m.blocks[bits.TrailingZeros32(uint32(layers))]
+	}
+	return mask
+}
+
+// Every layer not ignored by at least one of the given layers (blocking layers included)
+func (m *collisionMatrix) overlapMask(layers Layer) Layer {
+	var mask Layer
+	for ; layers != 0; layers &= layers - 1 {
+		mask |= ^m.ignores[bits.TrailingZeros32(uint32(layers))]
+	}
+	return mask
+}
@@ -0,0 +1,121 @@
+package colliders
+
+import (
+	"testing"
+)
+
+func TestCollisionMatrix(t *testing.T) {
+	a := FirstGameLayer
+	b := FirstGameLayer << 1
+	c := FirstGameLayer << 2
+	all := ^Layer(0)
+
+	tests := []struct {
+		name        string
+		rules       []CollisionRule
+		layers      Layer
+		wantBlock   Layer
+		wantOverlap Layer
+	}{
+		{
+			name:        "no rules overlap everything",
+			layers:      a,
+			wantBlock:   0,
+			wantOverlap: all,
+		},
+		{
+			name:        "block",
+			rules:       []CollisionRule{{A: a, B: b, Response: Block}},
+			layers:      a,
+			wantBlock:   b,
+			wantOverlap: all,
+		},
+		{
+			name:        "block both ways",
+			rules:       []CollisionRule{{A: a, B: b, Response: Block}},
+			layers:      b,
+			wantBlock:   a,
+			wantOverlap: all,
+		},
+		{
+			name:        "ignore",
+			rules:       []CollisionRule{{A: a, B: b, Response: Ignore}},
+			layers:      b,
+			wantBlock:   0,
+			wantOverlap: all &^ a,
+		},
+		{
+			name:        "self",
+			rules:       []CollisionRule{{A: a, B: a, Response: Block}},
+			layers:      a,
+			wantBlock:   a,
+			wantOverlap: all,
+		},
+		{
+			name: "later rule wins",
+			rules: []CollisionRule{
+				{A: a, B: b, Response: Block},
+				{A: b, B: a, Response: Ignore},
+			},
+			layers:      a,
+			wantBlock:   0,
+			wantOverlap: all &^ b,
+		},
+		{
+			name: "later overlap clears block",
+			rules: []CollisionRule{
+				{A: a, B: b | c, Response: Block},
+				{A: a, B: c, Response: Overlap},
+			},
+			layers:      a,
+			wantBlock:   b,
+			wantOverlap: all,
+		},
+		{
+			name:        "several layers in a rule",
+			rules:       []CollisionRule{{A: a | b, B: c, Response: Block}},
+			layers:      c,
+			wantBlock:   a | b,
+			wantOverlap: all,
+		},
+		{
+			name: "several layers on a collider",
+			rules: []CollisionRule{
+				{A: a, B: c, Response: Block},
+				{A: b, B: c, Response: Ignore},
+				{A: b, B: a, Response: Ignore},
+			},
+			layers:    a | b,
+			wantBlock: c,
+			// c is still seen by a, a is still seen by itself
+			wantOverlap: all,
+		},
+		{
+			name: "ignored by every layer",
+			rules: []CollisionRule{
+				{A: a, B: c, Response: Ignore},
+				{A: b, B: c, Response: Ignore},
+			},
+			layers:      a | b,
+			wantBlock:   0,
+			wantOverlap: all &^ c,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// the same as SetCollisionRules, without the global matrix
+			var matrix collisionMatrix
+			for _, rule := range test.rules {
+				matrix.set(rule.A, rule.B, rule.Response)
+				matrix.set(rule.B, rule.A, rule.Response)
+			}
+			if got := matrix.blockMask(test.layers); got != test.wantBlock {
+				t.Errorf("blockMask(%b) = %b, want %b", test.layers, got, test.wantBlock)
+			}
+			if got := matrix.overlapMask(test.layers); got != test.wantOverlap {
+				t.Errorf("overlapMask(%b) = %b, want %b", test.layers, got, test.wantOverlap)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package colliders
+
+// Package level state held by private singleton initialized at program start.
+// Two phase moves. Game objects update in parallel, so moving colliders right away makes every one
+// of them wait on the collider map lock, and whoever gets it first changes from frame to frame.
+// Instead, moves are requested during the update (cheap, only the queue is locked) and all resolved
+// together afterwards, one at a time in the order the colliders were added, with each move's
+// collision callbacks called before the next move. Same inputs, same results, however the
+// goroutines were scheduled.
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+type moveIntent struct {
+	collider *Collider2D
+	target   WorldCoords
+	onDone   func(WorldCoords, Hit, bool)
+}
+
+type moveQueue struct {
+	// one per collider, the latest request wins
+	intents map[*Collider2D]moveIntent
+	mu      sync.Mutex
+}
+
+var activeMoveQueue *moveQueue
+var onceMoveQueue sync.Once
+
+// ids are handed out as colliders are added, which is the order queued moves resolve in
+var lastColliderId atomic.Uint64
+
+func getMoveQueue() *moveQueue {
+	onceMoveQueue.Do(func() {
+		activeMoveQueue = new(moveQueue)
+		activeMoveQueue.intents = make(map[*Collider2D]moveIntent)
+	})
+	return activeMoveQueue
+}
+
+// Queues a move to target for ResolveMoves, replacing any move c already had queued. onDone (can be
+// nil) is given what SweepCollider returned, on the main thread.
+// thread safe by locking
+func (c *Collider2D) RequestMove(target WorldCoords, onDone func(WorldCoords, Hit, bool)) {
+	q := getMoveQueue()
+	q.mu.Lock()
+	q.intents[c] = moveIntent{collider: c, target: target, onDone: onDone}
+	q.mu.Unlock()
+}
+
+// Resolves every requested move.
+// should only be called from the main thread while no game objects are updating
+func ResolveMoves() {
+	q := getMoveQueue()
+	q.mu.Lock()
+	intents := slices.Collect(maps.Values(q.intents))
+	clear(q.intents)
+	q.mu.Unlock()
+
+	slices.SortFunc(intents, func(a moveIntent, b moveIntent) int {
+		return cmp.Compare(a.collider.id, b.collider.id)
+	})
+	for _, intent := range intents {
+		center, hit, blocked := intent.collider.SweepCollider(intent.target)
+		if intent.onDone != nil {
+			intent.onDone(center, hit, blocked)
+		}
+	}
+}
+
+// thread safe by locking
+func (q *moveQueue) cancel(c *Collider2D) {
+	q.mu.Lock()
+	delete(q.intents, c)
+	q.mu.Unlock()
+}
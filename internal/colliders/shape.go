@@ -0,0 +1,259 @@
+package colliders
+
+// Collider shapes other than the default box. The collider maps (broad phase) still only know
+// about bounding boxes, shapes are only compared once two bounding boxes are close.
+//
+// Every shape is a convex "core" (a point, a segment or a polygon) with a radius around it:
+//   - Box and Polygon: the polygon itself, radius 0
+//   - Circle: a point, radius Radius
+//   - Capsule: a segment, radius Radius
+// Two shapes touch when their cores are at most the sum of their radii apart. Polygon cores are
+// compared with the separating axis theorem (SAT).
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+type Shape interface {
+	// Size of the bounding box, which is what the collider maps store
+	BoundingBox() (width float32, height float32)
+	// convex core (relative to the collider's center) and the radius around it
+	core() ([]WorldCoords, float32)
+}
+
+type Box struct {
+	Width  float32
+	Height float32
+}
+
+type Circle struct {
+	Radius float32
+}
+
+// Two half circles joined by a rectangle. Length is the distance between the circles' centers,
+// so a vertical capsule is 2*Radius wide and Length+2*Radius tall.
+type Capsule struct {
+	Radius     float32
+	Length     float32
+	Horizontal bool
+}
+
+// Points are relative to the collider's center and must make a convex shape (in either order).
+type Polygon struct {
+	Points []WorldCoords
+}
+
+func (b Box) BoundingBox() (float32, float32) {
+	return b.Width, b.Height
+}
+
+func (b Box) core() ([]WorldCoords, float32) {
+	halfW := b.Width / 2.0
+	halfH := b.Height / 2.0
+	return []WorldCoords{
+		{X: -halfW, Y: -halfH},
+		{X: halfW, Y: -halfH},
+		{X: halfW, Y: halfH},
+		{X: -halfW, Y: halfH},
+	}, 0.0
+}
+
+func (c Circle) BoundingBox() (float32, float32) {
+	return 2.0 * c.Radius, 2.0 * c.Radius
+}
+
+func (c Circle) core() ([]WorldCoords, float32) {
+	return []WorldCoords{{X: 0.0, Y: 0.0}}, c.Radius
+}
+
+func (c Capsule) BoundingBox() (float32, float32) {
+	if c.Horizontal {
+		return c.Length + 2.0*c.Radius, 2.0 * c.Radius
+	}
+	return 2.0 * c.Radius, c.Length + 2.0*c.Radius
+}
+
+func (c Capsule) core() ([]WorldCoords, float32) {
+	half := c.Length / 2.0
+	if c.Horizontal {
+		return []WorldCoords{{X: -half, Y: 0.0}, {X: half, Y: 0.0}}, c.Radius
+	}
+	return []WorldCoords{{X: 0.0, Y: -half}, {X: 0.0, Y: half}}, c.Radius
+}
+
+func (p Polygon) BoundingBox() (float32, float32) {
+	// the collider center stays the middle of the bounding box, so it has to reach the furthest
+	// point on each side
+	var halfW, halfH float32
+	for _, point := range p.Points {
+		halfW = max(halfW, abs32(point.X))
+		halfH = max(halfH, abs32(point.Y))
+	}
+	return 2.0 * halfW, 2.0 * halfH
+}
+
+func (p Polygon) core() ([]WorldCoords, float32) {
+	return p.Points, 0.0
+}
+
+// The collider's shape, the box from Width and Height if it has none
+func (c *Collider2D) shape() Shape {
+	if c.Shape == nil {
+		return Box{Width: c.Width, Height: c.Height}
+	}
+	return c.Shape
+}
+
+// How far apart the two colliders' shapes would be with c centered at cCenter. 0 or less is
+// touching. Overlapping shapes give a negative value, but not a real penetration depth.
+func separation(c *Collider2D, cCenter WorldCoords, other *Collider2D) float32 {
+	coreA, radiusA := c.shape().core()
+	coreB, radiusB := other.shape().core()
+	a := translated(coreA, cCenter)
+	b := translated(coreB, other.CenterCoords)
+	return coreDistance(a, b) - radiusA - radiusB
+}
+
+func translated(points []WorldCoords, offset WorldCoords) []WorldCoords {
+	moved := make([]WorldCoords, len(points))
+	for i, point := range points {
+		moved[i] = WorldCoords{X: point.X + offset.X, Y: point.Y + offset.Y}
+	}
+	return moved
+}
+
+// Distance between two convex cores (0 if they intersect)
+func coreDistance(a []WorldCoords, b []WorldCoords) float32 {
+	if len(a) >= 3 || len(b) >= 3 {
+		if satOverlap(a, b) {
+			return 0.0
+		}
+	} else if len(a) == 2 && len(b) == 2 && segmentsCross(a[0], a[1], b[0], b[1]) {
+		return 0.0
+	}
+
+	// apart (or only touching), so the closest points are a vertex of one and an edge of the other
+	distance := float32(math.Inf(1))
+	for _, point := range a {
+		distance = min(distance, pointToCoreDistance(point, b))
+	}
+	for _, point := range b {
+		distance = min(distance, pointToCoreDistance(point, a))
+	}
+	return distance
+}
+
+func pointToCoreDistance(point WorldCoords, core []WorldCoords) float32 {
+	if len(core) == 1 {
+		return pointToSegmentDistance(point, core[0], core[0])
+	}
+	distance := float32(math.Inf(1))
+	for i := range core {
+		distance = min(distance, pointToSegmentDistance(point, core[i], core[(i+1)%len(core)]))
+	}
+	return distance
+}
+
+func pointToSegmentDistance(point WorldCoords, start WorldCoords, end WorldCoords) float32 {
+	segX := end.X - start.X
+	segY := end.Y - start.Y
+	lengthSq := segX*segX + segY*segY
+	var t float32
+	if lengthSq > 0.0 {
+		t = min(max(((point.X-start.X)*segX+(point.Y-start.Y)*segY)/lengthSq, 0.0), 1.0)
+	}
+	dx := point.X - (start.X + t*segX)
+	dy := point.Y - (start.Y + t*segY)
+	return float32(math.Sqrt(float64(dx*dx + dy*dy)))
+}
+
+// True if no edge normal of either core separates them. At least one core must be a polygon
+// (3+ points), separating two points or segments needs more than their normals.
+func satOverlap(a []WorldCoords, b []WorldCoords) bool {
+	for _, core := range [2][]WorldCoords{a, b} {
+		if len(core) < 2 {
+			continue
+		}
+		for i := range core {
+			next := core[(i+1)%len(core)]
+			axis := WorldCoords{X: -(next.Y - core[i].Y), Y: next.X - core[i].X}
+			minA, maxA := project(a, axis)
+			minB, maxB := project(b, axis)
+			if maxA < minB || maxB < minA {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func project(points []WorldCoords, axis WorldCoords) (float32, float32) {
+	low := float32(math.Inf(1))
+	high := float32(math.Inf(-1))
+	for _, point := range points {
+		dot := point.X*axis.X + point.Y*axis.Y
+		low = min(low, dot)
+		high = max(high, dot)
+	}
+	return low, high
+}
+
+// Proper crossing only, touching ends and overlapping collinear segments are caught by the
+// distance check instead.
+func segmentsCross(a1, a2, b1, b2 WorldCoords) bool {
+	d1 := cross(a1, a2, b1)
+	d2 := cross(a1, a2, b2)
+	d3 := cross(b1, b2, a1)
+	d4 := cross(b1, b2, a2)
+	return ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0))
+}
+
+// which side of the line origin->to the point is on
+func cross(origin, to, point WorldCoords) float32 {
+	return (to.X-origin.X)*(point.Y-origin.Y) - (to.Y-origin.Y)*(point.X-origin.X)
+}
+
+func abs32(value float32) float32 {
+	return float32(math.Abs(float64(value)))
+}
+
+// Points around the shape in order, for drawing it. Round parts are approximated.
+func (c *Collider2D) outlineAt(center WorldCoords) []WorldCoords {
+	const roundSegments int = 16
+	core, radius := c.shape().core()
+	if radius == 0.0 {
+		return translated(core, center)
+	}
+	// the furthest core point in each direction, pushed out by the radius. Core points tied for
+	// furthest (ex. both ends of a capsule, sideways) are all kept, in outline order, so the
+	// straight sides come out straight.
+	const tolerance float32 = 0.001
+	outline := make([]WorldCoords, 0, roundSegments+len(core))
+	for i := range roundSegments {
+		angle := 2.0 * math.Pi * float64(i) / float64(roundSegments)
+		direction := WorldCoords{X: float32(math.Cos(angle)), Y: float32(math.Sin(angle))}
+		furthest := float32(math.Inf(-1))
+		for _, point := range core {
+			furthest = max(furthest, point.X*direction.X+point.Y*direction.Y)
+		}
+		var supports []WorldCoords
+		for _, point := range core {
+			if point.X*direction.X+point.Y*direction.Y >= furthest-tolerance {
+				supports = append(supports, point)
+			}
+		}
+		// counterclockwise, so along the tangent (-y, x)
+		slices.SortFunc(supports, func(a WorldCoords, b WorldCoords) int {
+			return cmp.Compare(-a.X*direction.Y+a.Y*direction.X, -b.X*direction.Y+b.Y*direction.X)
+		})
+		for _, support := range supports {
+			outline = append(outline, WorldCoords{
+				X: center.X + support.X + radius*direction.X,
+				Y: center.Y + support.Y + radius*direction.Y,
+			})
+		}
+	}
+	return outline
+}
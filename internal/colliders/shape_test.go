@@ -0,0 +1,194 @@
+package colliders
+
+import (
+	"math"
+	"testing"
+)
+
+const testTolerance float32 = 0.001
+
+func closeTo(got float32, want float32, tolerance float32) bool {
+	return float32(math.Abs(float64(got-want))) <= tolerance
+}
+
+func TestSeparation(t *testing.T) {
+	triangle := Polygon{Points: []WorldCoords{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 0, Y: 10}}}
+	vertical := Capsule{Radius: 2, Length: 10}
+	horizontal := Capsule{Radius: 2, Length: 10, Horizontal: true}
+
+	tests := []struct {
+		name        string
+		shape       Shape
+		center      WorldCoords
+		otherShape  Shape
+		otherCenter WorldCoords
+		want        float32
+	}{
+		{
+			name:  "boxes apart",
+			shape: Box{Width: 10, Height: 10}, otherShape: Box{Width: 10, Height: 10},
+			otherCenter: WorldCoords{X: 15, Y: 0},
+			want:        5,
+		},
+		{
+			name:  "boxes apart corner to corner",
+			shape: Box{Width: 10, Height: 10}, otherShape: Box{Width: 10, Height: 10},
+			otherCenter: WorldCoords{X: 20, Y: 20},
+			want:        float32(math.Sqrt(200)),
+		},
+		{
+			name:  "boxes touching",
+			shape: Box{Width: 10, Height: 10}, otherShape: Box{Width: 10, Height: 10},
+			otherCenter: WorldCoords{X: 10, Y: 3},
+			want:        0,
+		},
+		{
+			name:  "boxes overlapping",
+			shape: Box{Width: 10, Height: 10}, otherShape: Box{Width: 10, Height: 10},
+			otherCenter: WorldCoords{X: 5, Y: 0},
+			want:        0,
+		},
+		{
+			name:  "box inside box",
+			shape: Box{Width: 2, Height: 2}, otherShape: Box{Width: 10, Height: 10},
+			want: 0,
+		},
+		{
+			name:  "circles apart",
+			shape: Circle{Radius: 5}, otherShape: Circle{Radius: 5},
+			otherCenter: WorldCoords{X: 13, Y: 0},
+			want:        3,
+		},
+		{
+			name:  "circles overlapping",
+			shape: Circle{Radius: 5}, otherShape: Circle{Radius: 5},
+			otherCenter: WorldCoords{X: 0, Y: 0},
+			want:        -10,
+		},
+		{
+			name:  "circle and box side",
+			shape: Circle{Radius: 5}, otherShape: Box{Width: 10, Height: 10},
+			otherCenter: WorldCoords{X: 12, Y: 0},
+			want:        2,
+		},
+		{
+			name:  "circle in box corner",
+			shape: Circle{Radius: 5}, otherShape: Box{Width: 10, Height: 10},
+			otherCenter: WorldCoords{X: 8, Y: 8},
+			want:        float32(math.Sqrt(18)) - 5,
+		},
+		{
+			name:  "capsule end and circle",
+			shape: vertical, otherShape: Circle{Radius: 1},
+			otherCenter: WorldCoords{X: 0, Y: 10},
+			want:        2,
+		},
+		{
+			name:  "capsule side and circle",
+			shape: vertical, otherShape: Circle{Radius: 1},
+			otherCenter: WorldCoords{X: 6, Y: 4},
+			want:        3,
+		},
+		{
+			name:  "capsules crossing",
+			shape: vertical, otherShape: horizontal,
+			want: -4,
+		},
+		{
+			name:  "capsules end on side",
+			shape: vertical, otherShape: horizontal,
+			center: WorldCoords{X: 5, Y: 0},
+			want:   -4,
+		},
+		{
+			name:  "parallel capsules",
+			shape: vertical, otherShape: vertical,
+			otherCenter: WorldCoords{X: 7, Y: 2},
+			want:        3,
+		},
+		{
+			name:  "polygon edge and box corner",
+			shape: triangle, otherShape: Box{Width: 2, Height: 2},
+			otherCenter: WorldCoords{X: 7, Y: 7},
+			want:        float32(math.Sqrt(2)),
+		},
+		{
+			name:  "polygon edge touching box corner",
+			shape: triangle, otherShape: Box{Width: 2, Height: 2},
+			otherCenter: WorldCoords{X: 6, Y: 6},
+			want:        0,
+		},
+		{
+			name:  "box corner in polygon",
+			shape: triangle, otherShape: Box{Width: 2, Height: 2},
+			otherCenter: WorldCoords{X: 4, Y: 4},
+			want:        0,
+		},
+		{
+			// the bounding boxes overlap, the shapes don't
+			name:  "polygon and circle past its edge",
+			shape: triangle, otherShape: Circle{Radius: 1},
+			otherCenter: WorldCoords{X: 7, Y: 7},
+			want:        float32(4/math.Sqrt2) - 1,
+		},
+		{
+			name:        "clockwise polygon",
+			shape:       Polygon{Points: []WorldCoords{{X: 0, Y: 10}, {X: 10, Y: 0}, {X: 0, Y: 0}}},
+			otherShape:  Box{Width: 2, Height: 2},
+			otherCenter: WorldCoords{X: 4, Y: 4},
+			want:        0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Collider2D{Shape: test.shape}
+			other := &Collider2D{Shape: test.otherShape, CenterCoords: test.otherCenter}
+			got := separation(c, test.center, other)
+			if !closeTo(got, test.want, testTolerance) {
+				t.Errorf("separation = %v, want %v", got, test.want)
+			}
+			// the same distance seen from the other side
+			got = separation(other, test.otherCenter, &Collider2D{
+				Shape: test.shape, CenterCoords: test.center,
+			})
+			if !closeTo(got, test.want, testTolerance) {
+				t.Errorf("reversed separation = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSatOverlap(t *testing.T) {
+	square := []WorldCoords{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 4}}
+	diamond := func(x float32, y float32) []WorldCoords {
+		return []WorldCoords{{X: x, Y: y - 2}, {X: x + 2, Y: y}, {X: x, Y: y + 2}, {X: x - 2, Y: y}}
+	}
+
+	tests := []struct {
+		name string
+		a    []WorldCoords
+		b    []WorldCoords
+		want bool
+	}{
+		{"same", square, square, true},
+		{"overlapping", square, diamond(5, 2), true},
+		{"touching corner to edge", square, diamond(6, 2), true},
+		// apart only along the diamond's diagonal edges, not the square's axes
+		{"apart diagonally", square, diamond(5.5, 5.5), false},
+		{"apart on an axis", square, diamond(7, 2), false},
+		{"point inside", square, []WorldCoords{{X: 1, Y: 1}}, true},
+		{"point outside", square, []WorldCoords{{X: 5, Y: 1}}, false},
+		{"segment through", square, []WorldCoords{{X: -1, Y: 2}, {X: 5, Y: 2}}, true},
+		{"segment past", square, []WorldCoords{{X: 5, Y: -1}, {X: 5, Y: 5}}, false},
+	}
+
+	for _, test := range tests {
+		if got := satOverlap(test.a, test.b); got != test.want {
+			t.Errorf("%v: satOverlap = %v, want %v", test.name, got, test.want)
+		}
+		if got := satOverlap(test.b, test.a); got != test.want {
+			t.Errorf("%v: reversed satOverlap = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package colliders
+
+// Continuous collision detection. Instead of testing points along a move, each collider near the
+// swept area gets the time of impact (TOI) of the move against it: the fraction of the move done
+// when the two first touch. Bounding boxes give it exactly (swept AABB), other shapes continue
+// from there by conservative advancement (never move further than the current gap, since nothing
+// can get closer faster than it moves).
+
+import (
+	"math"
+)
+
+// Moves stop this far short of what they hit, so the next move doesn't start out touching
+const skinWidth float32 = 0.05
+
+// Colliders this close count as touching for enter/exit and TouchingBlockers, as blocked moves
+// leave a skinWidth gap
+const contactTolerance float32 = 2.0 * skinWidth
+
+const maxAdvanceSteps int = 32
+
+type Hit struct {
+	Collider *Collider2D
+	// fraction of the move done when the colliders touched, from 0.0 to 1.0
+	Time float32
+	// unit vector pointing out of Collider, towards the collider that hit it
+	Normal WorldCoords
+}
+
+// When (if at all) c, moving from start by delta, first touches other. A collider that starts out
+// touching (or stuck inside) other only hits it if the move goes deeper, so it can still leave.
+func timeOfImpact(
+	c *Collider2D, start WorldCoords, delta WorldCoords, other *Collider2D,
+) (float32, WorldCoords, bool) {
+	length := vecLength(delta)
+	if length == 0.0 {
+		return 0.0, WorldCoords{}, false
+	}
+	startSeparation := separation(c, start, other)
+	if startSeparation <= 0.0 {
+		nudge := min(skinWidth/length, 1.0)
+		nudgedSeparation := separation(c, along(start, delta, nudge), other)
+		if nudgedSeparation > startSeparation {
+			return 0.0, WorldCoords{}, false
+		}
+		if nudgedSeparation < startSeparation {
+			return 0.0, normalAt(c, start, other), true
+		}
+		// polygons overlapping by any amount are all 0 apart, so go by which side it's moving to.
+		// No way out at all means it's deep inside, let it go
+		gradient := separationGradient(c, start, other)
+		if gradient.X*delta.X+gradient.Y*delta.Y >= 0.0 {
+			return 0.0, WorldCoords{}, false
+		}
+		return 0.0, normalAt(c, start, other), true
+	}
+
+	// the shapes are inside their bounding boxes, so they can't touch any earlier than those do
+	t, normal, ok := sweptBoxes(c, start, delta, other)
+	if !ok {
+		return 0.0, WorldCoords{}, false
+	}
+	if isBox(c) && isBox(other) {
+		return t, normal, true
+	}
+
+	for range maxAdvanceSteps {
+		gap := separation(c, along(start, delta, t), other)
+		if gap <= skinWidth/2.0 {
+			return t, normalAt(c, along(start, delta, t), other), true
+		}
+		t += gap / length
+		if t > 1.0 {
+			return 0.0, WorldCoords{}, false
+		}
+	}
+	// still closing in slowly (ex. sliding into a corner), close enough
+	return t, normalAt(c, along(start, delta, t), other), true
+}
+
+// Swept AABB: the move is a ray from c's center against other's box grown by c's half size
+func sweptBoxes(
+	c *Collider2D, start WorldCoords, delta WorldCoords, other *Collider2D,
+) (float32, WorldCoords, bool) {
+	entry := [2]float32{float32(math.Inf(-1)), float32(math.Inf(-1))}
+	exit := [2]float32{float32(math.Inf(1)), float32(math.Inf(1))}
+	from := [2]float32{start.X, start.Y}
+	move := [2]float32{delta.X, delta.Y}
+	target := [2]float32{other.CenterCoords.X, other.CenterCoords.Y}
+	reach := [2]float32{(c.Width + other.Width) / 2.0, (c.Height + other.Height) / 2.0}
+
+	for axis := range 2 {
+		low := target[axis] - reach[axis]
+		high := target[axis] + reach[axis]
+		if move[axis] == 0.0 {
+			if from[axis] < low || from[axis] > high {
+				return 0.0, WorldCoords{}, false
+			}
+			continue
+		}
+		t1 := (low - from[axis]) / move[axis]
+		t2 := (high - from[axis]) / move[axis]
+		entry[axis] = min(t1, t2)
+		exit[axis] = max(t1, t2)
+	}
+
+	enter := max(entry[0], entry[1])
+	leave := min(exit[0], exit[1])
+	if enter > leave || enter > 1.0 || leave < 0.0 {
+		return 0.0, WorldCoords{}, false
+	}
+	enter = max(enter, 0.0)
+
+	// the side we came through is the axis we entered last on
+	if entry[0] >= entry[1] {
+		return enter, WorldCoords{X: -sign(delta.X), Y: 0.0}, true
+	}
+	return enter, WorldCoords{X: 0.0, Y: -sign(delta.Y)}, true
+}
+
+// Direction separation grows fastest in, found numerically so it works for every shape
+func normalAt(c *Collider2D, center WorldCoords, other *Collider2D) WorldCoords {
+	gradient := separationGradient(c, center, other)
+	length := vecLength(gradient)
+	if length == 0.0 {
+		// dead center of other, any way out will do
+		return WorldCoords{X: 0.0, Y: 1.0}
+	}
+	return WorldCoords{X: gradient.X / length, Y: gradient.Y / length}
+}
+
+// Not normalized, zero if no small move changes the separation
+func separationGradient(c *Collider2D, center WorldCoords, other *Collider2D) WorldCoords {
+	const eps float32 = 0.5
+	return WorldCoords{
+		X: separation(c, WorldCoords{X: center.X + eps, Y: center.Y}, other) -
+			separation(c, WorldCoords{X: center.X - eps, Y: center.Y}, other),
+		Y: separation(c, WorldCoords{X: center.X, Y: center.Y + eps}, other) -
+			separation(c, WorldCoords{X: center.X, Y: center.Y - eps}, other),
+	}
+}
+
+func isBox(c *Collider2D) bool {
+	if c.Shape == nil {
+		return true
+	}
+	_, ok := c.Shape.(Box)
+	return ok
+}
+
+func along(start WorldCoords, delta WorldCoords, t float32) WorldCoords {
+	return WorldCoords{X: start.X + delta.X*t, Y: start.Y + delta.Y*t}
+}
+
+func vecLength(v WorldCoords) float32 {
+	return float32(math.Sqrt(float64(v.X*v.X + v.Y*v.Y)))
+}
+
+func sign(value float32) float32 {
+	if value < 0.0 {
+		return -1.0
+	}
+	return 1.0
+}
@@ -0,0 +1,153 @@
+package colliders
+
+import (
+	"testing"
+)
+
+func TestTimeOfImpact(t *testing.T) {
+	box := Box{Width: 10, Height: 10}
+	circle := Circle{Radius: 5}
+
+	tests := []struct {
+		name        string
+		shape       Shape
+		start       WorldCoords
+		delta       WorldCoords
+		otherShape  Shape
+		otherCenter WorldCoords
+		wantHit     bool
+		wantTime    float32
+		wantNormal  WorldCoords
+	}{
+		{
+			name:  "box into box",
+			shape: box, otherShape: box,
+			delta:       WorldCoords{X: 20, Y: 0},
+			otherCenter: WorldCoords{X: 20, Y: 0},
+			wantHit:     true,
+			wantTime:    0.5,
+			wantNormal:  WorldCoords{X: -1, Y: 0},
+		},
+		{
+			name:  "box down into box",
+			shape: box, otherShape: box,
+			delta:       WorldCoords{X: 0, Y: -40},
+			otherCenter: WorldCoords{X: 0, Y: -20},
+			wantHit:     true,
+			wantTime:    0.25,
+			wantNormal:  WorldCoords{X: 0, Y: 1},
+		},
+		{
+			name:  "box diagonally into box side",
+			shape: box, otherShape: box,
+			delta:       WorldCoords{X: 20, Y: 10},
+			otherCenter: WorldCoords{X: 20, Y: 8},
+			wantHit:     true,
+			wantTime:    0.5,
+			wantNormal:  WorldCoords{X: -1, Y: 0},
+		},
+		{
+			name:  "box stops short",
+			shape: box, otherShape: box,
+			delta:       WorldCoords{X: 5, Y: 0},
+			otherCenter: WorldCoords{X: 20, Y: 0},
+		},
+		{
+			name:  "box moves away",
+			shape: box, otherShape: box,
+			delta:       WorldCoords{X: -20, Y: 0},
+			otherCenter: WorldCoords{X: 20, Y: 0},
+		},
+		{
+			name:  "box passes by",
+			shape: box, otherShape: box,
+			delta:       WorldCoords{X: 40, Y: 0},
+			otherCenter: WorldCoords{X: 20, Y: 11},
+		},
+		{
+			name:  "no move",
+			shape: box, otherShape: box,
+			otherCenter: WorldCoords{X: 10, Y: 0},
+		},
+		{
+			name:  "touching, moving in",
+			shape: box, otherShape: box,
+			delta:       WorldCoords{X: 5, Y: 0},
+			otherCenter: WorldCoords{X: 10, Y: 0},
+			wantHit:     true,
+			wantTime:    0,
+			wantNormal:  WorldCoords{X: -1, Y: 0},
+		},
+		{
+			name:  "touching, moving out",
+			shape: box, otherShape: box,
+			delta:       WorldCoords{X: -5, Y: 0},
+			otherCenter: WorldCoords{X: 10, Y: 0},
+		},
+		{
+			name:  "touching, sliding along",
+			shape: box, otherShape: box,
+			delta:       WorldCoords{X: 0, Y: 5},
+			otherCenter: WorldCoords{X: 10, Y: 0},
+		},
+		{
+			name:  "stuck inside, moving any way",
+			shape: Box{Width: 2, Height: 2}, otherShape: box,
+			delta: WorldCoords{X: 0, Y: -5},
+		},
+		{
+			// conservative advancement stops within half a skin width
+			name:  "circle into circle",
+			shape: circle, otherShape: circle,
+			delta:       WorldCoords{X: 20, Y: 0},
+			otherCenter: WorldCoords{X: 20, Y: 0},
+			wantHit:     true,
+			wantTime:    0.5,
+			wantNormal:  WorldCoords{X: -1, Y: 0},
+		},
+		{
+			// touches the corner at x = 15 - sqrt(5^2 - 4.5^2)
+			name:  "circle into box corner",
+			shape: circle, otherShape: box,
+			delta:       WorldCoords{X: 40, Y: 0},
+			otherCenter: WorldCoords{X: 20, Y: 9.5},
+			wantHit:     true,
+			wantTime:    0.3205,
+			wantNormal:  WorldCoords{X: -0.4359, Y: -0.9},
+		},
+		{
+			// the bounding boxes meet on the way, the circles don't
+			name:  "circle passes circle",
+			shape: circle, otherShape: circle,
+			delta:       WorldCoords{X: 40, Y: 40},
+			otherCenter: WorldCoords{X: 30, Y: 15},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Collider2D{Shape: test.shape, CenterCoords: test.start}
+			c.Width, c.Height = test.shape.BoundingBox()
+			other := &Collider2D{Shape: test.otherShape, CenterCoords: test.otherCenter}
+			other.Width, other.Height = test.otherShape.BoundingBox()
+
+			gotTime, gotNormal, gotHit := timeOfImpact(c, test.start, test.delta, other)
+			if gotHit != test.wantHit {
+				t.Fatalf("hit = %v, want %v (time %v)", gotHit, test.wantHit, gotTime)
+			}
+			if !gotHit {
+				return
+			}
+			// a skin width along the move, plus rounding
+			tolerance := skinWidth/vecLength(test.delta) + testTolerance
+			if !closeTo(gotTime, test.wantTime, tolerance) {
+				t.Errorf("time = %v, want %v", gotTime, test.wantTime)
+			}
+			// normals of round shapes come from a numeric gradient
+			if !closeTo(gotNormal.X, test.wantNormal.X, 0.05) ||
+				!closeTo(gotNormal.Y, test.wantNormal.Y, 0.05) {
+				t.Errorf("normal = %v, want %v", gotNormal, test.wantNormal)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package debugOverlay
+
+// Package level state held by private singleton initialized at program start.
+// Draws what the collision system sees on top of the game: every collider's outline (colored by
+// its lowest layer), the collider map cells that have colliders in them, where recent blocked
+// moves made contact, and the camera. Toggled with F3.
+
+import (
+	"math/bits"
+	"sync"
+	"sync/atomic"
+	"weak"
+
+	"github.com/PatrickKoch07/game-proj/internal/camera"
+	"github.com/PatrickKoch07/game-proj/internal/colliders"
+	"github.com/PatrickKoch07/game-proj/internal/inputs"
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+	"github.com/PatrickKoch07/game-proj/internal/primitives"
+	"github.com/PatrickKoch07/game-proj/internal/sprites"
+)
+
+const ToggleKey inputs.Key = inputs.KeyF3
+
+// half size of the cross drawn on contact points and the camera center, in world units
+const markerSize float32 = 4.0
+
+// by layer bit, repeating for layers past the end
+var layerColors = []sprites.Color{
+	{R: 1.0, G: 1.0, B: 1.0, A: 1.0},
+	{R: 0.2, G: 1.0, B: 0.2, A: 1.0},
+	{R: 0.2, G: 0.8, B: 1.0, A: 1.0},
+	{R: 1.0, G: 0.9, B: 0.2, A: 1.0},
+	{R: 1.0, G: 0.3, B: 1.0, A: 1.0},
+	{R: 1.0, G: 0.6, B: 0.2, A: 1.0},
+}
+
+var cellColor = sprites.Color{R: 0.5, G: 0.5, B: 0.5, A: 0.35}
+var contactColor = sprites.Color{R: 1.0, G: 0.1, B: 0.1, A: 1.0}
+var cameraColor = sprites.Color{R: 1.0, G: 1.0, B: 0.0, A: 0.8}
+
+type overlay struct {
+	enabled       atomic.Bool
+	inputListener inputs.InputListener
+}
+
+var activeOverlay *overlay
+var once sync.Once
+
+func initOverlay() {
+	logger.LOG.Info().Msg("Creating debug overlay")
+	activeOverlay = new(overlay)
+	activeOverlay.inputListener = inputs.InputListener(activeOverlay)
+	ok := inputs.GetInputManager().Subscribe(ToggleKey, weak.Make(&activeOverlay.inputListener))
+	if !ok {
+		logger.LOG.Error().Msg("Debug overlay couldn't subscribe to its toggle key")
+	}
+}
+
+func GetOverlay() *overlay {
+	once.Do(initOverlay)
+	return activeOverlay
+}
+
+func (o *overlay) SetEnabled(enabled bool) {
+	o.enabled.Store(enabled)
+}
+
+func (o *overlay) IsEnabled() bool {
+	return o.enabled.Load()
+}
+
+func (o *overlay) OnKeyAction(keyAction inputs.KeyAction) {
+	if keyAction.Key == ToggleKey && keyAction.Action == inputs.Press {
+		o.enabled.Store(!o.enabled.Load())
+		logger.LOG.Debug().Msgf("Debug overlay enabled: %v", o.enabled.Load())
+	}
+}
+
+// Queues this frame's lines. Call before the draw queue draws.
+// should only be called in the main thread
+func (o *overlay) Draw() {
+	if !o.enabled.Load() {
+		return
+	}
+	snapshot := colliders.GetDebugSnapshot()
+
+	cellStyle := primitives.Style{Color: cellColor, Space: primitives.WorldSpace}
+	for _, cell := range snapshot.Cells {
+		center := primitives.Point{X: (cell.Min.X + cell.Max.X) / 2.0, Y: (cell.Min.Y + cell.Max.Y) / 2.0}
+		primitives.DrawRect(center, cell.Max.X-cell.Min.X, cell.Max.Y-cell.Min.Y, cellStyle)
+	}
+	for _, collider := range snapshot.Colliders {
+		style := primitives.Style{Color: layerColor(collider.Layers), Space: primitives.WorldSpace}
+		if len(collider.Outline) == 1 {
+			drawCross(primitives.Point(collider.Outline[0]), style)
+			continue
+		}
+		outline := make([]primitives.Point, len(collider.Outline))
+		for i, point := range collider.Outline {
+			outline[i] = primitives.Point(point)
+		}
+		primitives.DrawPolygon(outline, style)
+	}
+	contactStyle := primitives.Style{Color: contactColor, Thickness: 2.0, Space: primitives.WorldSpace}
+	for _, point := range snapshot.Contacts {
+		drawCross(primitives.Point(point), contactStyle)
+	}
+
+	// the camera's view in the world (one world unit per pixel), pulled in to stay on screen
+	cam := camera.GetCamera()
+	cameraStyle := primitives.Style{Color: cameraColor, Thickness: 2.0, Space: primitives.WorldSpace}
+	primitives.DrawRect(
+		primitives.Point(cam.WorldCenter),
+		float32(cam.ScreenWidth)-2.0,
+		float32(cam.ScreenHeight)-2.0,
+		cameraStyle,
+	)
+	drawCross(primitives.Point(cam.WorldCenter), cameraStyle)
+}
+
+func drawCross(center primitives.Point, style primitives.Style) {
+	primitives.DrawLine(
+		primitives.Point{X: center.X - markerSize, Y: center.Y - markerSize},
+		primitives.Point{X: center.X + markerSize, Y: center.Y + markerSize},
+		style,
+	)
+	primitives.DrawLine(
+		primitives.Point{X: center.X - markerSize, Y: center.Y + markerSize},
+		primitives.Point{X: center.X + markerSize, Y: center.Y - markerSize},
+		style,
+	)
+}
+
+func layerColor(layers colliders.Layer) sprites.Color {
+	if layers == 0 {
+		return layerColors[0]
+	}
+	return layerColors[bits.TrailingZeros32(uint32(layers))%len(layerColors)]
+}
@@ -0,0 +1,209 @@
+package ecs
+
+// The built in components and the systems for them
+
+import (
+	"errors"
+	"slices"
+	"weak"
+
+	"github.com/PatrickKoch07/game-proj/internal/audio"
+	"github.com/PatrickKoch07/game-proj/internal/colliders"
+	"github.com/PatrickKoch07/game-proj/internal/inputs"
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+	"github.com/PatrickKoch07/game-proj/internal/particles"
+	"github.com/PatrickKoch07/game-proj/internal/physics"
+	"github.com/PatrickKoch07/game-proj/internal/sprites"
+)
+
+// Drawn while the entity has it, where its Transform is (or its Collider, without one)
+type Sprite struct {
+	*sprites.Sprite
+	// size when added, what the Transform's scale multiplies
+	baseDimX float32
+	baseDimY float32
+}
+
+func (s *Sprite) Attach(w *World, e Entity) error {
+	if s.Sprite == nil {
+		return errors.New("nil sprite")
+	}
+	s.baseDimX = s.Tex.DimX
+	s.baseDimY = s.Tex.DimY
+	sprites.GetDrawQueue().AddToQueue(weak.Make(s.Sprite))
+	return nil
+}
+
+func (s *Sprite) Detach(w *World, e Entity) {
+	sprites.GetDrawQueue().RemoveFromQueue(weak.Make(s.Sprite))
+}
+
+// In the collider maps while the entity has it. Colliders without a parent get the world.
+type Collider struct {
+	*colliders.Collider2D
+}
+
+func (c *Collider) Attach(w *World, e Entity) error {
+	if c.Collider2D == nil {
+		return errors.New("nil collider")
+	}
+	if c.Parent == nil {
+		c.Parent = &w.gameObject
+	}
+	colliders.AddColliderToMaps(c.Collider2D)
+	return nil
+}
+
+func (c *Collider) Detach(w *World, e Entity) {
+	colliders.RemoveColliderFromMaps(c.Collider2D)
+}
+
+// Moved by the physics world while the entity has it. The body's collider should be the entity's
+// Collider.
+type Body struct {
+	*physics.Body
+}
+
+func (b *Body) Attach(w *World, e Entity) error {
+	if b.Body == nil {
+		return errors.New("nil body")
+	}
+	physics.GetWorld().AddBody(b.Body)
+	return nil
+}
+
+func (b *Body) Detach(w *World, e Entity) {
+	physics.GetWorld().RemoveBody(b.Body)
+}
+
+// Cleared when the entity loses it
+type Audio struct {
+	audio.Player
+}
+
+func (a *Audio) Detach(w *World, e Entity) {
+	if a.Player == nil {
+		return
+	}
+	err := a.Player.Clear()
+	if err != nil {
+		logger.LOG.Warn().Err(err).Msg("Trying to continue anyway")
+	}
+}
+
+// Updated by the world instead of a scene. Spawns particles where the entity's Transform is,
+// unless it has a Follow
+type Emitter struct {
+	*particles.Emitter
+	sprites []*sprites.Sprite
+}
+
+func (em *Emitter) Attach(w *World, e Entity) error {
+	if em.Emitter == nil {
+		return errors.New("nil emitter")
+	}
+	_, emitterSprites, _, ok := em.Emitter.InitInstance()
+	if !ok {
+		return errors.New("failed to init emitter")
+	}
+	em.sprites = emitterSprites
+	return nil
+}
+
+func (em *Emitter) Detach(w *World, e Entity) {
+	em.Emitter.Kill()
+}
+
+// Per entity behaviour, called every update. Scripts run one at a time and can change anything,
+// including adding and removing components and entities.
+type Script struct {
+	Update func(w *World, e Entity)
+}
+
+// Gets the key actions for Keys, at the start of the world's update (so in the main thread)
+type Input struct {
+	Keys        []inputs.Key
+	OnKeyAction func(w *World, e Entity, ka inputs.KeyAction)
+}
+
+func (in *Input) Attach(w *World, e Entity) error {
+	for _, key := range in.Keys {
+		ok := inputs.GetInputManager().Subscribe(key, weak.Make(&w.inputListener))
+		if !ok {
+			return errors.New("failed to subscribe to key")
+		}
+	}
+	return nil
+}
+
+// The world stays subscribed to the keys other Inputs still use
+func (in *Input) Detach(w *World, e Entity) {
+	stillUsed := make(map[inputs.Key]bool)
+	Query[Input](w).Each(func(other Entity, otherIn *Input) {
+		if other == e {
+			return
+		}
+		for _, key := range otherIn.Keys {
+			stillUsed[key] = true
+		}
+	})
+	for _, key := range in.Keys {
+		if stillUsed[key] {
+			continue
+		}
+		stillUsed[key] = true
+		err := inputs.GetInputManager().Unsubscribe(key, weak.Make(&w.inputListener))
+		if err != nil {
+			logger.LOG.Warn().Err(err).Msg("Trying to continue anyway")
+		}
+	}
+}
+
+var scriptSystem = NewSystem(
+	Access{Exclusive: true},
+	func(w *World) {
+		// scripts can add and remove scripts, so go through who had one at the start
+		scripts := Query[Script](w)
+		for _, e := range slices.Clone(scripts.entities) {
+			script, ok := scripts.Get(e)
+			if ok && script.Update != nil {
+				script.Update(w, e)
+			}
+		}
+	},
+)
+
+var emitterSystem = NewSystem(
+	Access{
+		Reads:  []ComponentType{TypeOf[Transform]()},
+		Writes: []ComponentType{TypeOf[Emitter]()},
+	},
+	func(w *World) {
+		transforms := Query[Transform](w)
+		Query[Emitter](w).Each(func(e Entity, em *Emitter) {
+			if em.IsDead() || em.ShouldSkipUpdate() {
+				return
+			}
+			if t, ok := transforms.Get(e); ok && em.Params.Follow == nil {
+				em.Params.Position = t.world.Position
+			}
+			em.Update()
+		})
+	},
+)
+
+// should only be called in the main thread
+func deliverKeyActions(w *World, keyActions []inputs.KeyAction) {
+	if len(keyActions) == 0 {
+		return
+	}
+	listeners := Query[Input](w)
+	for _, ka := range keyActions {
+		for _, e := range slices.Clone(listeners.entities) {
+			in, ok := listeners.Get(e)
+			if ok && in.OnKeyAction != nil && slices.Contains(in.Keys, ka.Key) {
+				in.OnKeyAction(w, e, ka)
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Identifies a component type, for declaring what systems use (see Access)
+type ComponentType reflect.Type
+
+func TypeOf[T any]() ComponentType {
+	return reflect.TypeFor[T]()
+}
+
+// Optional for components. Attach is called when the component is added to an entity and Detach
+// when it's removed (or the entity is destroyed), for setting up and cleaning up anything outside
+// the world (ex. the draw queue or the collider maps). A failed Attach isn't added.
+type Attacher interface {
+	Attach(w *World, e Entity) error
+}
+
+type Detacher interface {
+	Detach(w *World, e Entity)
+}
+
+// Every component of one type, packed together. Removing swaps the last component into the hole,
+// so the order isn't kept. Not locked: which systems touch it at the same time is kept safe by
+// their declared Access, and adding or removing only happens outside of them (see World.Later).
+type Storage[T any] struct {
+	components []T
+	entities   []Entity
+	index      map[Entity]int
+}
+
+type storage interface {
+	remove(w *World, e Entity)
+}
+
+func (s *Storage[T]) Len() int {
+	return len(s.components)
+}
+
+// The pointer is only good until a component of the same type is added or removed
+func (s *Storage[T]) Get(e Entity) (*T, bool) {
+	i, ok := s.index[e]
+	if !ok {
+		return nil, false
+	}
+	return &s.components[i], true
+}
+
+// In storage order, which is not the order they were added in
+func (s *Storage[T]) Each(f func(e Entity, component *T)) {
+	for i := range s.components {
+		f(s.entities[i], &s.components[i])
+	}
+}
+
+// should not already have one
+func (s *Storage[T]) add(e Entity, component T) {
+	s.index[e] = len(s.components)
+	s.components = append(s.components, component)
+	s.entities = append(s.entities, e)
+}
+
+func (s *Storage[T]) remove(w *World, e Entity) {
+	i, ok := s.index[e]
+	if !ok {
+		return
+	}
+	if detacher, ok := any(&s.components[i]).(Detacher); ok {
+		detacher.Detach(w, e)
+	}
+	last := len(s.components) - 1
+	s.components[i] = s.components[last]
+	s.entities[i] = s.entities[last]
+	s.index[s.entities[i]] = i
+	var zero T
+	s.components[last] = zero
+	s.components = s.components[:last]
+	s.entities = s.entities[:last]
+	delete(s.index, e)
+}
+
+// The storage for one component type, made if there isn't one yet
+// thread safe by locking
+func Query[T any](w *World) *Storage[T] {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	s, ok := w.storages[TypeOf[T]()]
+	if !ok {
+		s = &Storage[T]{index: make(map[Entity]int)}
+		w.storages[TypeOf[T]()] = s
+	}
+	return s.(*Storage[T])
+}
+
+// Gives the entity a component, replacing any it had of the same type. From systems other than
+// scripts, use World.Later.
+// should only be called in the main thread
+func Add[T any](w *World, e Entity, component T) error {
+	if !w.IsAlive(e) {
+		return fmt.Errorf("entity %v isn't alive", e)
+	}
+	// the old one goes first, as it may share things with the new one (ex. the same sprite)
+	s := Query[T](w)
+	s.remove(w, e)
+	if attacher, ok := any(&component).(Attacher); ok {
+		err := attacher.Attach(w, e)
+		if err != nil {
+			return err
+		}
+	}
+	s.add(e, component)
+	return nil
+}
+
+// The pointer is only good until a component of the same type is added or removed
+func Get[T any](w *World, e Entity) (*T, bool) {
+	return Query[T](w).Get(e)
+}
+
+// From systems other than scripts, use World.Later.
+// should only be called in the main thread
+func Remove[T any](w *World, e Entity) {
+	Query[T](w).remove(w, e)
+}
@@ -0,0 +1,179 @@
+package ecs
+
+import (
+	"slices"
+	"testing"
+)
+
+type testComponent struct {
+	value    int
+	detached *[]int
+}
+
+func (c *testComponent) Detach(w *World, e Entity) {
+	*c.detached = append(*c.detached, c.value)
+}
+
+type storageOp struct {
+	remove bool
+	// index of the entity, in spawn order
+	entity int
+	value  int
+}
+
+func TestStorage(t *testing.T) {
+	add := func(entity int, value int) storageOp {
+		return storageOp{entity: entity, value: value}
+	}
+	remove := func(entity int) storageOp {
+		return storageOp{remove: true, entity: entity}
+	}
+
+	tests := []struct {
+		name string
+		ops  []storageOp
+		// values in storage order
+		want         []int
+		wantDetached []int
+	}{
+		{
+			name: "added in order",
+			ops:  []storageOp{add(0, 0), add(1, 1), add(2, 2)},
+			want: []int{0, 1, 2},
+		},
+		{
+			name:         "remove last",
+			ops:          []storageOp{add(0, 0), add(1, 1), add(2, 2), remove(2)},
+			want:         []int{0, 1},
+			wantDetached: []int{2},
+		},
+		{
+			name:         "remove first swaps the last in",
+			ops:          []storageOp{add(0, 0), add(1, 1), add(2, 2), remove(0)},
+			want:         []int{2, 1},
+			wantDetached: []int{0},
+		},
+		{
+			name:         "remove middle swaps the last in",
+			ops:          []storageOp{add(0, 0), add(1, 1), add(2, 2), add(3, 3), remove(1)},
+			want:         []int{0, 3, 2},
+			wantDetached: []int{1},
+		},
+		{
+			name:         "remove twice",
+			ops:          []storageOp{add(0, 0), add(1, 1), remove(0), remove(0)},
+			want:         []int{1},
+			wantDetached: []int{0},
+		},
+		{
+			name: "remove all",
+			ops: []storageOp{
+				add(0, 0), add(1, 1), add(2, 2), remove(0), remove(1), remove(2),
+			},
+			want:         []int{},
+			wantDetached: []int{0, 1, 2},
+		},
+		{
+			name: "remove one never added",
+			ops:  []storageOp{add(0, 0), add(1, 1), remove(2)},
+			want: []int{0, 1},
+		},
+		{
+			name:         "replace",
+			ops:          []storageOp{add(0, 0), add(1, 1), add(2, 2), add(0, 10)},
+			want:         []int{2, 1, 10},
+			wantDetached: []int{0},
+		},
+		{
+			name:         "add back after remove",
+			ops:          []storageOp{add(0, 0), add(1, 1), remove(0), add(0, 5)},
+			want:         []int{1, 5},
+			wantDetached: []int{0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := NewWorld()
+			entities := []Entity{w.Spawn(), w.Spawn(), w.Spawn(), w.Spawn()}
+			var detached []int
+			values := make(map[Entity]int)
+			for _, op := range test.ops {
+				e := entities[op.entity]
+				if op.remove {
+					Remove[testComponent](w, e)
+					delete(values, e)
+					continue
+				}
+				err := Add(w, e, testComponent{value: op.value, detached: &detached})
+				if err != nil {
+					t.Fatal(err)
+				}
+				values[e] = op.value
+			}
+
+			s := Query[testComponent](w)
+			if s.Len() != len(test.want) {
+				t.Fatalf("Len = %v, want %v", s.Len(), len(test.want))
+			}
+			got := []int{}
+			s.Each(func(e Entity, component *testComponent) {
+				got = append(got, component.value)
+				if component.value != values[e] {
+					t.Errorf("entity %v has %v, want %v", e, component.value, values[e])
+				}
+			})
+			if !slices.Equal(got, test.want) {
+				t.Errorf("storage order %v, want %v", got, test.want)
+			}
+			// the index still finds everything after the swaps
+			for _, e := range entities {
+				component, ok := s.Get(e)
+				value, want := values[e]
+				if ok != want {
+					t.Errorf("Get(%v) found %v, want %v", e, ok, want)
+				} else if ok && component.value != value {
+					t.Errorf("Get(%v) = %v, want %v", e, component.value, value)
+				}
+			}
+			if !slices.Equal(detached, test.wantDetached) {
+				t.Errorf("detached %v, want %v", detached, test.wantDetached)
+			}
+		})
+	}
+}
+
+func TestDestroyRemovesComponents(t *testing.T) {
+	w := NewWorld()
+	var detached []int
+	a := w.Spawn()
+	b := w.Spawn()
+	for _, e := range []Entity{a, b} {
+		if err := Add(w, e, testComponent{value: int(e), detached: &detached}); err != nil {
+			t.Fatal(err)
+		}
+		if err := Add(w, e, 1.5); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w.Destroy(a)
+	if w.IsAlive(a) || !w.IsAlive(b) {
+		t.Errorf("alive after destroy: a %v, b %v", w.IsAlive(a), w.IsAlive(b))
+	}
+	if _, ok := Get[testComponent](w, a); ok {
+		t.Error("destroyed entity still has its component")
+	}
+	if _, ok := Get[float64](w, a); ok {
+		t.Error("destroyed entity still has its float64")
+	}
+	if _, ok := Get[testComponent](w, b); !ok {
+		t.Error("other entity lost its component")
+	}
+	if !slices.Equal(detached, []int{int(a)}) {
+		t.Errorf("detached %v, want %v", detached, []int{int(a)})
+	}
+	if err := Add(w, a, 2.5); err == nil {
+		t.Error("added a component to a destroyed entity")
+	}
+}
@@ -0,0 +1,91 @@
+package ecs
+
+import (
+	"slices"
+	"sync"
+)
+
+// What components a system reads and writes. Systems next to each other that don't write anything
+// the other uses run at the same time, so the sets have to be complete.
+type Access struct {
+	Reads  []ComponentType
+	Writes []ComponentType
+	// can touch anything (ex. scripts), always runs alone
+	Exclusive bool
+}
+
+type System interface {
+	Access() Access
+	Run(w *World)
+}
+
+type funcSystem struct {
+	access Access
+	run    func(*World)
+}
+
+func (fs *funcSystem) Access() Access {
+	return fs.access
+}
+
+func (fs *funcSystem) Run(w *World) {
+	fs.run(w)
+}
+
+// For systems that are only a function
+func NewSystem(access Access, run func(w *World)) System {
+	return &funcSystem{access: access, run: run}
+}
+
+func (a Access) conflictsWith(other Access) bool {
+	if a.Exclusive || other.Exclusive {
+		return true
+	}
+	for _, written := range a.Writes {
+		if slices.Contains(other.Reads, written) || slices.Contains(other.Writes, written) {
+			return true
+		}
+	}
+	for _, written := range other.Writes {
+		if slices.Contains(a.Reads, written) {
+			return true
+		}
+	}
+	return false
+}
+
+// Runs the systems in order, a batch at a time. A batch is as many systems in a row as possible
+// that don't conflict with each other, which run at the same time.
+// should only be called in the main thread
+func runSystems(w *World, systems []System) {
+	for start := 0; start < len(systems); {
+		end := start + 1
+		for ; end < len(systems); end++ {
+			if conflictsWithAny(systems[end], systems[start:end]) {
+				break
+			}
+		}
+
+		batch := systems[start:end]
+		if len(batch) == 1 {
+			batch[0].Run(w)
+		} else {
+			var wg sync.WaitGroup
+			for _, system := range batch {
+				wg.Add(1)
+				go func() { defer wg.Done(); system.Run(w) }()
+			}
+			wg.Wait()
+		}
+		start = end
+	}
+}
+
+func conflictsWithAny(system System, others []System) bool {
+	for _, other := range others {
+		if system.Access().conflictsWith(other.Access()) {
+			return true
+		}
+	}
+	return false
+}
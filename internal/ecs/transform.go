@@ -0,0 +1,204 @@
+package ecs
+
+// Where entities are, relative to a parent entity (ex. a hat on a character). World transforms
+// are worked out every update, parents first, and the entity's Sprite, Collider and Emitter are
+// put there. So a child entity with its own Sprite sits at a local offset from its parent, and
+// moves, turns and mirrors with it.
+//
+// A root entity with a Collider goes where the collider is at the start of every update, as
+// colliders are moved by physics and blocked by other colliders. Setting its Position (ex. from a
+// script) requests a move to there instead. Bodies belong on root entities.
+
+import (
+	"math"
+
+	"github.com/PatrickKoch07/game-proj/internal/camera"
+	"github.com/PatrickKoch07/game-proj/internal/colliders"
+)
+
+// Make with NewTransform, the zero value has no scale
+type Transform struct {
+	// relative to the parent, in the parent's (rotated and scaled) coords
+	Position colliders.WorldCoords
+	// radians, counterclockwise
+	Rotation float32
+	// 1.0 is normal size, negative mirrors (ex. {X: -1.0, Y: 1.0} faces the other way)
+	Scale colliders.WorldCoords
+	// 0 is none. A parent without a Transform counts as none
+	Parent Entity
+	world  WorldTransform
+	// the world's update the world transform is from
+	frame uint64
+}
+
+type WorldTransform struct {
+	Position colliders.WorldCoords
+	Rotation float32
+	Scale    colliders.WorldCoords
+}
+
+func NewTransform(position colliders.WorldCoords, parent Entity) Transform {
+	return Transform{
+		Position: position,
+		Scale:    colliders.WorldCoords{X: 1.0, Y: 1.0},
+		Parent:   parent,
+	}
+}
+
+// As of the last update (or where it'll be once it runs, for new entities)
+func (t *Transform) World() WorldTransform {
+	if t.frame == 0 {
+		return noParent.apply(t)
+	}
+	return t.world
+}
+
+// what roots are relative to
+var noParent = WorldTransform{Scale: colliders.WorldCoords{X: 1.0, Y: 1.0}}
+
+// Where a child at local ends up
+func (parent WorldTransform) apply(local *Transform) WorldTransform {
+	x := local.Position.X * parent.Scale.X
+	y := local.Position.Y * parent.Scale.Y
+	sin, cos := math.Sincos(float64(parent.Rotation))
+	rotation := parent.Rotation + local.Rotation
+	// mirrored once, turns the other way
+	if parent.Scale.X*parent.Scale.Y < 0 {
+		rotation = parent.Rotation - local.Rotation
+	}
+	return WorldTransform{
+		Position: colliders.WorldCoords{
+			X: parent.Position.X + x*float32(cos) - y*float32(sin),
+			Y: parent.Position.Y + x*float32(sin) + y*float32(cos),
+		},
+		Rotation: rotation,
+		Scale: colliders.WorldCoords{
+			X: parent.Scale.X * local.Scale.X,
+			Y: parent.Scale.Y * local.Scale.Y,
+		},
+	}
+}
+
+// Children of e, and their children, ...
+// should only be called in the main thread
+func (w *World) descendants(e Entity) []Entity {
+	transforms := Query[Transform](w)
+	seen := map[Entity]bool{e: true}
+	found := []Entity{}
+	parents := []Entity{e}
+	for len(parents) > 0 {
+		parent := parents[0]
+		parents = parents[1:]
+		for i, t := range transforms.components {
+			child := transforms.entities[i]
+			if t.Parent == parent && !seen[child] {
+				seen[child] = true
+				found = append(found, child)
+				parents = append(parents, child)
+			}
+		}
+	}
+	return found
+}
+
+func isRoot(transforms *Storage[Transform], t *Transform) bool {
+	if t.Parent == 0 {
+		return true
+	}
+	_, ok := transforms.Get(t.Parent)
+	return !ok
+}
+
+// Root entities with colliders go where physics (or the move queue) left them
+var colliderToTransformSystem = NewSystem(
+	Access{
+		Reads:  []ComponentType{TypeOf[Collider]()},
+		Writes: []ComponentType{TypeOf[Transform]()},
+	},
+	func(w *World) {
+		transforms := Query[Transform](w)
+		Query[Collider](w).Each(func(e Entity, c *Collider) {
+			t, ok := transforms.Get(e)
+			if ok && isRoot(transforms, t) {
+				t.Position = c.CenterCoords
+			}
+		})
+	},
+)
+
+var transformSystem = NewSystem(
+	Access{Writes: []ComponentType{TypeOf[Transform]()}},
+	func(w *World) {
+		transforms := Query[Transform](w)
+		visiting := make(map[Entity]bool)
+		transforms.Each(func(e Entity, t *Transform) {
+			updateWorldTransform(transforms, e, t, w.frame, visiting)
+		})
+	},
+)
+
+// Parents first. A parent loop is cut where it comes back around
+func updateWorldTransform(
+	transforms *Storage[Transform],
+	e Entity,
+	t *Transform,
+	frame uint64,
+	visiting map[Entity]bool,
+) {
+	if t.frame == frame || visiting[e] {
+		return
+	}
+	visiting[e] = true
+	parentWorld := noParent
+	if parent, ok := transforms.Get(t.Parent); ok && t.Parent != 0 && !visiting[t.Parent] {
+		updateWorldTransform(transforms, t.Parent, parent, frame, visiting)
+		parentWorld = parent.world
+	}
+	t.world = parentWorld.apply(t)
+	t.frame = frame
+	delete(visiting, e)
+}
+
+// Colliders aren't turned, only moved
+var transformToColliderSystem = NewSystem(
+	Access{Reads: []ComponentType{TypeOf[Transform](), TypeOf[Collider]()}},
+	func(w *World) {
+		transforms := Query[Transform](w)
+		Query[Collider](w).Each(func(e Entity, c *Collider) {
+			t, ok := transforms.Get(e)
+			if ok && t.world.Position != c.CenterCoords {
+				c.RequestMove(t.world.Position, nil)
+			}
+		})
+	},
+)
+
+// Sprites without a Transform follow their Collider, if they have one
+var placeSpriteSystem = NewSystem(
+	Access{
+		Reads:  []ComponentType{TypeOf[Transform](), TypeOf[Collider]()},
+		Writes: []ComponentType{TypeOf[Sprite]()},
+	},
+	func(w *World) {
+		transforms := Query[Transform](w)
+		entityColliders := Query[Collider](w)
+		Query[Sprite](w).Each(func(e Entity, s *Sprite) {
+			if t, ok := transforms.Get(e); ok {
+				s.place(t.world)
+			} else if c, ok := entityColliders.Get(e); ok {
+				s.ScreenCenter = camera.WorldCoordsToScreenCoords(c.CenterCoords)
+			}
+		})
+	},
+)
+
+// Stretches by the scale and flips when it's negative
+func (s *Sprite) place(world WorldTransform) {
+	s.ScreenCenter = camera.WorldCoordsToScreenCoords(world.Position)
+	// screen rotation is clockwise
+	s.Rotation = -world.Rotation
+	s.Tex.DimX = s.baseDimX * float32(math.Abs(float64(world.Scale.X)))
+	s.Tex.DimY = s.baseDimY * float32(math.Abs(float64(world.Scale.Y)))
+	s.FlipX = world.Scale.X < 0
+	s.FlipY = world.Scale.Y < 0
+}
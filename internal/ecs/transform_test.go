@@ -0,0 +1,271 @@
+package ecs
+
+import (
+	"math"
+	"testing"
+
+	"github.com/PatrickKoch07/game-proj/internal/colliders"
+)
+
+const testTolerance float64 = 0.0001
+
+func closeTo(got float32, want float32) bool {
+	return math.Abs(float64(got-want)) <= testTolerance
+}
+
+func sameWorldTransform(got WorldTransform, want WorldTransform) bool {
+	return closeTo(got.Position.X, want.Position.X) && closeTo(got.Position.Y, want.Position.Y) &&
+		closeTo(got.Rotation, want.Rotation) &&
+		closeTo(got.Scale.X, want.Scale.X) && closeTo(got.Scale.Y, want.Scale.Y)
+}
+
+func coords(x float32, y float32) colliders.WorldCoords {
+	return colliders.WorldCoords{X: x, Y: y}
+}
+
+func TestApply(t *testing.T) {
+	halfTurn := float32(math.Pi / 2.0)
+	one := coords(1, 1)
+
+	tests := []struct {
+		name   string
+		parent WorldTransform
+		local  Transform
+		want   WorldTransform
+	}{
+		{
+			name:   "root",
+			parent: noParent,
+			local:  Transform{Position: coords(3, 4), Rotation: 0.5, Scale: coords(2, 1)},
+			want:   WorldTransform{Position: coords(3, 4), Rotation: 0.5, Scale: coords(2, 1)},
+		},
+		{
+			name:   "moved",
+			parent: WorldTransform{Position: coords(10, -5), Scale: one},
+			local:  Transform{Position: coords(1, 2), Scale: one},
+			want:   WorldTransform{Position: coords(11, -3), Scale: one},
+		},
+		{
+			name:   "turned",
+			parent: WorldTransform{Position: coords(10, 0), Rotation: halfTurn, Scale: one},
+			local:  Transform{Position: coords(1, 0), Rotation: 0.25, Scale: one},
+			want:   WorldTransform{Position: coords(10, 1), Rotation: halfTurn + 0.25, Scale: one},
+		},
+		{
+			name:   "scaled",
+			parent: WorldTransform{Scale: coords(2, 3)},
+			local:  Transform{Position: coords(1, 1), Scale: coords(0.5, 2)},
+			want:   WorldTransform{Position: coords(2, 3), Scale: coords(1, 6)},
+		},
+		{
+			name:   "mirrored",
+			parent: WorldTransform{Position: coords(5, 5), Scale: coords(-1, 1)},
+			local:  Transform{Position: coords(2, 1), Rotation: 0.5, Scale: one},
+			want:   WorldTransform{Position: coords(3, 6), Rotation: -0.5, Scale: coords(-1, 1)},
+		},
+		{
+			name:   "mirrored and turned",
+			parent: WorldTransform{Rotation: halfTurn, Scale: coords(-1, 1)},
+			local:  Transform{Position: coords(1, 0), Rotation: 0.25, Scale: one},
+			want: WorldTransform{
+				Position: coords(0, -1), Rotation: halfTurn - 0.25, Scale: coords(-1, 1),
+			},
+		},
+		{
+			name:   "mirrored child",
+			parent: WorldTransform{Position: coords(5, 5), Scale: one},
+			local:  Transform{Position: coords(2, 1), Rotation: 0.5, Scale: coords(-1, 1)},
+			want:   WorldTransform{Position: coords(7, 6), Rotation: 0.5, Scale: coords(-1, 1)},
+		},
+		{
+			// mirrored both ways is a half turn, so it turns the normal way
+			name:   "mirrored twice",
+			parent: WorldTransform{Scale: coords(-1, -1)},
+			local:  Transform{Position: coords(1, 2), Rotation: 0.5, Scale: one},
+			want:   WorldTransform{Position: coords(-1, -2), Rotation: 0.5, Scale: coords(-1, -1)},
+		},
+	}
+
+	for _, test := range tests {
+		got := test.parent.apply(&test.local)
+		if !sameWorldTransform(got, test.want) {
+			t.Errorf("%v: got %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+// an entity to spawn, with the index of its parent (-1 for none)
+type testEntity struct {
+	position colliders.WorldCoords
+	rotation float32
+	scale    colliders.WorldCoords
+	parent   int
+}
+
+func TestWorldTransforms(t *testing.T) {
+	one := coords(1, 1)
+	mirrored := coords(-1, 1)
+
+	tests := []struct {
+		name     string
+		entities []testEntity
+		want     []WorldTransform
+	}{
+		{
+			name: "chain",
+			entities: []testEntity{
+				{position: coords(10, 0), scale: one, parent: -1},
+				{position: coords(1, 0), scale: one, parent: 0},
+				{position: coords(0, 2), scale: one, parent: 1},
+			},
+			want: []WorldTransform{
+				{Position: coords(10, 0), Scale: one},
+				{Position: coords(11, 0), Scale: one},
+				{Position: coords(11, 2), Scale: one},
+			},
+		},
+		{
+			// children stored before their parents still go after them
+			name: "children first",
+			entities: []testEntity{
+				{position: coords(0, 2), scale: one, parent: 1},
+				{position: coords(1, 0), scale: one, parent: 2},
+				{position: coords(10, 0), scale: one, parent: -1},
+			},
+			want: []WorldTransform{
+				{Position: coords(11, 2), Scale: one},
+				{Position: coords(11, 0), Scale: one},
+				{Position: coords(10, 0), Scale: one},
+			},
+		},
+		{
+			name: "mirrored chain",
+			entities: []testEntity{
+				{scale: mirrored, parent: -1},
+				{position: coords(3, 0), rotation: 0.5, scale: one, parent: 0},
+				{position: coords(1, 0), scale: one, parent: 1},
+			},
+			want: []WorldTransform{
+				{Scale: mirrored},
+				{Position: coords(-3, 0), Rotation: -0.5, Scale: mirrored},
+				{
+					Position: coords(-3-float32(math.Cos(0.5)), float32(math.Sin(0.5))),
+					Rotation: -0.5,
+					Scale:    mirrored,
+				},
+			},
+		},
+		{
+			name: "parent without a transform",
+			entities: []testEntity{
+				// the extra entity spawned at the end has no transform
+				{position: coords(1, 2), scale: one, parent: 1},
+			},
+			want: []WorldTransform{
+				{Position: coords(1, 2), Scale: one},
+			},
+		},
+		{
+			name: "own parent",
+			entities: []testEntity{
+				{position: coords(1, 2), scale: one, parent: 0},
+			},
+			want: []WorldTransform{
+				{Position: coords(1, 2), Scale: one},
+			},
+		},
+		{
+			// cut where it comes back around: the first one updated is the other's child
+			name: "parent loop",
+			entities: []testEntity{
+				{position: coords(1, 0), scale: one, parent: 2},
+				{position: coords(0, 5), scale: one, parent: 0},
+				{position: coords(0, 1), scale: one, parent: 1},
+			},
+			want: []WorldTransform{
+				{Position: coords(1, 6), Scale: one},
+				{Position: coords(0, 5), Scale: one},
+				{Position: coords(0, 6), Scale: one},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := NewWorld()
+			spawned := make([]Entity, len(test.entities)+1)
+			for i := range spawned {
+				spawned[i] = w.Spawn()
+			}
+			for i, spec := range test.entities {
+				var parent Entity
+				if spec.parent >= 0 {
+					parent = spawned[spec.parent]
+				}
+				transform := NewTransform(spec.position, parent)
+				transform.Rotation = spec.rotation
+				transform.Scale = spec.scale
+				if err := Add(w, spawned[i], transform); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			w.Update()
+			for i, want := range test.want {
+				transform, _ := Get[Transform](w, spawned[i])
+				if got := transform.World(); !sameWorldTransform(got, want) {
+					t.Errorf("entity %v: got %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDestroyChildren(t *testing.T) {
+	tests := []struct {
+		name string
+		// parent index of each entity (-1 for none)
+		parents   []int
+		destroy   int
+		wantAlive []bool
+	}{
+		{
+			name:      "chain",
+			parents:   []int{-1, 0, 1, -1},
+			destroy:   1,
+			wantAlive: []bool{true, false, false, true},
+		},
+		{
+			name:      "parent loop",
+			parents:   []int{2, 0, 1, -1},
+			destroy:   1,
+			wantAlive: []bool{false, false, false, true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := NewWorld()
+			spawned := make([]Entity, len(test.parents))
+			for i := range spawned {
+				spawned[i] = w.Spawn()
+			}
+			for i, parentIndex := range test.parents {
+				var parent Entity
+				if parentIndex >= 0 {
+					parent = spawned[parentIndex]
+				}
+				if err := Add(w, spawned[i], NewTransform(coords(0, 0), parent)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			w.Destroy(spawned[test.destroy])
+			for i, wantAlive := range test.wantAlive {
+				if w.IsAlive(spawned[i]) != wantAlive {
+					t.Errorf("entity %v alive %v, want %v", i, w.IsAlive(spawned[i]), wantAlive)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package ecs
+
+// Entities, components and systems. An entity is only an id, what it is comes from the components
+// added to it (a sprite, a collider, a script, ...), and systems update every entity with the
+// components they care about. Each component type is stored in its own dense array (see
+// Storage), so systems go through them in order instead of jumping between game objects.
+//
+// A world is a game object, so a scene owns the entities in the worlds added to it: make a world,
+// spawn into it, then add it with scenes.InitOnScene (or any of the others). Killing the world
+// (ex. switching scenes) removes every entity along with their components.
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/PatrickKoch07/game-proj/internal/audio"
+	"github.com/PatrickKoch07/game-proj/internal/inputs"
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+	"github.com/PatrickKoch07/game-proj/internal/scenes"
+	"github.com/PatrickKoch07/game-proj/internal/sprites"
+)
+
+// 0 is never an entity, so it can be used for none
+type Entity uint64
+
+type World struct {
+	nextEntity Entity
+	alive      map[Entity]struct{}
+	storages   map[ComponentType]storage
+	systems    []System
+	// destroys and other changes asked for while updating, done once the systems are
+	later []func(*World)
+	// key actions for Input components, handed out at the start of the next update
+	keyActions    []inputs.KeyAction
+	inputListener inputs.InputListener
+	// what colliders point to as their parent
+	gameObject scenes.GameObject
+	// counts updates, so world transforms are only worked out once per update
+	frame uint64
+	// set under mu, so Later knows if it can run right away
+	updating atomic.Bool
+	dead     atomic.Bool
+	mu       sync.Mutex
+	// held for a whole update, and by anything Later runs right away. Storages aren't locked, so
+	// this is what keeps other goroutines (ex. a scene being killed) out of them mid update.
+	updateMu sync.Mutex
+}
+
+// Comes with the built in systems (see components.go and transform.go), in the order they run:
+// root transforms following their colliders, scripts, world transforms, then colliders, emitters
+// and sprites going where their transforms are. Systems added later run after.
+func NewWorld() *World {
+	w := &World{
+		alive:    make(map[Entity]struct{}),
+		storages: make(map[ComponentType]storage),
+	}
+	w.inputListener = inputs.InputListener(w)
+	w.gameObject = scenes.GameObject(w)
+	w.AddSystem(colliderToTransformSystem)
+	w.AddSystem(scriptSystem)
+	w.AddSystem(transformSystem)
+	w.AddSystem(transformToColliderSystem)
+	w.AddSystem(emitterSystem)
+	w.AddSystem(placeSpriteSystem)
+	return w
+}
+
+// thread safe by locking
+func (w *World) Spawn() Entity {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.nextEntity++
+	w.alive[w.nextEntity] = struct{}{}
+	return w.nextEntity
+}
+
+// Removes the entity and all its components, along with its children (see Transform). While the
+// world is updating (ex. from a script) this waits until the systems are done, so none of them see
+// half an entity.
+// thread safe by locking
+func (w *World) Destroy(e Entity) {
+	w.Later(func(w *World) { w.destroyWithChildren(e) })
+}
+
+func (w *World) destroyWithChildren(e Entity) {
+	for _, child := range w.descendants(e) {
+		w.destroy(child)
+	}
+	w.destroy(e)
+}
+
+func (w *World) destroy(e Entity) {
+	w.mu.Lock()
+	_, ok := w.alive[e]
+	delete(w.alive, e)
+	storages := make([]storage, 0, len(w.storages))
+	for _, s := range w.storages {
+		storages = append(storages, s)
+	}
+	w.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	for _, s := range storages {
+		s.remove(w, e)
+	}
+}
+
+// thread safe by locking
+func (w *World) IsAlive(e Entity) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, ok := w.alive[e]
+	return ok
+}
+
+// Runs f after the systems are done with the current update, or right away if the world isn't
+// updating. For adding and removing components from systems that aren't scripts. Called from
+// another goroutine mid update, f waits for the update to finish.
+// thread safe by locking
+func (w *World) Later(f func(*World)) {
+	w.mu.Lock()
+	if w.updating.Load() {
+		w.later = append(w.later, f)
+		w.mu.Unlock()
+		return
+	}
+	w.mu.Unlock()
+
+	w.updateMu.Lock()
+	defer w.updateMu.Unlock()
+	w.startUpdating()
+	f(w)
+	w.finishUpdating()
+}
+
+// Systems run in the order they're added, see System for which run at the same time
+// should only be called in the main thread
+func (w *World) AddSystem(system System) {
+	w.systems = append(w.systems, system)
+}
+
+func (w *World) InitInstance() ([]scenes.GameObject, []*sprites.Sprite, []audio.Player, bool) {
+	var Sprites []*sprites.Sprite
+	Query[Sprite](w).Each(func(e Entity, s *Sprite) {
+		Sprites = append(Sprites, s.Sprite)
+	})
+	Query[Emitter](w).Each(func(e Entity, em *Emitter) {
+		Sprites = append(Sprites, em.sprites...)
+	})
+	var AudioPlayers []audio.Player
+	Query[Audio](w).Each(func(e Entity, a *Audio) {
+		AudioPlayers = append(AudioPlayers, a.Player)
+	})
+	return []scenes.GameObject{w}, Sprites, AudioPlayers, true
+}
+
+// Hands out the key actions from since the last update, then runs the systems
+// should only be called in the main thread
+func (w *World) Update() {
+	w.updateMu.Lock()
+	defer w.updateMu.Unlock()
+
+	w.mu.Lock()
+	keyActions := w.keyActions
+	w.keyActions = nil
+	w.mu.Unlock()
+
+	w.frame++
+	w.startUpdating()
+	deliverKeyActions(w, keyActions)
+	runSystems(w, w.systems)
+	w.finishUpdating()
+}
+
+// should be called with updateMu locked
+func (w *World) startUpdating() {
+	w.mu.Lock()
+	w.updating.Store(true)
+	w.mu.Unlock()
+}
+
+// Runs the later funcs. Still updating meanwhile, so anything they put off runs in the next round
+// should be called with updateMu locked
+func (w *World) finishUpdating() {
+	for {
+		w.mu.Lock()
+		later := w.later
+		w.later = nil
+		if len(later) == 0 {
+			w.updating.Store(false)
+			w.mu.Unlock()
+			return
+		}
+		w.mu.Unlock()
+		for _, f := range later {
+			f(w)
+		}
+	}
+}
+
+func (w *World) ShouldSkipUpdate() bool {
+	return false
+}
+
+// Scenes kill their game objects on other goroutines, so this waits for any update (see Later)
+// thread safe by locking
+func (w *World) Kill() {
+	w.dead.Store(true)
+	w.Later(func(w *World) {
+		w.mu.Lock()
+		entities := make([]Entity, 0, len(w.alive))
+		for e := range w.alive {
+			entities = append(entities, e)
+		}
+		w.mu.Unlock()
+
+		slices.Sort(entities)
+		for _, e := range entities {
+			w.destroyWithChildren(e)
+		}
+		logger.LOG.Debug().Msgf("Killed world with %v entities", len(entities))
+	})
+}
+
+func (w *World) IsDead() bool {
+	return w.dead.Load()
+}
+
+// Key actions are kept for the next update, so Input components are only ever called from the
+// main thread, one at a time
+// thread safe by locking
+func (w *World) OnKeyAction(ka inputs.KeyAction) {
+	if w.dead.Load() {
+		return
+	}
+	w.mu.Lock()
+	w.keyActions = append(w.keyActions, ka)
+	w.mu.Unlock()
+}
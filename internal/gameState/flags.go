@@ -11,7 +11,4 @@ const (
 	LoadingScene Flag = iota
 	TitleScene   Flag = iota
 	WorldScene   Flag = iota
-
-	EnvironmentCollider Flag = iota
-	AllColliders        Flag = iota
 )
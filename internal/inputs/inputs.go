@@ -1,14 +1,18 @@
 package inputs
 
 // Package level state held by private singleton initiated at program start.
-// The main thread will use a function to call notify every frame.
-// During a frame, on any thread, objects can subscribe or unsubscribe. (todo)
+// The main thread will use a function to call notify every frame, which calls the listeners of
+// every key action since the last frame, in order, on the main thread. Game code can instead poll
+// the keys as of that call (see IsDown), from any thread.
+// During a frame, on any thread, objects can subscribe or unsubscribe.
 
 import (
 	"container/list"
 	"errors"
 	"reflect"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"weak"
 
 	"github.com/go-gl/glfw/v3.3/glfw"
@@ -21,20 +25,36 @@ type KeyAction struct {
 	Action Action
 }
 
+// Called on the main thread, from Notify
 type InputListener interface {
 	OnKeyAction(KeyAction)
 }
 
 type inputManager struct {
+	// only touched by the main thread, game code should use the snapshot (see IsDown)
 	keyStates      map[Key]KeyState
 	keyActionQueue []KeyAction
 	keyListeners   map[Key]*list.List
-	mu             sync.Mutex
+	// typed characters and mouse wheel movement since they were last taken. Only touched on the
+	// main thread (glfw callbacks and TakeTyped/TakeScroll)
+	typed   []rune
+	scrollY float32
+	// Optional. Listeners it returns false for aren't notified
+	listenerFilter func(InputListener) bool
+	// listeners that got each key's press, so only they get its release (main thread only)
+	pressedBy map[Key][]weak.Pointer[InputListener]
+	// the keys as of the last Notify, replaced whole every frame (see snapshot.go)
+	snapshot atomic.Pointer[inputSnapshot]
+	mu       sync.Mutex
 }
 
-// 10 seems like a large number for every frame's worth of inputs
+// 10 seems like a large number for every frame's worth of inputs, but the queue grows past it
+// (ex. fast typing with a gamepad's buttons on top)
 const inputManagerQueueSize int = 10
 
+// past this many in a frame something is wrong, so any more are dropped
+const maxQueuedKeyActions int = 256
+
 var inputManagerObj *inputManager
 var once sync.Once
 
@@ -45,22 +65,13 @@ func initInputManager() {
 	inputManagerObj.keyActionQueue = make([]KeyAction, 0, inputManagerQueueSize)
 
 	inputManagerObj.keyStates = make(map[Key]KeyState)
-	inputManagerObj.keyStates[KeyW] = Inactive
-	inputManagerObj.keyStates[KeyA] = Inactive
-	inputManagerObj.keyStates[KeyS] = Inactive
-	inputManagerObj.keyStates[KeyD] = Inactive
-	inputManagerObj.keyStates[KeyEscape] = Inactive
-	inputManagerObj.keyStates[LMB] = Inactive
-	inputManagerObj.keyStates[RMB] = Inactive
-
 	inputManagerObj.keyListeners = make(map[Key]*list.List)
-	inputManagerObj.keyListeners[KeyW] = list.New()
-	inputManagerObj.keyListeners[KeyA] = list.New()
-	inputManagerObj.keyListeners[KeyS] = list.New()
-	inputManagerObj.keyListeners[KeyD] = list.New()
-	inputManagerObj.keyListeners[KeyEscape] = list.New()
-	inputManagerObj.keyListeners[LMB] = list.New()
-	inputManagerObj.keyListeners[RMB] = list.New()
+	inputManagerObj.pressedBy = make(map[Key][]weak.Pointer[InputListener])
+	inputManagerObj.snapshot.Store(newInputSnapshot())
+	for _, key := range knownKeys {
+		inputManagerObj.keyStates[key] = Inactive
+		inputManagerObj.keyListeners[key] = list.New()
+	}
 }
 
 func GetInputManager() *inputManager {
@@ -68,11 +79,24 @@ func GetInputManager() *inputManager {
 	return inputManagerObj
 }
 
-// Gets the state of a key. Should** (see Notify function) be thread safe since all inputs dealt
-// with at once during dedicated main thread time.
+// Gets the state of a key as of the last Notify, false for keys that aren't known.
+// thread safe
 func (k *inputManager) GetKeyState(key Key) (KeyState, bool) {
-	value, ok := k.keyStates[key]
-	return value, ok
+	if !slices.Contains(knownKeys, key) {
+		return Inactive, false
+	}
+	if k.IsDown(key) {
+		return Pressed, true
+	}
+	return Inactive, true
+}
+
+// Listeners filter returns false for miss key presses, ex. ones in scenes under a menu (see
+// scenes.GetsInput). Called on the main thread, once per listener per press. Releases go to the
+// listeners that got the press, so no listener sees a key stuck down.
+// (should be) run in the main thread only, at program start
+func (k *inputManager) SetListenerFilter(filter func(InputListener) bool) {
+	k.listenerFilter = filter
 }
 
 // locks to be thread safe
@@ -176,58 +200,86 @@ func (k *inputManager) Unsubscribe(key Key, w weak.Pointer[InputListener]) error
 	return errors.New("no listener to be removed")
 }
 
+// Listeners are called one at a time, in the order their keys were pressed, so nothing else in
+// the main loop runs during them. Also takes this frame's snapshot (see IsDown).
 // (should be) run in the main thread only
 func (k *inputManager) Notify() {
-	var wg sync.WaitGroup
+	frame := k.snapshot.Load().next()
 	// for all Actions in input queue
 	for ka, ok := k.dirtyPop(); ok; ka, ok = k.dirtyPop() {
-		listenerQueue, ok := k.keyListeners[ka.Key]
-		if !ok {
-			continue
-		}
-
+		frame.apply(ka)
 		// notify all listeners of that key
-		for listElem := listenerQueue.Front(); listElem != nil; {
-			// if we encounter nil valued elem, we delete. So should store next here.
-			nextListElem := listElem.Next()
-
-			switch listener := listElem.Value.(type) {
-			case nil:
-				logger.LOG.Debug().Msgf("(Key: %v) Removed nil listener", ka.Key)
-				k.keyListeners[ka.Key].Remove(listElem)
-			case weak.Pointer[InputListener]:
-				strongListener := listener.Value()
-				if strongListener == nil {
-					logger.LOG.Debug().Msgf("(Key: %v) Removed nil listener", ka.Key)
-					k.keyListeners[ka.Key].Remove(listElem)
-				} else {
-					// logger.LOG.Debug().Msgf(
-					// 	"(Key: %v) Input Manager notifying: %v",
-					// 	ka.Key,
-					// 	strongListener,
-					// )
-
-					wg.Add(1)
-					go func() { defer wg.Done(); (*strongListener).OnKeyAction(ka) }()
-				}
-			default:
-				logger.LOG.Fatal().Msgf(
-					"(Key: %v) Found listener not a weakptr to InputListener: %v (%v)",
-					ka.Key,
-					listener,
-					reflect.TypeOf(listener).Name(),
-				)
+		for _, listener := range k.listenersOf(ka.Key) {
+			strongListener := listener.Value()
+			if strongListener != nil && k.shouldNotify(ka, listener, *strongListener) {
+				(*strongListener).OnKeyAction(ka)
 			}
-
-			listElem = nextListElem
+		}
+		if ka.Action == Release {
+			delete(k.pressedBy, ka.Key)
 		}
 	}
-	wg.Wait()
+	k.snapshot.Store(frame)
 
 	// because dirty pop
 	k.keyActionQueue = make([]KeyAction, 0, inputManagerQueueSize)
 }
 
+// The key's listeners, dropping any that were GC'd. Copied, so listeners can subscribe and
+// unsubscribe while being notified.
+// locks to be thread safe
+func (k *inputManager) listenersOf(key Key) []weak.Pointer[InputListener] {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	listenerQueue, ok := k.keyListeners[key]
+	if !ok {
+		return nil
+	}
+	listeners := make([]weak.Pointer[InputListener], 0, listenerQueue.Len())
+	for listElem := listenerQueue.Front(); listElem != nil; {
+		// if we encounter nil valued elem, we delete. So should store next here.
+		nextListElem := listElem.Next()
+
+		switch listener := listElem.Value.(type) {
+		case nil:
+			logger.LOG.Debug().Msgf("(Key: %v) Removed nil listener", key)
+			listenerQueue.Remove(listElem)
+		case weak.Pointer[InputListener]:
+			if listener.Value() == nil {
+				logger.LOG.Debug().Msgf("(Key: %v) Removed nil listener", key)
+				listenerQueue.Remove(listElem)
+			} else {
+				listeners = append(listeners, listener)
+			}
+		default:
+			logger.LOG.Fatal().Msgf(
+				"(Key: %v) Found listener not a weakptr to InputListener: %v (%v)",
+				key,
+				listener,
+				reflect.TypeOf(listener).Name(),
+			)
+		}
+
+		listElem = nextListElem
+	}
+	return listeners
+}
+
+// Releases only go to listeners that got the press (see SetListenerFilter)
+// (should be) run in the main thread only
+func (k *inputManager) shouldNotify(
+	ka KeyAction, w weak.Pointer[InputListener], listener InputListener) bool {
+	if ka.Action == Release {
+		return slices.Contains(k.pressedBy[ka.Key], w)
+	}
+	if k.listenerFilter != nil && !k.listenerFilter(listener) {
+		return false
+	}
+	k.pressedBy[ka.Key] = append(k.pressedBy[ka.Key], w)
+	return true
+}
+
 func InputKeysCallback(
 	w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
 	// Throw away repeat press case
@@ -237,7 +289,7 @@ func InputKeysCallback(
 
 	err := GetInputManager().push(KeyAction{Key: Key(key), Action: Action(action)})
 	if err != nil {
-		logger.LOG.Fatal().Err(err).Msg("Error in glfw to input queue.")
+		logger.LOG.Warn().Err(err).Msg("Dropped key action.")
 	}
 }
 
@@ -250,16 +302,78 @@ func InputMouseCallback(
 
 	err := GetInputManager().push(KeyAction{Key: Key(MouseButtonToKey(button)), Action: Action(action)})
 	if err != nil {
-		logger.LOG.Fatal().Err(err).Msg("Error in glfw to input queue.")
+		logger.LOG.Warn().Err(err).Msg("Dropped mouse action.")
 	}
 }
 
+// Text typed since the last call, as characters (so shift, keyboard layouts etc. are already
+// applied). Keys are still sent to their listeners as usual.
+// (should be) run in the main thread only
+func (k *inputManager) TakeTyped() []rune {
+	typed := k.typed
+	k.typed = nil
+	return typed
+}
+
+// How far the mouse wheel moved since the last call. Positive is away from the user (scroll up).
+// (should be) run in the main thread only
+func (k *inputManager) TakeScroll() float32 {
+	scrollY := k.scrollY
+	k.scrollY = 0.0
+	return scrollY
+}
+
+// gamepad buttons sent as key actions
+var gamepadButtons = []glfw.GamepadButton{
+	glfw.ButtonA, glfw.ButtonB, glfw.ButtonStart,
+	glfw.ButtonDpadUp, glfw.ButtonDpadDown, glfw.ButtonDpadLeft, glfw.ButtonDpadRight,
+}
+
+// glfw has no gamepad callbacks, so the first connected gamepad's buttons are polled and any
+// changes queued as key actions, the same way the keyboard callbacks do.
+// (should be) run in the main thread only, after glfw.PollEvents and before Notify
+func (k *inputManager) PollGamepad() {
+	state := firstGamepadState()
+	for _, button := range gamepadButtons {
+		key := Key(GamepadButtonToKey(button))
+		down := state != nil && state.Buttons[button] == glfw.Press
+		if down == (k.keyStates[key] == Pressed) {
+			continue
+		}
+		action := Release
+		if down {
+			action = Press
+		}
+		err := k.push(KeyAction{Key: key, Action: action})
+		if err != nil {
+			logger.LOG.Warn().Err(err).Msg("Dropped gamepad action.")
+		}
+	}
+}
+
+func firstGamepadState() *glfw.GamepadState {
+	for joystick := glfw.Joystick1; joystick <= glfw.JoystickLast; joystick++ {
+		if joystick.Present() && joystick.IsGamepad() {
+			return joystick.GetGamepadState()
+		}
+	}
+	return nil
+}
+
+func InputCharCallback(w *glfw.Window, char rune) {
+	GetInputManager().typed = append(GetInputManager().typed, char)
+}
+
+func InputScrollCallback(w *glfw.Window, xoff float64, yoff float64) {
+	GetInputManager().scrollY += float32(yoff)
+}
+
 func (k *inputManager) push(ka KeyAction) error {
 	// logger.LOG.Debug().Msgf("KeyPressQueue push() appended: %v", ka)
-	k.keyActionQueue = append(k.keyActionQueue, ka)
-	if len(k.keyActionQueue) == cap(k.keyActionQueue) {
+	if len(k.keyActionQueue) >= maxQueuedKeyActions {
 		return errors.New("unexpectedly high number of inputs queued")
 	}
+	k.keyActionQueue = append(k.keyActionQueue, ka)
 	return nil
 }
 
@@ -19,10 +19,34 @@ const (
 	KeyS      Key = Key(glfw.KeyS)
 	KeyD      Key = Key(glfw.KeyD)
 	KeyEscape Key = Key(glfw.KeyEscape)
-	LMB       Key = Key(glfw.MouseButton1*-1 - 2)
-	RMB       Key = Key(glfw.MouseButton2*-1 - 2)
+	KeyF3     Key = Key(glfw.KeyF3)
+	// for typing into text inputs (the characters themselves come from TakeTyped)
+	KeyBackspace Key = Key(glfw.KeyBackspace)
+	KeyEnter     Key = Key(glfw.KeyEnter)
+	KeyUp        Key = Key(glfw.KeyUp)
+	KeyDown      Key = Key(glfw.KeyDown)
+	KeyLeft      Key = Key(glfw.KeyLeft)
+	KeyRight     Key = Key(glfw.KeyRight)
+	LMB          Key = Key(glfw.MouseButton1*-1 - 2)
+	RMB          Key = Key(glfw.MouseButton2*-1 - 2)
+	// buttons of the first connected gamepad (see PollGamepad)
+	GamepadA         Key = Key(glfw.ButtonA*-1 - 100)
+	GamepadB         Key = Key(glfw.ButtonB*-1 - 100)
+	GamepadStart     Key = Key(glfw.ButtonStart*-1 - 100)
+	GamepadDpadUp    Key = Key(glfw.ButtonDpadUp*-1 - 100)
+	GamepadDpadDown  Key = Key(glfw.ButtonDpadDown*-1 - 100)
+	GamepadDpadLeft  Key = Key(glfw.ButtonDpadLeft*-1 - 100)
+	GamepadDpadRight Key = Key(glfw.ButtonDpadRight*-1 - 100)
 )
 
+// every key that can be subscribed to
+var knownKeys = []Key{
+	KeyW, KeyA, KeyS, KeyD, KeyEscape, KeyF3, KeyBackspace, KeyEnter,
+	KeyUp, KeyDown, KeyLeft, KeyRight, LMB, RMB,
+	GamepadA, GamepadB, GamepadStart,
+	GamepadDpadUp, GamepadDpadDown, GamepadDpadLeft, GamepadDpadRight,
+}
+
 type Action glfw.Action
 
 const (
@@ -33,3 +57,8 @@ const (
 func MouseButtonToKey(m glfw.MouseButton) int {
 	return (-1 * int(m)) - 2
 }
+
+// well past the mouse buttons
+func GamepadButtonToKey(b glfw.GamepadButton) int {
+	return (-1 * int(b)) - 100
+}
@@ -0,0 +1,62 @@
+package inputs
+
+// The keys as of the last Notify, for game code that polls instead of listening (ex. movement
+// read every update). Every frame gets a new snapshot that is never changed after, so reading it
+// from any thread, during any part of the frame, gives the same answer.
+//
+// Unlike listeners, polling isn't filtered, so game objects under a menu that blocks input should
+// check scenes.GetsInput themselves.
+
+type inputSnapshot struct {
+	down         map[Key]bool
+	justPressed  map[Key]bool
+	justReleased map[Key]bool
+}
+
+func newInputSnapshot() *inputSnapshot {
+	return &inputSnapshot{
+		down:         make(map[Key]bool),
+		justPressed:  make(map[Key]bool),
+		justReleased: make(map[Key]bool),
+	}
+}
+
+// The next frame's snapshot starts with the keys still down
+func (s *inputSnapshot) next() *inputSnapshot {
+	next := newInputSnapshot()
+	for key := range s.down {
+		next.down[key] = true
+	}
+	return next
+}
+
+// should only be called on a snapshot that isn't stored yet
+func (s *inputSnapshot) apply(ka KeyAction) {
+	switch ka.Action {
+	case Press:
+		s.down[ka.Key] = true
+		s.justPressed[ka.Key] = true
+	case Release:
+		delete(s.down, ka.Key)
+		s.justReleased[ka.Key] = true
+	}
+}
+
+// If the key is held down, as of the last Notify
+// thread safe
+func (k *inputManager) IsDown(key Key) bool {
+	return k.snapshot.Load().down[key]
+}
+
+// If the key went down since the Notify before last. Still true if it also came back up in that
+// time (a quick tap), while IsDown isn't.
+// thread safe
+func (k *inputManager) JustPressed(key Key) bool {
+	return k.snapshot.Load().justPressed[key]
+}
+
+// If the key came up since the Notify before last
+// thread safe
+func (k *inputManager) JustReleased(key Key) bool {
+	return k.snapshot.Load().justReleased[key]
+}
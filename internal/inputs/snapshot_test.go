@@ -0,0 +1,119 @@
+package inputs
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	press := func(key Key) KeyAction { return KeyAction{Key: key, Action: Press} }
+	release := func(key Key) KeyAction { return KeyAction{Key: key, Action: Release} }
+
+	tests := []struct {
+		name string
+		// key actions pushed before each Notify
+		frames           [][]KeyAction
+		wantDown         []Key
+		wantJustPressed  []Key
+		wantJustReleased []Key
+	}{
+		{
+			name:            "press",
+			frames:          [][]KeyAction{{press(KeyW)}},
+			wantDown:        []Key{KeyW},
+			wantJustPressed: []Key{KeyW},
+		},
+		{
+			name:     "held",
+			frames:   [][]KeyAction{{press(KeyW)}, {}},
+			wantDown: []Key{KeyW},
+		},
+		{
+			name:             "release",
+			frames:           [][]KeyAction{{press(KeyW)}, {release(KeyW)}},
+			wantJustReleased: []Key{KeyW},
+		},
+		{
+			name:             "tap",
+			frames:           [][]KeyAction{{press(KeyW), release(KeyW)}},
+			wantJustPressed:  []Key{KeyW},
+			wantJustReleased: []Key{KeyW},
+		},
+		{
+			name:             "released and pressed again",
+			frames:           [][]KeyAction{{press(KeyW)}, {release(KeyW), press(KeyW)}},
+			wantDown:         []Key{KeyW},
+			wantJustPressed:  []Key{KeyW},
+			wantJustReleased: []Key{KeyW},
+		},
+		{
+			// a second press without a release in between isn't a new press
+			name:     "pressed twice",
+			frames:   [][]KeyAction{{press(KeyW)}, {press(KeyW)}},
+			wantDown: []Key{KeyW},
+		},
+		{
+			name:             "two keys",
+			frames:           [][]KeyAction{{press(KeyW), press(KeyA)}, {release(KeyA)}},
+			wantDown:         []Key{KeyW},
+			wantJustReleased: []Key{KeyA},
+		},
+		{
+			name:   "tap a frame ago",
+			frames: [][]KeyAction{{press(KeyW), release(KeyW)}, {}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// a fresh manager, so no keys are down from other tests
+			initInputManager()
+			k := GetInputManager()
+			for _, frame := range test.frames {
+				for _, ka := range frame {
+					if err := k.push(ka); err != nil {
+						t.Fatal(err)
+					}
+				}
+				k.Notify()
+			}
+
+			for _, key := range []Key{KeyW, KeyA} {
+				wantDown := slices.Contains(test.wantDown, key)
+				wantJustPressed := slices.Contains(test.wantJustPressed, key)
+				wantJustReleased := slices.Contains(test.wantJustReleased, key)
+				if got := k.IsDown(key); got != wantDown {
+					t.Errorf("IsDown(%v) = %v, want %v", key, got, wantDown)
+				}
+				if got := k.JustPressed(key); got != wantJustPressed {
+					t.Errorf("JustPressed(%v) = %v, want %v", key, got, wantJustPressed)
+				}
+				if got := k.JustReleased(key); got != wantJustReleased {
+					t.Errorf("JustReleased(%v) = %v, want %v", key, got, wantJustReleased)
+				}
+			}
+		})
+	}
+}
+
+// Snapshots already handed out never change, the next Notify makes a new one
+func TestSnapshotUnchanged(t *testing.T) {
+	initInputManager()
+	k := GetInputManager()
+	if err := k.push(KeyAction{Key: KeyW, Action: Press}); err != nil {
+		t.Fatal(err)
+	}
+	k.Notify()
+	old := k.snapshot.Load()
+
+	if err := k.push(KeyAction{Key: KeyW, Action: Release}); err != nil {
+		t.Fatal(err)
+	}
+	k.Notify()
+	if !old.down[KeyW] || !old.justPressed[KeyW] || old.justReleased[KeyW] {
+		t.Errorf("old snapshot changed: %+v", old)
+	}
+	if k.IsDown(KeyW) {
+		t.Error("W is still down")
+	}
+}
@@ -1,95 +1,64 @@
 package gameCharacters
 
 import (
-	"weak"
+	"math"
 
-	"github.com/PatrickKoch07/game-proj/internal/audio"
-	"github.com/PatrickKoch07/game-proj/internal/camera"
-	"github.com/PatrickKoch07/game-proj/internal/characters"
 	"github.com/PatrickKoch07/game-proj/internal/colliders"
-	"github.com/PatrickKoch07/game-proj/internal/gameState"
+	"github.com/PatrickKoch07/game-proj/internal/ecs"
 	"github.com/PatrickKoch07/game-proj/internal/logger"
-	"github.com/PatrickKoch07/game-proj/internal/scenes"
+	"github.com/PatrickKoch07/game-proj/internal/particles"
 	"github.com/PatrickKoch07/game-proj/internal/sprites"
 )
 
-// dummy struct to test colliders
-type Block struct {
-	*characters.CollidableObject
-}
-
-func (b *Block) InitInstance() ([]scenes.GameObject, []*sprites.Sprite, []audio.Player, bool) {
-	var Sprites []*sprites.Sprite = make([]*sprites.Sprite, 1)
-	var AudioPlayers []audio.Player = make([]audio.Player, 0)
-	var GameObjects []scenes.GameObject = make([]scenes.GameObject, 1)
-	GameObjects[0] = b
-	creationSuccess := true
+// dummy wall to test colliders, flashes and sparks when something hits it
+// should only be called in the main thread
+func SpawnBlock(w *ecs.World, center colliders.WorldCoords) (ecs.Entity, bool) {
+	// sparks when something hits the block
+	sparks := particles.NewEmitter(particles.EmitterParams{
+		Position:         center,
+		SpawnArea:        96.0,
+		Lifetime:         0.5,
+		LifetimeVariance: 0.2,
+		Direction:        math.Pi / 2.0,
+		Spread:           math.Pi,
+		Speed:            250.0,
+		SpeedVariance:    100.0,
+		Gravity:          colliders.WorldCoords{X: 0.0, Y: -600.0},
+		Spin:             6.0,
+		StartSize:        10.0,
+		EndSize:          2.0,
+		StartColor:       sprites.Color{R: 1.0, G: 0.9, B: 0.4, A: 1.0},
+		EndColor:         sprites.Color{R: 1.0, G: 0.3, B: 0.1, A: 0.0},
+	})
 
-	collider := colliders.Collider2D{
-		Tags:             make([]gameState.Flag, 1),
-		CenterCoords:     colliders.WorldCoords{X: 0.0, Y: 0.0},
-		Width:            128.0,
-		Height:           128.0,
-		OnEnterCollision: func(c *colliders.Collider2D) { logger.LOG.Debug().Msg("block collided") },
-		OnExitCollision:  func(c *colliders.Collider2D) { logger.LOG.Debug().Msg("block stopped colliding") },
-		Block:            make([]gameState.Flag, 0),
-		Ignore:           make([]gameState.Flag, 0),
-		Parent:           &GameObjects[0],
-	}
-	collider.Tags[0] = gameState.EnvironmentCollider
-	colliderSprites := make([]*sprites.Sprite, 1)
-	colliderSprite, err := sprites.CreateSprite(
-		&sprites.SpriteInitParams{
-			ShaderRelPaths: sprites.ShaderFiles{
-				VertexPath:   "alphaTextureShader.vs",
-				FragmentPath: "alphaTextureShader.fs",
-			},
-			TextureRelPath: "ui/button.png",
-			TextureCoords:  sprites.TexCoordOneSpritePerImg,
-			ScreenCenter:   camera.WorldCoordsToScreenCoords(collider.CenterCoords),
-			SpriteCenter:   sprites.SpriteCoords{X: 0.5, Y: 0.5},
-			StretchX:       1.0,
-			StretchY:       1.0,
+	// flashes while something touches the block
+	material := sprites.NewMaterial()
+	material.SetColor("flashColor", sprites.Color{R: 1.0, G: 0.2, B: 0.2, A: 1.0})
+	e, _, ok := spawnBox(w, boxParams{
+		layers:         colliders.EnvironmentLayer,
+		center:         center,
+		size:           128.0,
+		fragmentShader: "flashShader.fs",
+		material:       material,
+		onEnter: func(c *colliders.Collider2D) {
+			logger.LOG.Debug().Msg("block collided")
+			material.SetFloat("flashAmount", 0.6)
+			sparks.Burst(24)
 		},
-	)
-	colliderSprite.Tex.DimX = collider.Width
-	colliderSprite.Tex.DimY = collider.Height
-	colliderSprites[0] = colliderSprite
-	b.CollidableObject = characters.CreateCollidableObject(&collider, colliderSprites)
-	if err != nil {
-		creationSuccess = false
-	}
-
-	Sprites[0] = b.Sprites[0]
-
-	for _, sprite := range b.Sprites {
-		if sprite == nil {
-			logger.LOG.Error().Msg(
-				"nil sprite encountered adding Block sprites to draw queue (init)",
-			)
-		} else {
-			sprites.GetDrawQueue().AddToQueue(weak.Make(sprite))
-		}
+		onExit: func(c *colliders.Collider2D) {
+			logger.LOG.Debug().Msg("block stopped colliding")
+			material.Unset("flashAmount")
+		},
+	})
+	if !ok {
+		return e, false
 	}
 
-	// GameObjects[0] = b
-	return GameObjects, Sprites, AudioPlayers, creationSuccess
-}
-
-func (b *Block) Update() {
-	return
-}
-
-func (b *Block) ShouldSkipUpdate() bool {
-	return true
-}
-
-func (b *Block) Kill() {
-	for _, sprite := range b.Sprites {
-		sprites.GetDrawQueue().RemoveFromQueue(weak.Make(sprite))
+	err := ecs.Add(w, e, ecs.Emitter{Emitter: sparks})
+	if err != nil {
+		logger.LOG.Error().Err(err).Msg("Failed to spawn block")
+		w.Destroy(e)
+		return e, false
 	}
-}
-
-func (b *Block) IsDead() bool {
-	return false
+	return e, true
 }
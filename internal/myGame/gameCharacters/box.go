@@ -0,0 +1,96 @@
+package gameCharacters
+
+import (
+	"github.com/PatrickKoch07/game-proj/internal/camera"
+	"github.com/PatrickKoch07/game-proj/internal/colliders"
+	"github.com/PatrickKoch07/game-proj/internal/ecs"
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+	"github.com/PatrickKoch07/game-proj/internal/sprites"
+)
+
+// What every character here starts as: a square collider with a sprite stretched over it
+type boxParams struct {
+	layers colliders.Layer
+	center colliders.WorldCoords
+	size   float32
+	// default is alphaTextureShader.fs
+	fragmentShader string
+	// default is no tint
+	tint     sprites.Color
+	material *sprites.Material
+	onEnter  func(*colliders.Collider2D)
+	onExit   func(*colliders.Collider2D)
+}
+
+// Spawns an entity with a Transform, a Collider and a Sprite. The entity is destroyed again if any
+// of them fail.
+// should only be called in the main thread
+func spawnBox(w *ecs.World, params boxParams) (ecs.Entity, *colliders.Collider2D, bool) {
+	if params.onEnter == nil {
+		params.onEnter = func(c *colliders.Collider2D) {}
+	}
+	if params.onExit == nil {
+		params.onExit = func(c *colliders.Collider2D) {}
+	}
+
+	collider := &colliders.Collider2D{
+		Layers:           params.layers,
+		CenterCoords:     params.center,
+		Width:            params.size,
+		Height:           params.size,
+		OnEnterCollision: params.onEnter,
+		OnExitCollision:  params.onExit,
+	}
+	sprite, ok := newBoxSprite(params.size, params.size, params)
+	if !ok {
+		return 0, nil, false
+	}
+
+	e := w.Spawn()
+	err := ecs.Add(w, e, ecs.NewTransform(params.center, 0))
+	if err == nil {
+		err = ecs.Add(w, e, ecs.Collider{Collider2D: collider})
+	}
+	if err == nil {
+		err = ecs.Add(w, e, ecs.Sprite{Sprite: sprite})
+	}
+	if err != nil {
+		logger.LOG.Error().Err(err).Msg("Failed to spawn box")
+		w.Destroy(e)
+		return 0, nil, false
+	}
+	return e, collider, true
+}
+
+// The box's look, without a collider (ex. for things a character carries)
+// should only be called in the main thread
+func newBoxSprite(width float32, height float32, params boxParams) (*sprites.Sprite, bool) {
+	if params.fragmentShader == "" {
+		params.fragmentShader = "alphaTextureShader.fs"
+	}
+	sprite, err := sprites.CreateSprite(
+		&sprites.SpriteInitParams{
+			ShaderRelPaths: sprites.ShaderFiles{
+				VertexPath:   "alphaTextureShader.vs",
+				FragmentPath: params.fragmentShader,
+			},
+			TextureRelPath: "ui/button.png",
+			TextureCoords:  sprites.TexCoordOneSpritePerImg,
+			ScreenCenter:   camera.WorldCoordsToScreenCoords(params.center),
+			SpriteCenter:   sprites.SpriteCoords{X: 0.5, Y: 0.5},
+			StretchX:       1.0,
+			StretchY:       1.0,
+			Material:       params.material,
+		},
+	)
+	if err != nil {
+		logger.LOG.Error().Err(err).Msg("Failed to make box sprite")
+		return nil, false
+	}
+	sprite.Tex.DimX = width
+	sprite.Tex.DimY = height
+	if params.tint != (sprites.Color{}) {
+		sprite.Tint = params.tint
+	}
+	return sprite, true
+}
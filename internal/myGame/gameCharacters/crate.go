@@ -0,0 +1,35 @@
+package gameCharacters
+
+import (
+	"github.com/PatrickKoch07/game-proj/internal/colliders"
+	"github.com/PatrickKoch07/game-proj/internal/ecs"
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+	"github.com/PatrickKoch07/game-proj/internal/physics"
+	"github.com/PatrickKoch07/game-proj/internal/sprites"
+)
+
+// pushable box to test bodies
+// should only be called in the main thread
+func SpawnCrate(w *ecs.World, center colliders.WorldCoords) (ecs.Entity, bool) {
+	e, collider, ok := spawnBox(w, boxParams{
+		layers: PropLayer,
+		center: center,
+		size:   48.0,
+		tint:   sprites.Color{R: 0.7, G: 0.5, B: 0.3, A: 1.0},
+	})
+	if !ok {
+		return e, false
+	}
+
+	// slides to a stop once it isn't pushed anymore
+	body := physics.NewBody(
+		collider, physics.BodyParams{Mass: 2.0, Damping: 4.0, Bounciness: 0.2},
+	)
+	err := ecs.Add(w, e, ecs.Body{Body: body})
+	if err != nil {
+		logger.LOG.Error().Err(err).Msg("Failed to spawn crate")
+		w.Destroy(e)
+		return e, false
+	}
+	return e, true
+}
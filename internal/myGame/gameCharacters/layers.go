@@ -0,0 +1,18 @@
+package gameCharacters
+
+import (
+	"github.com/PatrickKoch07/game-proj/internal/colliders"
+)
+
+const (
+	PlayerLayer colliders.Layer = colliders.FirstGameLayer << iota
+	// things that get pushed around, ex. crates
+	PropLayer
+)
+
+// What blocks what. Everything else just overlaps. New character types only need a layer and a
+// line here.
+var CollisionRules = []colliders.CollisionRule{
+	{A: PlayerLayer, B: colliders.EnvironmentLayer | PropLayer, Response: colliders.Block},
+	{A: PropLayer, B: colliders.EnvironmentLayer | PropLayer, Response: colliders.Block},
+}
@@ -1,111 +1,85 @@
 package gameCharacters
 
 import (
-	"weak"
+	"errors"
 
-	"github.com/PatrickKoch07/game-proj/internal/audio"
-	"github.com/PatrickKoch07/game-proj/internal/camera"
-	"github.com/PatrickKoch07/game-proj/internal/characters"
 	"github.com/PatrickKoch07/game-proj/internal/colliders"
-	"github.com/PatrickKoch07/game-proj/internal/gameState"
+	"github.com/PatrickKoch07/game-proj/internal/ecs"
 	"github.com/PatrickKoch07/game-proj/internal/inputs"
 	"github.com/PatrickKoch07/game-proj/internal/logger"
-	"github.com/PatrickKoch07/game-proj/internal/scenes"
+	"github.com/PatrickKoch07/game-proj/internal/physics"
 	"github.com/PatrickKoch07/game-proj/internal/sprites"
 )
 
-// dummy struct to test colliders
+// dummy component to test colliders. The player entity is a box with a body, moved by WASD, and
+// wears a hat (a child entity, to test transforms)
 type Player struct {
-	*characters.CollidableObject
-	inputListener inputs.InputListener
-	// temp
-	death         bool
 	baseVelocityX float32
 	baseVelocityY float32
-	movespeed     float32
+	// world units per second
+	movespeed float32
 }
 
-func (p *Player) InitInstance() ([]scenes.GameObject, []*sprites.Sprite, []audio.Player, bool) {
-	var Sprites []*sprites.Sprite = make([]*sprites.Sprite, 0, 1)
-	var AudioPlayers []audio.Player = make([]audio.Player, 0)
-	var GameObjects []scenes.GameObject = make([]scenes.GameObject, 0, 1)
-	GameObjects = append(GameObjects, p)
-
-	p.death = false
-	p.movespeed = 5.0
-	creationSuccess := true
-
-	collider := colliders.Collider2D{
-		Tags:             make([]gameState.Flag, 0),
-		CenterCoords:     colliders.WorldCoords{X: 300.0, Y: 300.0},
-		Width:            32.0,
-		Height:           32.0,
-		OnEnterCollision: func(c *colliders.Collider2D) { logger.LOG.Debug().Msg("player collided") },
-		OnExitCollision:  func(c *colliders.Collider2D) { logger.LOG.Debug().Msg("player stopped colliding") },
-		Block:            make([]gameState.Flag, 1),
-		Ignore:           make([]gameState.Flag, 0),
-		Parent:           &GameObjects[0],
-	}
-	collider.Block[0] = gameState.EnvironmentCollider
-	colliderSprites := make([]*sprites.Sprite, 1)
-	colliderSprite, err := sprites.CreateSprite(
-		&sprites.SpriteInitParams{
-			ShaderRelPaths: sprites.ShaderFiles{
-				VertexPath:   "alphaTextureShader.vs",
-				FragmentPath: "alphaTextureShader.fs",
-			},
-			TextureRelPath: "ui/button.png",
-			TextureCoords:  sprites.TexCoordOneSpritePerImg,
-			ScreenCenter:   camera.WorldCoordsToScreenCoords(collider.CenterCoords),
-			SpriteCenter:   sprites.SpriteCoords{X: 0.5, Y: 0.5},
-			StretchX:       1.0,
-			StretchY:       1.0,
-		},
-	)
-	colliderSprite.Tex.DimX = collider.Width
-	colliderSprite.Tex.DimY = collider.Height
-	colliderSprites[0] = colliderSprite
-	p.CollidableObject = characters.CreateCollidableObject(&collider, colliderSprites)
-	if err != nil {
-		creationSuccess = false
+// should only be called in the main thread
+func SpawnPlayer(w *ecs.World, center colliders.WorldCoords) (ecs.Entity, bool) {
+	e, collider, ok := spawnBox(w, boxParams{
+		layers:  PlayerLayer,
+		center:  center,
+		size:    32.0,
+		onEnter: func(c *colliders.Collider2D) { logger.LOG.Debug().Msg("player collided") },
+		onExit:  func(c *colliders.Collider2D) { logger.LOG.Debug().Msg("player stopped colliding") },
+	})
+	if !ok {
+		return e, false
 	}
-	// sprite.Tex.DimX = 100
-	// sprite.Tex.DimY = 100
-	Sprites = append(Sprites, p.CollidableObject.Sprites...)
 
-	p.inputListener = inputs.InputListener(p)
-	ok := inputs.GetInputManager().Subscribe(inputs.KeyW, weak.Make(&p.inputListener))
-	if !ok {
-		creationSuccess = false
+	// top down, so no gravity. Heavy enough to push crates around
+	body := physics.NewBody(collider, physics.BodyParams{Mass: 1.0})
+	err := ecs.Add(w, e, ecs.Body{Body: body})
+	if err == nil {
+		err = ecs.Add(w, e, Player{movespeed: 300.0})
 	}
-	ok = inputs.GetInputManager().Subscribe(inputs.KeyA, weak.Make(&p.inputListener))
-	if !ok {
-		creationSuccess = false
+	if err == nil {
+		err = ecs.Add(w, e, ecs.Input{
+			Keys:        []inputs.Key{inputs.KeyW, inputs.KeyA, inputs.KeyS, inputs.KeyD},
+			OnKeyAction: onPlayerKeyAction,
+		})
 	}
-	ok = inputs.GetInputManager().Subscribe(inputs.KeyS, weak.Make(&p.inputListener))
-	if !ok {
-		creationSuccess = false
+	if err == nil {
+		err = ecs.Add(w, e, ecs.Script{Update: updatePlayer})
 	}
-	ok = inputs.GetInputManager().Subscribe(inputs.KeyD, weak.Make(&p.inputListener))
-	if !ok {
-		creationSuccess = false
+	if err == nil {
+		err = spawnHat(w, e)
 	}
-
-	for _, sprite := range Sprites {
-		if sprite == nil {
-			logger.LOG.Error().Msg(
-				"nil sprite encountered adding Player sprites to draw queue (init)",
-			)
-		} else {
-			sprites.GetDrawQueue().AddToQueue(weak.Make(sprite))
-		}
+	if err != nil {
+		logger.LOG.Error().Err(err).Msg("Failed to spawn player")
+		w.Destroy(e)
+		return e, false
 	}
+	return e, true
+}
 
-	// GameObjects = append(GameObjects, p)
-	return GameObjects, Sprites, AudioPlayers, creationSuccess
+// sits on top of the player, a little forward, so it shows the player turning around
+func spawnHat(w *ecs.World, player ecs.Entity) error {
+	sprite, ok := newBoxSprite(
+		20.0, 10.0, boxParams{tint: sprites.Color{R: 0.8, G: 0.1, B: 0.1, A: 1.0}},
+	)
+	if !ok {
+		return errors.New("failed to make hat sprite")
+	}
+	hat := w.Spawn()
+	err := ecs.Add(w, hat, ecs.NewTransform(colliders.WorldCoords{X: 4.0, Y: 21.0}, player))
+	if err == nil {
+		err = ecs.Add(w, hat, ecs.Sprite{Sprite: sprite})
+	}
+	return err
 }
 
-func (p *Player) OnKeyAction(ka inputs.KeyAction) {
+func onPlayerKeyAction(w *ecs.World, e ecs.Entity, ka inputs.KeyAction) {
+	p, ok := ecs.Get[Player](w, e)
+	if !ok {
+		return
+	}
 	if ka.Key == inputs.KeyW {
 		if ka.Action != inputs.Release {
 			p.baseVelocityY += p.movespeed
@@ -136,25 +110,21 @@ func (p *Player) OnKeyAction(ka inputs.KeyAction) {
 	}
 }
 
-func (p *Player) Update() {
-	p.MoveCharacter(
-		colliders.WorldCoords{
-			X: p.Collider.CenterCoords.X + p.baseVelocityX,
-			Y: p.Collider.CenterCoords.Y + p.baseVelocityY,
-		},
-	)
-}
-
-func (p *Player) ShouldSkipUpdate() bool {
-	return false
-}
-
-func (p *Player) Kill() {
-	for _, sprite := range p.Sprites {
-		sprites.GetDrawQueue().RemoveFromQueue(weak.Make(sprite))
+func updatePlayer(w *ecs.World, e ecs.Entity) {
+	p, ok := ecs.Get[Player](w, e)
+	if !ok {
+		return
+	}
+	// face the way we're moving, keep facing that way when stopped. Mirroring the transform turns
+	// the hat around too
+	if t, ok := ecs.Get[ecs.Transform](w, e); ok {
+		if p.baseVelocityX < 0 {
+			t.Scale.X = -1.0
+		} else if p.baseVelocityX > 0 {
+			t.Scale.X = 1.0
+		}
+	}
+	if body, ok := ecs.Get[ecs.Body](w, e); ok {
+		body.SetVelocity(physics.Vec2{X: p.baseVelocityX, Y: p.baseVelocityY})
 	}
-}
-
-func (p *Player) IsDead() bool {
-	return p.death
 }
@@ -4,6 +4,7 @@ import (
 	"weak"
 
 	"github.com/PatrickKoch07/game-proj/internal/logger"
+	"github.com/PatrickKoch07/game-proj/internal/myGame/gameUi"
 	"github.com/PatrickKoch07/game-proj/internal/scenes"
 	"github.com/PatrickKoch07/game-proj/internal/sprites"
 )
@@ -34,5 +35,12 @@ func createLoadingScene() *scenes.Scene {
 	}
 	sprites.GetDrawQueue().AddToQueue(weak.Make(loadingScene.Sprites[0]))
 
+	loadingBar := &gameUi.LoadingBar{
+		TopLeft: sprites.ScreenCoords{X: 384, Y: 832},
+		Width:   512,
+		Height:  32,
+	}
+	scenes.InitOnScene(loadingScene, scenes.GameObject(loadingBar))
+
 	return loadingScene
 }
@@ -1,6 +1,7 @@
 package gameScenes
 
 import (
+	"github.com/PatrickKoch07/game-proj/internal/assets"
 	"github.com/PatrickKoch07/game-proj/internal/gameState"
 	"github.com/PatrickKoch07/game-proj/internal/scenes"
 )
@@ -12,3 +13,16 @@ func GetSceneMap() map[gameState.Flag]func() *scenes.Scene {
 	sceneMap[gameState.WorldScene] = createWorldScene
 	return sceneMap
 }
+
+// What each scene loads in the background while the loading scene is up
+func GetSceneAssets() map[gameState.Flag]assets.Manifest {
+	sceneAssets := make(map[gameState.Flag]assets.Manifest)
+	sceneAssets[gameState.TitleScene] = assets.Manifest{
+		Textures: []string{"ui/button.png", "ui/font.png"},
+		Audio:    []string{"assets/audio/buttonPress.mp3"},
+	}
+	sceneAssets[gameState.WorldScene] = assets.Manifest{
+		Textures: []string{"ui/button.png", "tiles/overworld.png"},
+	}
+	return sceneAssets
+}
@@ -1,18 +1,28 @@
 package gameScenes
 
 import (
+	"github.com/PatrickKoch07/game-proj/internal/colliders"
+	"github.com/PatrickKoch07/game-proj/internal/ecs"
 	"github.com/PatrickKoch07/game-proj/internal/logger"
 	"github.com/PatrickKoch07/game-proj/internal/myGame/gameCharacters"
+	"github.com/PatrickKoch07/game-proj/internal/myGame/gameUi"
 	"github.com/PatrickKoch07/game-proj/internal/scenes"
+	"github.com/PatrickKoch07/game-proj/internal/tilemap"
 )
 
 func createWorldScene() *scenes.Scene {
 	logger.LOG.Info().Msg("Making worldScene")
 
 	worldScene := new(scenes.Scene)
-	player := new(gameCharacters.Player)
-	scenes.InitOnGlobalScene(scenes.GameObject(player))
-	block := new(gameCharacters.Block)
-	scenes.InitOnGlobalScene(scenes.GameObject(block))
+	// 40x30 tiles of 32px, centered on the world origin
+	worldMap := tilemap.NewTilemap("world.tmx", colliders.WorldCoords{X: -640.0, Y: 480.0})
+	scenes.InitOnScene(worldScene, scenes.GameObject(worldMap))
+	world := ecs.NewWorld()
+	gameCharacters.SpawnPlayer(world, colliders.WorldCoords{X: 300.0, Y: 300.0})
+	gameCharacters.SpawnBlock(world, colliders.WorldCoords{X: 0.0, Y: 0.0})
+	gameCharacters.SpawnCrate(world, colliders.WorldCoords{X: -200.0, Y: 150.0})
+	scenes.InitOnScene(worldScene, scenes.GameObject(world))
+	pauseMenu := new(gameUi.PauseMenu)
+	scenes.InitOnScene(worldScene, scenes.GameObject(pauseMenu))
 	return worldScene
 }
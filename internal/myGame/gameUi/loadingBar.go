@@ -0,0 +1,96 @@
+package gameUi
+
+import (
+	"weak"
+
+	"github.com/PatrickKoch07/game-proj/internal/audio"
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+	"github.com/PatrickKoch07/game-proj/internal/primitives"
+	"github.com/PatrickKoch07/game-proj/internal/scenes"
+	"github.com/PatrickKoch07/game-proj/internal/sprites"
+)
+
+// how much of the gap between the shown and actual progress is closed every frame
+const loadingBarSmoothing float32 = 0.2
+
+var loadingBarFillColor = sprites.Color{R: 0.85, G: 0.85, B: 0.85, A: 1.0}
+
+// Progress bar that follows the global scene's loading progress
+type LoadingBar struct {
+	// top left corner of the bar
+	TopLeft sprites.ScreenCoords
+	Width   float32
+	Height  float32
+
+	outline       *sprites.Sprite
+	shownProgress float32
+}
+
+func (lb *LoadingBar) ShouldSkipUpdate() bool {
+	return lb.outline == nil
+}
+
+func (lb *LoadingBar) Update() {
+	progress := scenes.GetGlobalScene().LoadingProgress()
+	lb.shownProgress += (progress - lb.shownProgress) * loadingBarSmoothing
+
+	// drawn over the outline sprite (screen space primitives are in front of UI sprites)
+	fillWidth := lb.Width * lb.shownProgress
+	primitives.FillRect(
+		primitives.Point{X: lb.TopLeft.X + fillWidth/2.0, Y: lb.TopLeft.Y + lb.Height/2.0},
+		fillWidth,
+		lb.Height,
+		primitives.Style{Color: loadingBarFillColor, Space: primitives.ScreenSpace},
+	)
+}
+
+func (lb *LoadingBar) IsDead() bool {
+	return false
+}
+
+func (lb *LoadingBar) Kill() {
+	if lb.outline != nil {
+		sprites.GetDrawQueue().RemoveFromQueue(weak.Make(lb.outline))
+	}
+}
+
+func (lb *LoadingBar) InitInstance() ([]scenes.GameObject, []*sprites.Sprite, []audio.Player, bool) {
+	var Sprites []*sprites.Sprite = make([]*sprites.Sprite, 0, 1)
+	creationSuccess := true
+
+	outline, err := createBarSprite("ui/emptybox.png", lb.TopLeft)
+	if err != nil {
+		creationSuccess = false
+		logger.LOG.Error().Err(err).Msg("")
+	} else {
+		lb.outline = outline
+		lb.outline.Tex.DimX = lb.Width
+		lb.outline.Tex.DimY = lb.Height
+		Sprites = append(Sprites, lb.outline)
+	}
+
+	for _, sprite := range Sprites {
+		sprites.GetDrawQueue().AddToQueue(weak.Make(sprite))
+	}
+
+	return []scenes.GameObject{lb}, Sprites, []audio.Player{}, creationSuccess
+}
+
+func createBarSprite(textureRelPath string, topLeft sprites.ScreenCoords) (*sprites.Sprite, error) {
+	return sprites.CreateSprite(
+		&sprites.SpriteInitParams{
+			ShaderRelPaths: sprites.ShaderFiles{
+				VertexPath:   "uiShader.vs",
+				FragmentPath: "alphaTextureShader.fs",
+			},
+			TextureRelPath: textureRelPath,
+			TextureCoords:  sprites.TexCoordOneSpritePerImg,
+			// the sprite's origin is its top left
+			ScreenCenter: topLeft,
+			SpriteCenter: sprites.SpriteCoords{X: 0.0, Y: 0.0},
+			// Tex Dim is set manually to the bar size anyway
+			StretchX: 1.0,
+			StretchY: 1.0,
+		},
+	)
+}
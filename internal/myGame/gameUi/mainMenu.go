@@ -1,21 +1,25 @@
 package gameUi
 
 import (
-	"weak"
+	"time"
 
 	"github.com/PatrickKoch07/game-proj/internal/audio"
 	"github.com/PatrickKoch07/game-proj/internal/gameState"
 	"github.com/PatrickKoch07/game-proj/internal/logger"
 	"github.com/PatrickKoch07/game-proj/internal/scenes"
 	"github.com/PatrickKoch07/game-proj/internal/sprites"
-	"github.com/PatrickKoch07/game-proj/internal/text"
+	"github.com/PatrickKoch07/game-proj/internal/ui"
 )
 
-type MainMenu struct {
-	playButton *button
-	exitButton *button
+// the world wipes in over the menu (and the loading screen after it)
+var playTransition = scenes.Transition{
+	Effect:    scenes.Wipe,
+	Duration:  600 * time.Millisecond,
+	Direction: scenes.LeftToRight,
 }
 
+type MainMenu struct{}
+
 func (mm MainMenu) ShouldSkipUpdate() bool {
 	return true
 }
@@ -28,76 +32,26 @@ func (mm MainMenu) IsDead() bool {
 	return false
 }
 
-func (mm MainMenu) Kill() {
-	mm.playButton.UnsubInput()
-	mm.exitButton.UnsubInput()
-}
+// the menu's root is in the scene itself, so it gets killed with it
+func (mm MainMenu) Kill() {}
 
 func (mm MainMenu) InitInstance() ([]scenes.GameObject, []*sprites.Sprite, []audio.Player, bool) {
-	var Sprites []*sprites.Sprite = make([]*sprites.Sprite, 2)
-	var AudioPlayers []audio.Player = make([]audio.Player, 2)
-	creationSuccess := true
-
-	textPlaySprites, ok := text.TextToSprites("play", sprites.ScreenCoords{X: 584, Y: 656}, 1.75, 20)
-	if !ok {
-		creationSuccess = false
-		logger.LOG.Error().Msg(
-			"failed to make some of the play button text sprites. trying to display anyway",
-		)
-	}
-	Sprites = append(Sprites, textPlaySprites...)
-	playButton, err := CreateButton(64, 256, 512, 640)
-	if err != nil {
-		creationSuccess = false
-		logger.LOG.Error().Err(err).Msg("")
-	} else {
-		mm.playButton = playButton
-		mm.playButton.OnPress = switchScene
-		Sprites[0] = mm.playButton.Sprite
-		AudioPlayers[0] = mm.playButton.AudioPlayer
-	}
-
-	textExitSprites, ok := text.TextToSprites("exit", sprites.ScreenCoords{X: 584, Y: 772}, 1.75, 20)
-	if !ok {
-		creationSuccess = false
-		logger.LOG.Error().Msg(
-			"failed to make some of the exit button text sprites. trying to display anyway",
-		)
-	}
-	Sprites = append(Sprites, textExitSprites...)
-	exitButton, err := CreateButton(64, 256, 512, 756)
-	if err != nil {
-		creationSuccess = false
-		logger.LOG.Error().Err(err).Msg("")
-	} else {
-		mm.exitButton = exitButton
-		mm.exitButton.OnPress = almostExitGame
-		mm.exitButton.OnRelease = exitGame
-		Sprites[1] = exitButton.Sprite
-		AudioPlayers[0] = mm.exitButton.AudioPlayer
-	}
+	playButton := ui.NewButton("play", 256, 64)
+	playButton.OnClick = switchScene
 
-	textSprites, ok := text.TextToSprites("Welcome to the Game!", sprites.ScreenCoords{X: 192, Y: 384}, 3, 20)
-	if !ok {
-		creationSuccess = false
-		logger.LOG.Error().Msg("failed to make some of the title text sprites. trying to display anyway")
-	}
-	Sprites = append(Sprites, textSprites...)
+	exitButton := ui.NewButton("exit", 256, 64)
+	exitButton.OnPress = almostExitGame
+	exitButton.OnClick = exitGame
 
-	for _, sprite := range Sprites {
-		if sprite == nil {
-			logger.LOG.Error().Msg(
-				"nil sprite encountered adding MainMenu sprites to draw queue (init)",
-			)
-		} else {
-			sprites.GetDrawQueue().AddToQueue(weak.Make(sprite))
-		}
-	}
+	buttons := ui.NewStack(ui.Vertical, 52, playButton, exitButton)
+	menu := ui.NewStack(ui.Vertical, 192, ui.NewLabel("Welcome to the Game!", 3), buttons)
+	menu.Align = ui.AlignCenter
 
-	return []scenes.GameObject{mm}, Sprites, AudioPlayers, creationSuccess
+	return ui.NewRoot(ui.NewAnchor().Add(menu, ui.Center, 0, 0)).InitInstance()
 }
 
 func switchScene() {
+	scenes.GetGlobalScene().SetNextTransition(playTransition)
 	gameState.GetCurrentGameState().SetFlagValue(gameState.NextScene, int32(gameState.WorldScene))
 	gameState.GetCurrentGameState().SetFlagValue(gameState.LoadingScene, int32(1))
 }
@@ -107,5 +61,5 @@ func exitGame() {
 }
 
 func almostExitGame() {
-	logger.LOG.Debug().Msg("This will exit if you let go over the button!!")
+	logger.LOG.Debug().Msg("This will exit if you let go over the button!! (move off it to cancel)")
 }
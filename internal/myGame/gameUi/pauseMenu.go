@@ -0,0 +1,137 @@
+package gameUi
+
+import (
+	"sync/atomic"
+	"weak"
+
+	"github.com/PatrickKoch07/game-proj/internal/audio"
+	"github.com/PatrickKoch07/game-proj/internal/inputs"
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+	"github.com/PatrickKoch07/game-proj/internal/scenes"
+	"github.com/PatrickKoch07/game-proj/internal/sprites"
+	"github.com/PatrickKoch07/game-proj/internal/ui"
+)
+
+// darkens the paused game behind the menu
+var pauseDimColor = sprites.Color{R: 0.0, G: 0.0, B: 0.0, A: 0.5}
+
+// the scene under the menu is left alone, and only the menu gets input
+var pauseSceneParams = scenes.OverlayParams{
+	BlockUpdates:       true,
+	BlockInput:         true,
+	UpdatesWhilePaused: true,
+}
+
+// Opens the pause menu (escape or gamepad start) in scenes that can be paused. The menu is pushed
+// on top of the scene, so the game picks up right where it was when the menu closes.
+type PauseMenu struct {
+	inputListener inputs.InputListener
+	open          atomic.Bool
+	dead          atomic.Bool
+}
+
+func (pm *PauseMenu) ShouldSkipUpdate() bool {
+	return true
+}
+
+func (pm *PauseMenu) Update() {}
+
+// the listener is only weakly held, so this stops it opening menus until it's GC'd
+func (pm *PauseMenu) Kill() {
+	pm.dead.Store(true)
+}
+
+func (pm *PauseMenu) IsDead() bool {
+	return pm.dead.Load()
+}
+
+func (pm *PauseMenu) InitInstance() ([]scenes.GameObject, []*sprites.Sprite, []audio.Player, bool) {
+	creationSuccess := true
+	pm.inputListener = inputs.InputListener(pm)
+	for _, key := range []inputs.Key{inputs.KeyEscape, inputs.GamepadStart} {
+		ok := inputs.GetInputManager().Subscribe(key, weak.Make(&pm.inputListener))
+		if !ok {
+			creationSuccess = false
+		}
+	}
+	return []scenes.GameObject{pm}, []*sprites.Sprite{}, []audio.Player{}, creationSuccess
+}
+
+// Only gets input while the menu is closed, as the menu blocks input to the scene under it
+func (pm *PauseMenu) OnKeyAction(ka inputs.KeyAction) {
+	if ka.Action != inputs.Press || pm.dead.Load() || !pm.open.CompareAndSwap(false, true) {
+		return
+	}
+	logger.LOG.Debug().Msg("Pausing")
+	scenes.GetGlobalScene().Pause()
+	scenes.GetGlobalScene().PushScene(pm.createPauseScene, pauseSceneParams)
+}
+
+// thread safe
+func (pm *PauseMenu) resume() {
+	if !pm.open.CompareAndSwap(true, false) {
+		return
+	}
+	logger.LOG.Debug().Msg("Resuming")
+	scenes.GetGlobalScene().PopScene()
+	scenes.GetGlobalScene().Resume()
+}
+
+// should only be called in the main thread (by the global scene)
+func (pm *PauseMenu) createPauseScene() *scenes.Scene {
+	pauseScene := new(scenes.Scene)
+
+	resumeButton := ui.NewButton("resume", 256, 64)
+	resumeButton.OnClick = pm.resume
+	exitButton := ui.NewButton("exit", 256, 64)
+	exitButton.OnClick = exitGame
+
+	menu := ui.NewPanel(
+		ui.NewStack(ui.Vertical, 32, ui.NewLabel("Paused", 3), resumeButton, exitButton),
+	)
+	menu.Padding = 48
+	dim := ui.NewPanel(ui.NewAnchor().Add(menu, ui.Center, 0, 0))
+	dim.Color = pauseDimColor
+	dim.BorderColor = sprites.Color{}
+	dim.Padding = 0
+
+	// escape (and gamepad B) are the root's cancel, start is listened to on its own
+	root := ui.NewRoot(dim)
+	root.OnCancel = pm.resume
+	scenes.InitOnScene(pauseScene, root)
+	scenes.InitOnScene(pauseScene, &pauseCloser{menu: pm})
+	return pauseScene
+}
+
+// Closes the pause menu on gamepad start. Lives in the pause scene, so it still gets input
+type pauseCloser struct {
+	menu          *PauseMenu
+	inputListener inputs.InputListener
+	dead          atomic.Bool
+}
+
+func (pc *pauseCloser) ShouldSkipUpdate() bool {
+	return true
+}
+
+func (pc *pauseCloser) Update() {}
+
+func (pc *pauseCloser) Kill() {
+	pc.dead.Store(true)
+}
+
+func (pc *pauseCloser) IsDead() bool {
+	return pc.dead.Load()
+}
+
+func (pc *pauseCloser) InitInstance() ([]scenes.GameObject, []*sprites.Sprite, []audio.Player, bool) {
+	pc.inputListener = inputs.InputListener(pc)
+	ok := inputs.GetInputManager().Subscribe(inputs.GamepadStart, weak.Make(&pc.inputListener))
+	return []scenes.GameObject{pc}, []*sprites.Sprite{}, []audio.Player{}, ok
+}
+
+func (pc *pauseCloser) OnKeyAction(ka inputs.KeyAction) {
+	if ka.Action == inputs.Press && !pc.dead.Load() {
+		pc.menu.resume()
+	}
+}
@@ -0,0 +1,281 @@
+package particles
+
+// Short lived visuals (dust, sparks, hit effects, ...). Each emitter is a game object that
+// simulates its own particles and draws all of them with one batch sprite, so thousands of
+// particles are still a single draw queue entry and a single draw call.
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+	"weak"
+
+	"github.com/PatrickKoch07/game-proj/internal/audio"
+	"github.com/PatrickKoch07/game-proj/internal/camera"
+	"github.com/PatrickKoch07/game-proj/internal/colliders"
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+	"github.com/PatrickKoch07/game-proj/internal/scenes"
+	"github.com/PatrickKoch07/game-proj/internal/sprites"
+)
+
+const defaultTexture string = "particles/dot.png"
+const defaultMaxParticles int = 256
+
+// A long frame (ex. a scene loading) shouldn't throw particles across the screen
+const maxStep float32 = 0.1
+
+// Times are in seconds, distances in world units (pixels) and angles in radians
+type EmitterParams struct {
+	// default is a soft white dot (particles/dot.png)
+	TextureRelPath string
+	// Where particles spawn. Ignored if Follow is set
+	Position colliders.WorldCoords
+	// Optional. Called every update to get where particles spawn (ex. a game object's collider)
+	Follow func() colliders.WorldCoords
+	// Particles spawn somewhere in a SpawnArea x SpawnArea box around the position
+	SpawnArea float32
+	// Particles per second while emitting. 0 means only bursts (see Burst)
+	SpawnRate float32
+	// Oldest particles are dropped after this many. Default is 256
+	MaxParticles int
+	// Kill the emitter once it has nothing left to show and isn't emitting
+	OneShot bool
+
+	Lifetime         float32
+	LifetimeVariance float32
+	// Launch direction in world coords (0 is right, Pi/2 is up), +- Spread/2 at random
+	Direction float32
+	Spread    float32
+	Speed     float32
+	// +- at random
+	SpeedVariance float32
+	// Added to the velocity every second (ex. {Y: -400} pulls particles down)
+	Gravity colliders.WorldCoords
+	// Velocity lost per second, from 0.0 (none) to 1.0 (all of it)
+	Drag float32
+	// Radians per second, +- at random
+	Spin float32
+
+	// Interpolated over the particle's life
+	StartSize  float32
+	EndSize    float32
+	StartColor sprites.Color
+	EndColor   sprites.Color
+}
+
+type particle struct {
+	position colliders.WorldCoords
+	velocity colliders.WorldCoords
+	rotation float32
+	spin     float32
+	age      float32
+	lifetime float32
+}
+
+type Emitter struct {
+	Params EmitterParams
+
+	sprite     *sprites.Sprite
+	particles  []particle
+	instances  []sprites.BatchInstance
+	spawnDebt  float32
+	lastUpdate time.Time
+	// everything below can be changed by other game objects mid update
+	emitting     atomic.Bool
+	pendingBurst atomic.Int32
+	dead         atomic.Bool
+	mu           sync.Mutex
+}
+
+// Emitters start emitting (if they have a SpawnRate). Add them to a scene with
+// scenes.InitOnCurrentScene (or any of the others).
+func NewEmitter(params EmitterParams) *Emitter {
+	if params.TextureRelPath == "" {
+		params.TextureRelPath = defaultTexture
+	}
+	if params.MaxParticles <= 0 {
+		params.MaxParticles = defaultMaxParticles
+	}
+	e := &Emitter{Params: params}
+	e.emitting.Store(true)
+	return e
+}
+
+// Should never be called concurrently because it *COULD* use glfw/gl
+func (e *Emitter) InitInstance() ([]scenes.GameObject, []*sprites.Sprite, []audio.Player, bool) {
+	sprite, err := sprites.CreateBatchSprite(
+		&sprites.SpriteInitParams{
+			ShaderRelPaths: sprites.ShaderFiles{
+				VertexPath:   "particle.vs",
+				FragmentPath: "particle.fs",
+			},
+			TextureRelPath: e.Params.TextureRelPath,
+			TextureCoords:  sprites.TexCoordOneSpritePerImg,
+		},
+	)
+	if err != nil {
+		logger.LOG.Error().Err(err).Msg("Failed to make particle emitter sprite")
+		return nil, nil, nil, false
+	}
+	e.sprite = sprite
+	e.particles = make([]particle, 0, e.Params.MaxParticles)
+	e.instances = make([]sprites.BatchInstance, 0, e.Params.MaxParticles)
+	sprites.GetDrawQueue().AddToQueue(weak.Make(e.sprite))
+
+	return []scenes.GameObject{e}, []*sprites.Sprite{e.sprite}, []audio.Player{}, true
+}
+
+// Spawns count particles right away (on the emitter's next update).
+// thread safe
+func (e *Emitter) Burst(count int) {
+	e.pendingBurst.Add(int32(count))
+}
+
+// Turns the continuous spawning (SpawnRate) on or off. Live particles finish their life either way.
+// thread safe
+func (e *Emitter) SetEmitting(emitting bool) {
+	e.emitting.Store(emitting)
+}
+
+// thread safe by locking
+func (e *Emitter) SetPosition(position colliders.WorldCoords) {
+	e.mu.Lock()
+	e.Params.Position = position
+	e.mu.Unlock()
+}
+
+func (e *Emitter) Update() {
+	now := time.Now()
+	var dt float32
+	if !e.lastUpdate.IsZero() {
+		dt = min(float32(now.Sub(e.lastUpdate).Seconds()), maxStep)
+	}
+	dt *= scenes.GetGlobalScene().TimeScale()
+	e.lastUpdate = now
+
+	e.simulate(dt)
+	e.spawn(dt)
+
+	e.instances = e.instances[:0]
+	for _, p := range e.particles {
+		e.instances = append(e.instances, e.instanceOf(&p))
+	}
+	e.sprite.SetInstances(e.instances)
+
+	if e.Params.OneShot && len(e.particles) == 0 && e.pendingBurst.Load() == 0 &&
+		(!e.emitting.Load() || e.Params.SpawnRate == 0) {
+		e.Kill()
+	}
+}
+
+func (e *Emitter) simulate(dt float32) {
+	drag := max(1.0-e.Params.Drag*dt, 0.0)
+	alive := e.particles[:0]
+	for _, p := range e.particles {
+		p.age += dt
+		if p.age >= p.lifetime {
+			continue
+		}
+		p.velocity.X = (p.velocity.X + e.Params.Gravity.X*dt) * drag
+		p.velocity.Y = (p.velocity.Y + e.Params.Gravity.Y*dt) * drag
+		p.position.X += p.velocity.X * dt
+		p.position.Y += p.velocity.Y * dt
+		p.rotation += p.spin * dt
+		alive = append(alive, p)
+	}
+	e.particles = alive
+}
+
+func (e *Emitter) spawn(dt float32) {
+	count := int(e.pendingBurst.Swap(0))
+	if e.emitting.Load() && e.Params.SpawnRate > 0 {
+		e.spawnDebt += e.Params.SpawnRate * dt
+		count += int(e.spawnDebt)
+		e.spawnDebt -= float32(int(e.spawnDebt))
+	}
+	if count == 0 {
+		return
+	}
+
+	var origin colliders.WorldCoords
+	if e.Params.Follow != nil {
+		origin = e.Params.Follow()
+	} else {
+		e.mu.Lock()
+		origin = e.Params.Position
+		e.mu.Unlock()
+	}
+
+	for range count {
+		if len(e.particles) >= e.Params.MaxParticles {
+			// drop the oldest (they're the first ones)
+			e.particles = append(e.particles[:0], e.particles[1:]...)
+		}
+		e.particles = append(e.particles, e.newParticle(origin))
+	}
+}
+
+func (e *Emitter) newParticle(origin colliders.WorldCoords) particle {
+	angle := e.Params.Direction + randomBetween(-e.Params.Spread/2.0, e.Params.Spread/2.0)
+	speed := e.Params.Speed + randomBetween(-e.Params.SpeedVariance, e.Params.SpeedVariance)
+	lifetime := e.Params.Lifetime +
+		randomBetween(-e.Params.LifetimeVariance, e.Params.LifetimeVariance)
+	halfArea := e.Params.SpawnArea / 2.0
+	return particle{
+		position: colliders.WorldCoords{
+			X: origin.X + randomBetween(-halfArea, halfArea),
+			Y: origin.Y + randomBetween(-halfArea, halfArea),
+		},
+		velocity: colliders.WorldCoords{
+			X: speed * float32(math.Cos(float64(angle))),
+			Y: speed * float32(math.Sin(float64(angle))),
+		},
+		rotation: randomBetween(0.0, 2.0*math.Pi),
+		spin:     randomBetween(-e.Params.Spin, e.Params.Spin),
+		lifetime: max(lifetime, 0.001),
+	}
+}
+
+func (e *Emitter) instanceOf(p *particle) sprites.BatchInstance {
+	t := p.age / p.lifetime
+	size := lerp(e.Params.StartSize, e.Params.EndSize, t)
+	return sprites.BatchInstance{
+		ScreenCenter: camera.WorldCoordsToScreenCoords(p.position),
+		Width:        size,
+		Height:       size,
+		Rotation:     p.rotation,
+		Color: sprites.Color{
+			R: lerp(e.Params.StartColor.R, e.Params.EndColor.R, t),
+			G: lerp(e.Params.StartColor.G, e.Params.EndColor.G, t),
+			B: lerp(e.Params.StartColor.B, e.Params.EndColor.B, t),
+			A: lerp(e.Params.StartColor.A, e.Params.EndColor.A, t),
+		},
+	}
+}
+
+func (e *Emitter) ShouldSkipUpdate() bool {
+	return e.sprite == nil
+}
+
+func (e *Emitter) Kill() {
+	if e.dead.Swap(true) {
+		return
+	}
+	if e.sprite != nil {
+		e.sprite.Clear()
+	}
+}
+
+func (e *Emitter) IsDead() bool {
+	return e.dead.Load()
+}
+
+func lerp(from float32, to float32, t float32) float32 {
+	return from + (to-from)*t
+}
+
+func randomBetween(low float32, high float32) float32 {
+	return low + rand.Float32()*(high-low)
+}
@@ -0,0 +1,92 @@
+package physics
+
+// Optional rigid body for a Collider2D. The physics world moves the collider every fixed step from
+// the body's velocity, so a game object with a body should stop calling MoveCollider itself and
+// only read where its collider ended up (ex. to place its sprites).
+
+import (
+	"sync"
+
+	"github.com/PatrickKoch07/game-proj/internal/colliders"
+)
+
+type Vec2 struct {
+	X float32
+	Y float32
+}
+
+type BodyParams struct {
+	// Units of world gravity applied. 0 (top down games) turns gravity off for the body
+	GravityScale float32
+	// from 0.0 (ice) to 1.0. How much sliding along a blocking collider slows the body down
+	Friction float32
+	// from 0.0 (stops dead) to 1.0 (bounces back at full speed)
+	Bounciness float32
+	// Velocity lost per second, from 0.0 (none) to 1.0 (all of it). For top down games, where
+	// nothing is pressing bodies into the ground to cause friction.
+	Damping float32
+	// 0 or less makes the body immovable: it never moves and other bodies bounce off it
+	Mass float32
+}
+
+type Body struct {
+	Collider *colliders.Collider2D
+	params   BodyParams
+
+	velocity     Vec2
+	acceleration Vec2
+	// touching a blocking collider below it during the last step
+	grounded bool
+	mu       sync.Mutex
+}
+
+// The body does nothing until it is added to the world (see AddBody).
+func NewBody(collider *colliders.Collider2D, params BodyParams) *Body {
+	return &Body{Collider: collider, params: params}
+}
+
+// thread safe by locking
+func (b *Body) Velocity() Vec2 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.velocity
+}
+
+// World units per second. thread safe by locking
+func (b *Body) SetVelocity(velocity Vec2) {
+	b.mu.Lock()
+	b.velocity = velocity
+	b.mu.Unlock()
+}
+
+// Constant acceleration (ex. a thruster) on top of gravity, world units per second squared.
+// thread safe by locking
+func (b *Body) SetAcceleration(acceleration Vec2) {
+	b.mu.Lock()
+	b.acceleration = acceleration
+	b.mu.Unlock()
+}
+
+// Instant change of momentum (ex. a jump or an explosion). Does nothing to immovable bodies.
+// thread safe by locking
+func (b *Body) AddImpulse(impulse Vec2) {
+	if b.params.Mass <= 0.0 {
+		return
+	}
+	b.mu.Lock()
+	b.velocity.X += impulse.X / b.params.Mass
+	b.velocity.Y += impulse.Y / b.params.Mass
+	b.mu.Unlock()
+}
+
+// If the body was standing on something during the last step (ex. to allow jumping).
+// thread safe by locking
+func (b *Body) IsGrounded() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.grounded
+}
+
+func (b *Body) isStatic() bool {
+	return b.params.Mass <= 0.0
+}
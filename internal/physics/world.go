@@ -0,0 +1,211 @@
+package physics
+
+// Package level state held by private singleton initialized at program start.
+// Steps every body at a fixed rate, however long frames take, so jumps are the same height at any
+// framerate. Bodies move one axis at a time through SweepCollider, and when a move is blocked the
+// body bounces off (or pushes) whatever blocked it.
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/PatrickKoch07/game-proj/internal/colliders"
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+	"github.com/PatrickKoch07/game-proj/internal/scenes"
+)
+
+const fixedStep time.Duration = time.Second / 60
+
+// After a long frame (ex. a scene loading) the simulation drops time instead of catching up
+const maxStepsPerUpdate int = 5
+
+// Bounces slower than this (world units per second) stop instead, so resting bodies don't jitter
+const minBounceSpeed float32 = 20.0
+
+type world struct {
+	// in the order they were added, which is the order they are stepped in
+	bodies     []*Body
+	byCollider map[*colliders.Collider2D]*Body
+	// world units per second squared
+	gravity     Vec2
+	accumulator time.Duration
+	lastUpdate  time.Time
+	mu          sync.Mutex
+}
+
+var activeWorld *world
+var once sync.Once
+
+func initWorld() {
+	logger.LOG.Info().Msg("Creating new physics world")
+	activeWorld = new(world)
+	activeWorld.byCollider = make(map[*colliders.Collider2D]*Body)
+	activeWorld.gravity = Vec2{X: 0.0, Y: -980.0}
+}
+
+func GetWorld() *world {
+	once.Do(initWorld)
+	return activeWorld
+}
+
+// thread safe by locking
+func (w *world) AddBody(b *Body) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.byCollider[b.Collider]; ok {
+		logger.LOG.Warn().Msg("Body already in the physics world. Not adding again.")
+		return
+	}
+	w.bodies = append(w.bodies, b)
+	w.byCollider[b.Collider] = b
+}
+
+// thread safe by locking
+func (w *world) RemoveBody(b *Body) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.bodies = slices.DeleteFunc(w.bodies, func(heldBody *Body) bool { return heldBody == b })
+	delete(w.byCollider, b.Collider)
+}
+
+// thread safe by locking
+func (w *world) SetGravity(gravity Vec2) {
+	w.mu.Lock()
+	w.gravity = gravity
+	w.mu.Unlock()
+}
+
+// Runs as many fixed steps as the time since the last call needs.
+// should only be called from the main thread while no game objects are updating
+func (w *world) Update() {
+	now := time.Now()
+	if w.lastUpdate.IsZero() {
+		w.lastUpdate = now
+		return
+	}
+	// slow motion (or pausing) just lets less time build up
+	scale := scenes.GetGlobalScene().TimeScale()
+	w.accumulator += time.Duration(float32(now.Sub(w.lastUpdate)) * scale)
+	w.lastUpdate = now
+
+	steps := 0
+	for w.accumulator >= fixedStep {
+		if steps == maxStepsPerUpdate {
+			w.accumulator = 0
+			break
+		}
+		w.step(float32(fixedStep.Seconds()))
+		w.accumulator -= fixedStep
+		steps++
+	}
+}
+
+func (w *world) step(dt float32) {
+	w.mu.Lock()
+	bodies := slices.Clone(w.bodies)
+	gravity := w.gravity
+	w.mu.Unlock()
+
+	for _, b := range bodies {
+		if b.isStatic() {
+			continue
+		}
+		b.mu.Lock()
+		v := b.velocity
+		v.X += (b.acceleration.X + gravity.X*b.params.GravityScale) * dt
+		v.Y += (b.acceleration.Y + gravity.Y*b.params.GravityScale) * dt
+		damping := max(1.0-b.params.Damping*dt, 0.0)
+		v.X *= damping
+		v.Y *= damping
+		b.mu.Unlock()
+
+		w.moveAxis(b, &v, dt, true)
+		falling := v.Y < 0.0
+		grounded := w.moveAxis(b, &v, dt, false) && falling
+
+		b.mu.Lock()
+		b.velocity = v
+		b.grounded = grounded
+		b.mu.Unlock()
+	}
+}
+
+// Moves the body along one axis. Returns if the move was blocked, after changing v (and the
+// velocity of a pushed body) for the bounce.
+func (w *world) moveAxis(b *Body, v *Vec2, dt float32, horizontal bool) bool {
+	// normal is the axis we move along, tangent the other one
+	normal, tangent := &v.Y, &v.X
+	if horizontal {
+		normal, tangent = &v.X, &v.Y
+	}
+	if *normal == 0.0 {
+		return false
+	}
+
+	start := b.Collider.CenterCoords
+	target := start
+	if horizontal {
+		target.X += *normal * dt
+	} else {
+		target.Y += *normal * dt
+	}
+	_, hit, blocked := b.Collider.SweepCollider(target)
+	if !blocked {
+		return false
+	}
+
+	before := *normal
+	after := -before * b.params.Bounciness
+	w.mu.Lock()
+	other, ok := w.byCollider[hit.Collider]
+	w.mu.Unlock()
+	if ok && !other.isStatic() {
+		// push the body in the way, like a 1D collision along the normal
+		after = w.pushBody(b, other, before, horizontal)
+	}
+	if float32(math.Abs(float64(after))) < minBounceSpeed {
+		after = 0.0
+	}
+
+	// sliding along whatever we hit loses speed in proportion to how hard we hit it
+	slowdown := b.params.Friction * float32(math.Abs(float64(after-before)))
+	if *tangent > 0.0 {
+		*tangent = max(*tangent-slowdown, 0.0)
+	} else {
+		*tangent = min(*tangent+slowdown, 0.0)
+	}
+	*normal = after
+	return true
+}
+
+// Exchanges momentum between the moving body and the one it ran into. Returns the moving body's
+// new speed along the normal.
+func (w *world) pushBody(b *Body, other *Body, speed float32, horizontal bool) float32 {
+	other.mu.Lock()
+	defer other.mu.Unlock()
+
+	otherSpeed := other.velocity.Y
+	if horizontal {
+		otherSpeed = other.velocity.X
+	}
+	if (speed-otherSpeed)*speed <= 0.0 {
+		// already moving apart
+		return speed
+	}
+
+	m1 := b.params.Mass
+	m2 := other.params.Mass
+	restitution := max(b.params.Bounciness, other.params.Bounciness)
+	momentum := m1*speed + m2*otherSpeed
+	newSpeed := (momentum + m2*restitution*(otherSpeed-speed)) / (m1 + m2)
+	newOtherSpeed := (momentum + m1*restitution*(speed-otherSpeed)) / (m1 + m2)
+
+	if horizontal {
+		other.velocity.X = newOtherSpeed
+	} else {
+		other.velocity.Y = newOtherSpeed
+	}
+	return newSpeed
+}
@@ -0,0 +1,172 @@
+package primitives
+
+// Immediate mode shapes: lines, rects and circles for this frame only (call every frame to keep
+// them on screen). Everything drawn in a frame goes out in one batch after the sprites, in the
+// order it was queued. Safe to call from any game object's Update.
+//
+// World space shapes sit in front of every world sprite but behind the UI (ex. health bars),
+// screen space shapes in front of UI panels but behind text and the cursor (ex. selection boxes).
+
+import (
+	"math"
+
+	"github.com/PatrickKoch07/game-proj/internal/camera"
+	"github.com/PatrickKoch07/game-proj/internal/colliders"
+	"github.com/PatrickKoch07/game-proj/internal/sprites"
+)
+
+type Space int
+
+const (
+	// pixels, top left of the screen is 0, 0 and y goes down
+	ScreenSpace Space = iota
+	// world units, y goes up. Follows the camera
+	WorldSpace
+)
+
+const worldDepth float32 = -0.65
+const screenDepth float32 = -0.92
+
+// Either a sprites.ScreenCoords or a colliders.WorldCoords (convert with Point(coords)), depending
+// on the Style's Space
+type Point struct {
+	X float32
+	Y float32
+}
+
+type Style struct {
+	Color sprites.Color
+	// Line width for outlines. 0 is 1 pixel
+	Thickness float32
+	Space     Space
+	// Optional. 0 is the Space's usual depth (see the top of the file)
+	Depth float32
+}
+
+// thread safe by locking
+func DrawLine(from Point, to Point, style Style) {
+	sprites.DrawTriangles(lineTriangles(nil, toScreen(from, style), toScreen(to, style), style))
+}
+
+// Closed outline through the points
+// thread safe by locking
+func DrawPolygon(points []Point, style Style) {
+	if len(points) < 2 {
+		return
+	}
+	var triangles []sprites.PrimitiveVertex
+	previous := toScreen(points[len(points)-1], style)
+	for _, point := range points {
+		current := toScreen(point, style)
+		triangles = lineTriangles(triangles, previous, current, style)
+		previous = current
+	}
+	sprites.DrawTriangles(triangles)
+}
+
+// thread safe by locking
+func DrawRect(center Point, width float32, height float32, style Style) {
+	DrawPolygon(rectCorners(center, width, height), style)
+}
+
+// thread safe by locking
+func FillRect(center Point, width float32, height float32, style Style) {
+	corners := rectCorners(center, width, height)
+	sprites.DrawTriangles(fanTriangles(nil, center, corners, style))
+}
+
+// thread safe by locking
+func DrawCircle(center Point, radius float32, style Style) {
+	DrawPolygon(circlePoints(center, radius), style)
+}
+
+// thread safe by locking
+func FillCircle(center Point, radius float32, style Style) {
+	sprites.DrawTriangles(fanTriangles(nil, center, circlePoints(center, radius), style))
+}
+
+func rectCorners(center Point, width float32, height float32) []Point {
+	halfW := width / 2.0
+	halfH := height / 2.0
+	return []Point{
+		{X: center.X - halfW, Y: center.Y - halfH},
+		{X: center.X + halfW, Y: center.Y - halfH},
+		{X: center.X + halfW, Y: center.Y + halfH},
+		{X: center.X - halfW, Y: center.Y + halfH},
+	}
+}
+
+// more segments for bigger circles, so they stay round without wasting triangles on small ones
+func circlePoints(center Point, radius float32) []Point {
+	segments := min(max(int(radius/2.0), 12), 64)
+	points := make([]Point, segments)
+	for i := range segments {
+		angle := 2.0 * math.Pi * float64(i) / float64(segments)
+		points[i] = Point{
+			X: center.X + radius*float32(math.Cos(angle)),
+			Y: center.Y + radius*float32(math.Sin(angle)),
+		}
+	}
+	return points
+}
+
+// A quad from -> to, thickness wide. The ends stick out by half the thickness so corners of
+// outlines close up.
+func lineTriangles(
+	triangles []sprites.PrimitiveVertex, from sprites.ScreenCoords, to sprites.ScreenCoords, style Style,
+) []sprites.PrimitiveVertex {
+	dx := to.X - from.X
+	dy := to.Y - from.Y
+	length := float32(math.Sqrt(float64(dx*dx + dy*dy)))
+	if length == 0.0 {
+		return triangles
+	}
+	half := max(style.Thickness, 1.0) / 2.0
+	// along the line and across it, half the thickness long
+	alongX, alongY := dx/length*half, dy/length*half
+	acrossX, acrossY := -alongY, alongX
+
+	corners := [4]sprites.ScreenCoords{
+		{X: from.X - alongX + acrossX, Y: from.Y - alongY + acrossY},
+		{X: from.X - alongX - acrossX, Y: from.Y - alongY - acrossY},
+		{X: to.X + alongX - acrossX, Y: to.Y + alongY - acrossY},
+		{X: to.X + alongX + acrossX, Y: to.Y + alongY + acrossY},
+	}
+	for _, i := range [6]int{0, 1, 2, 0, 2, 3} {
+		triangles = append(triangles, vertex(corners[i], style))
+	}
+	return triangles
+}
+
+// Fills a convex shape with triangles from its center to each edge
+func fanTriangles(
+	triangles []sprites.PrimitiveVertex, center Point, points []Point, style Style,
+) []sprites.PrimitiveVertex {
+	screenCenter := toScreen(center, style)
+	previous := toScreen(points[len(points)-1], style)
+	for _, point := range points {
+		current := toScreen(point, style)
+		triangles = append(triangles,
+			vertex(screenCenter, style), vertex(previous, style), vertex(current, style),
+		)
+		previous = current
+	}
+	return triangles
+}
+
+func toScreen(point Point, style Style) sprites.ScreenCoords {
+	if style.Space == WorldSpace {
+		return camera.WorldCoordsToScreenCoords(colliders.WorldCoords(point))
+	}
+	return sprites.ScreenCoords(point)
+}
+
+func vertex(position sprites.ScreenCoords, style Style) sprites.PrimitiveVertex {
+	depth := style.Depth
+	if depth == 0.0 && style.Space == WorldSpace {
+		depth = worldDepth
+	} else if depth == 0.0 {
+		depth = screenDepth
+	}
+	return sprites.PrimitiveVertex{Position: position, Depth: depth, Color: style.Color}
+}
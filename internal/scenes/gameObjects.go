@@ -1,8 +1,6 @@
 package scenes
 
 import (
-	"sync"
-
 	"github.com/PatrickKoch07/game-proj/internal/audio"
 	"github.com/PatrickKoch07/game-proj/internal/logger"
 	"github.com/PatrickKoch07/game-proj/internal/sprites"
@@ -67,31 +65,3 @@ func InitOnScene(scene *Scene, gameObj GameObject) {
 	scene.AddToGameObjects(gameObjs...)
 	scene.AddToAudio(audioPlayers...)
 }
-
-// should only be called from the main thread
-func updateGameObjects(gameObjects []GameObject) []GameObject {
-	var wg sync.WaitGroup
-	maxIterInd := len(gameObjects)
-	for i, gameObject := range gameObjects {
-		// because we delete during a loop
-		if i >= maxIterInd {
-			break
-		}
-
-		if gameObject.IsDead() {
-			// remove from slice
-			gameObjects[i] = gameObjects[len(gameObjects)-1]
-			gameObjects = gameObjects[:len(gameObjects)-1]
-			i--
-			continue
-		}
-		if gameObject.ShouldSkipUpdate() {
-			continue
-		}
-
-		wg.Add(1)
-		go func() { defer wg.Done(); gameObject.Update() }()
-	}
-	wg.Wait()
-	return gameObjects
-}
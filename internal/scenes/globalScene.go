@@ -1,23 +1,44 @@
 package scenes
 
 import (
+	"math"
 	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/PatrickKoch07/game-proj/internal/assets"
 	"github.com/PatrickKoch07/game-proj/internal/audio"
 	"github.com/PatrickKoch07/game-proj/internal/cursor"
 	"github.com/PatrickKoch07/game-proj/internal/gameState"
+	"github.com/PatrickKoch07/game-proj/internal/inputs"
 	"github.com/PatrickKoch07/game-proj/internal/logger"
 	"github.com/PatrickKoch07/game-proj/internal/sprites"
 
-	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
 )
 
 const largeNumberOfSprites int = 100
 const largeNumberOfAudioPlayers int = 20
 
+// How long each frame can spend uploading textures while on the loading screen
+const loadingUploadBudget time.Duration = 4 * time.Millisecond
+
+// I added this so I can see some of the loading screen at least
+const minLoadingScreenTime time.Duration = 1 * time.Second
+
+// A scene switch that is waiting on its assets while the loading scene is shown
+type sceneLoad struct {
+	job           *assets.LoadJob
+	nextSceneFunc func() *Scene
+	// already killed (or killed once the transition into the loading scene is done), but its
+	// graphics objects are only unloaded once the next scene exists
+	previousScene *Scene
+	startTime     time.Time
+	// the switch out of the loading scene looks the same as the one into it
+	transition Transition
+}
+
 // context to bind sprites, game objects and audio to. Separate from scene as this is meant to last
 // for the whole duration of the game. as such, handles the switching between scenes
 type globalScene struct {
@@ -36,9 +57,31 @@ type globalScene struct {
 	loadingSceneFlag gameState.Flag
 	// To be loaded from the main function on game start. whatever is fed in should be user defined
 	sceneMap map[gameState.Flag]func() *Scene
-
+	// Optional. What to load in the background (while on the loading scene) before a scene is made
+	sceneAssets map[gameState.Flag]assets.Manifest
+	// not nil while the loading scene is up
+	loading *sceneLoad
+	// Optional. Used by the next scene switch instead of DefaultTransition
+	nextTransition *Transition
+	// not nil while a scene switch is animating (see transition.go)
+	transition    *runningTransition
+	transitioning atomic.Bool
+	// not nil while the scene switched away from is still shown (crossfades, wipes and slides)
+	outgoing *outgoingScene
+
+	// bottom of the scene stack, switched with the NextScene flag
 	currentScene *Scene
-	mu           sync.Mutex
+	// scenes on top of currentScene, last is top most (see sceneStack.go)
+	overlays []*overlay
+	// pushes, pops and replaces for the next update
+	stackChanges []stackChange
+	// float32 bits. 1.0 is normal speed, 0.0 is paused
+	timeScale atomic.Uint32
+	// what Resume goes back to
+	resumeScale float32
+	// PhaseMode for each Phase, Sequential by default (see updatePhases.go)
+	phaseModes [phaseCount]atomic.Int32
+	mu         sync.Mutex
 }
 
 var activeGlobalScene *globalScene
@@ -51,7 +94,10 @@ func GetGlobalScene() *globalScene {
 
 func createGlobalScene() {
 	activeGlobalScene = new(globalScene)
+	activeGlobalScene.timeScale.Store(math.Float32bits(1.0))
+	activeGlobalScene.resumeScale = 1.0
 	activeGlobalScene.GlobalSprites = append(activeGlobalScene.GlobalSprites, cursor.GetCursor())
+	inputs.GetInputManager().SetListenerFilter(activeGlobalScene.listenerGetsInput)
 }
 
 // should only be called in the main thread
@@ -66,6 +112,20 @@ func (gs *globalScene) InitializeGlobalScene(
 	gs.loadingSceneFlag = loadingScene
 }
 
+// should only be called in the main thread
+func (gs *globalScene) SetSceneAssets(sceneAssets map[gameState.Flag]assets.Manifest) {
+	gs.sceneAssets = sceneAssets
+}
+
+// From 0.0 to 1.0, how much of the next scene's assets are loaded. 1.0 when nothing is loading.
+func (gs *globalScene) LoadingProgress() float32 {
+	loading := gs.loading
+	if loading == nil {
+		return 1.0
+	}
+	return loading.job.Progress()
+}
+
 // thread safe by locking
 func (gs *globalScene) AddToSprites(newSprites ...*sprites.Sprite) {
 	gs.mu.Lock()
@@ -166,22 +226,22 @@ func (gs *globalScene) RemoveFromAudio(audioPlayer audio.Player) {
 }
 
 // should only be called in the main thread
-func (gs *globalScene) popNextScene() (func() *Scene, bool) {
+func (gs *globalScene) popNextScene() (gameState.Flag, func() *Scene, bool) {
 	default_func := func() *Scene { return new(Scene) }
 
 	val, ok := gameState.GetCurrentGameState().GetFlagValue(gameState.NextScene)
 	defer func() { gameState.GetCurrentGameState().SetFlagValue(gameState.NextScene, 0) }()
 	if !ok || val == 0 {
-		return default_func, false
+		return 0, default_func, false
 	}
 
 	nextSceneFunc, ok := gs.sceneMap[gameState.Flag(val)]
 	if !ok {
 		logger.LOG.Error().Msgf("Bad scene value: %v", val)
-		return default_func, false
+		return 0, default_func, false
 	}
 
-	return nextSceneFunc, true
+	return gameState.Flag(val), nextSceneFunc, true
 }
 
 // should only be called in the main thread
@@ -216,38 +276,88 @@ func (gs *globalScene) Update() {
 		glfw.GetCurrentContext().SetShouldClose(true)
 		return
 	}
-	if isNextSceneRequested() {
+	// scenes only change once the outgoing one is faded out (crossovers change right away)
+	if gs.loading != nil {
+		gs.stepLoading()
+	} else if isNextSceneRequested() && gs.transitionOut(gs.peekNextTransition()) {
 		gs.switchScene()
+	} else {
+		gs.transitionIn()
 	}
 
-	gs.currentScene.GameObjects = updateGameObjects(gs.currentScene.GameObjects)
-	gs.GlobalGameObjects = updateGameObjects(gs.GlobalGameObjects)
+	gs.applyStackChanges()
+	gs.updateStack()
 }
 
 // should only be called in the main thread
 func (gs *globalScene) switchScene() {
-	nextSceneFunc, ok := gs.popNextScene()
+	sceneFlag, nextSceneFunc, ok := gs.popNextScene()
 	if !ok {
 		logger.LOG.Error().Msg("Ignoring scene switch.")
 		return
 	}
-
-	Kill(gs.currentScene)
+	transition := gs.takeNextTransition()
+	defer gs.beginTransitionIn()
+
+	// overlays were on top of the old scene, so they go with it
+	if transition.Effect == FadeThroughColor {
+		Kill(gs.currentScene)
+		gs.clearStack()
+	} else {
+		gs.keepOutgoing()
+	}
 
 	if gs.useLoadingScene() {
-		logger.LOG.Debug().Msg("Drawing loading screen")
-		loadingScene := gs.sceneMap[gs.loadingSceneFlag]()
-		defer Kill(loadingScene)
-		// clear previous rendering
-		gl.Clear(gl.COLOR_BUFFER_BIT)
-		gl.Clear(gl.DEPTH_BUFFER_BIT)
-		// draw
-		sprites.GetDrawQueue().Draw()
-		glfw.GetCurrentContext().SwapBuffers()
-		// I added this so I can see some of the loading screen at least
-		time.Sleep(1 * time.Second)
+		logger.LOG.Debug().Msg("Showing loading screen")
+		gs.loading = &sceneLoad{
+			job:           assets.Load(gs.sceneAssets[sceneFlag]),
+			nextSceneFunc: nextSceneFunc,
+			previousScene: gs.currentScene,
+			startTime:     time.Now(),
+			transition:    transition,
+		}
+		// the loading scene gets updated & drawn like any other scene until the load is done
+		gs.currentScene = gs.sceneMap[gs.loadingSceneFlag]()
+		return
+	}
+
+	gs.createNextScene(nextSceneFunc, gs.currentScene)
+}
+
+// should only be called in the main thread
+func (gs *globalScene) stepLoading() {
+	done := gs.loading.job.Step(loadingUploadBudget)
+	// the transition into the loading scene finishes before the one out of it starts
+	waiting := !done || time.Since(gs.loading.startTime) < minLoadingScreenTime
+	if waiting || gs.outgoing != nil {
+		gs.transitionIn()
+		return
+	}
+	if !gs.transitionOut(gs.loading.transition) {
+		return
+	}
+	if errCount := gs.loading.job.ErrCount(); errCount != 0 {
+		logger.LOG.Warn().Msgf("%v assets failed to load in the background", errCount)
 	}
 
+	loadingScene := gs.currentScene
+	if gs.loading.transition.Effect == FadeThroughColor {
+		Kill(loadingScene)
+	} else {
+		gs.keepOutgoing()
+	}
+	nextSceneFunc := gs.loading.nextSceneFunc
+	previousScene := gs.loading.previousScene
+	gs.loading = nil
+
+	gs.createNextScene(nextSceneFunc, previousScene, loadingScene)
+	gs.beginTransitionIn()
+}
+
+// Makes the next scene current and unloads graphics objects only the old scenes used. While an
+// outgoing scene is still shown, that waits until it's gone.
+// should only be called in the main thread
+func (gs *globalScene) createNextScene(nextSceneFunc func() *Scene, oldScenes ...*Scene) {
 	// gameState specific logic goes here
 	// ex. load info of scene, if exists
 	//
@@ -268,9 +378,15 @@ func (gs *globalScene) switchScene() {
 	// create next scene
 	nextScene := nextSceneFunc()
 	logger.LOG.Debug().Msg("Next scene loaded, removing unused graphics objects")
-	unloadUncommonGraphicObjs(gs.currentScene, nextScene, gs.GlobalSprites)
-
 	gs.currentScene = nextScene
+	if gs.outgoing != nil {
+		gs.outgoing.unload = append(gs.outgoing.unload, oldScenes...)
+		return
+	}
+	for _, oldScene := range oldScenes {
+		unloadUncommonGraphicObjs(oldScene, nextScene, gs.GlobalSprites)
+	}
+	audio.UnloadUnusedAudio()
 }
 
 func (gs *globalScene) Kill() {
@@ -278,6 +394,15 @@ func (gs *globalScene) Kill() {
 	go gs.clearAudio()
 	go gs.killGameObjects()
 	go Kill(gs.currentScene)
+	for _, above := range gs.overlays {
+		go Kill(above.scene)
+	}
+	if gs.outgoing != nil {
+		go Kill(gs.outgoing.scene)
+		for _, above := range gs.outgoing.overlays {
+			go Kill(above.scene)
+		}
+	}
 }
 
 func (gs *globalScene) clearSprites() {
@@ -0,0 +1,57 @@
+package scenes
+
+import (
+	"math"
+)
+
+// Pausing and slow motion. The time scale multiplies how much game time passes each frame: 1.0 is
+// normal speed, 0.5 is half speed and 0.0 is paused. Anything that moves by elapsed time (physics,
+// particles) should scale it by TimeScale(). While paused, game objects aren't updated at all,
+// unless they opt out (see Unpausable) or are in a scene pushed with UpdatesWhilePaused.
+
+// Optional for game objects. Ones that return true keep updating while paused (ex. menus)
+type Unpausable interface {
+	UpdatesWhilePaused() bool
+}
+
+func updatesWhilePaused(gameObj GameObject) bool {
+	unpausable, ok := gameObj.(Unpausable)
+	return ok && unpausable.UpdatesWhilePaused()
+}
+
+// thread safe
+func (gs *globalScene) TimeScale() float32 {
+	return math.Float32frombits(gs.timeScale.Load())
+}
+
+// Negative scales are treated as 0.0 (paused)
+// thread safe
+func (gs *globalScene) SetTimeScale(scale float32) {
+	gs.timeScale.Store(math.Float32bits(max(scale, 0.0)))
+}
+
+// thread safe
+func (gs *globalScene) IsPaused() bool {
+	return gs.TimeScale() == 0.0
+}
+
+// Sets the time scale to 0.0, remembering the old one for Resume
+// thread safe by locking
+func (gs *globalScene) Pause() {
+	gs.mu.Lock()
+	if !gs.IsPaused() {
+		gs.resumeScale = gs.TimeScale()
+		gs.SetTimeScale(0.0)
+	}
+	gs.mu.Unlock()
+}
+
+// Goes back to the time scale from before Pause
+// thread safe by locking
+func (gs *globalScene) Resume() {
+	gs.mu.Lock()
+	if gs.IsPaused() {
+		gs.SetTimeScale(gs.resumeScale)
+	}
+	gs.mu.Unlock()
+}
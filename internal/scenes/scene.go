@@ -27,14 +27,11 @@ func unloadUncommonGraphicObjs(current *Scene, next *Scene, globalSprites []*spr
 	nextShaders := make(map[uint32]struct{})
 	nextTextures := make(map[uint32]struct{})
 	nextVAOs := make(map[uint32]struct{})
-	for _, sprite := range next.Sprites {
+	for _, sprite := range slices.Concat(next.Sprites, globalSprites) {
 		nextShaders[sprite.GetShaderId()] = struct{}{}
-		nextTextures[sprite.GetTextureId()] = struct{}{}
-		nextVAOs[sprite.GetVAO()] = struct{}{}
-	}
-	for _, sprite := range globalSprites {
-		nextShaders[sprite.GetShaderId()] = struct{}{}
-		nextTextures[sprite.GetTextureId()] = struct{}{}
+		for _, textureId := range sprite.GetTextureIds() {
+			nextTextures[textureId] = struct{}{}
+		}
 		nextVAOs[sprite.GetVAO()] = struct{}{}
 	}
 
@@ -45,10 +42,12 @@ func unloadUncommonGraphicObjs(current *Scene, next *Scene, globalSprites []*spr
 			sprites.DeleteShaderById(sprite.GetShaderId())
 			logger.LOG.Debug().Msgf("deleted shader %v", sprite.GetShaderId())
 		}
-		_, ok = nextTextures[sprite.GetTextureId()]
-		if !ok {
-			sprites.DeleteTextureById(sprite.GetTextureId())
-			logger.LOG.Debug().Msgf("deleted texture %v", sprite.GetTextureId())
+		for _, textureId := range sprite.GetTextureIds() {
+			_, ok = nextTextures[textureId]
+			if !ok {
+				sprites.DeleteTextureById(textureId)
+				logger.LOG.Debug().Msgf("deleted texture %v", textureId)
+			}
 		}
 		_, ok = nextVAOs[sprite.GetVAO()]
 		if !ok {
@@ -66,6 +65,14 @@ func Kill(s *Scene) {
 	go killSceneGameObjects(s)
 }
 
+// Like Kill, but done by the time it returns
+// should only be called in the main thread
+func killNow(s *Scene) {
+	clearSceneSprites(s)
+	clearSceneAudio(s)
+	killSceneGameObjects(s)
+}
+
 func clearSceneSprites(s *Scene) {
 	for _, sprite := range s.Sprites {
 		if sprite == nil {
@@ -150,6 +157,7 @@ func (s *Scene) RemoveFromGameObjects(gameObj GameObject) {
 		}
 	}
 	if index == -1 {
+		s.mu.Unlock()
 		return
 	}
 	s.GameObjects[index] = s.GameObjects[len(s.GameObjects)-1]
@@ -0,0 +1,255 @@
+package scenes
+
+import (
+	"slices"
+
+	"github.com/PatrickKoch07/game-proj/internal/inputs"
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+	"github.com/PatrickKoch07/game-proj/internal/sprites"
+)
+
+// Overlay scenes (inventory, dialogue, pause menu) are pushed on top of the current scene instead
+// of replacing it, so popping them goes back to exactly where things were. The current scene is
+// the bottom of the stack and is still switched with the NextScene flag, which also pops every
+// overlay. Pushes, pops and replaces happen on the next update, in the order they were asked for.
+//
+// Later overlays are on top: their UI roots are added later (so are drawn over and hit first) but
+// their sprites go by depth like any other, so keep overlay sprites in front (ex. UI sprites).
+//
+// During a crossover transition the outgoing scene and its overlays are their own stack, updated
+// before the current one (see transition.go).
+
+// How a pushed scene treats the scenes under it. Global game objects count as part of the bottom
+// scene.
+type OverlayParams struct {
+	// the scenes under it aren't updated (ex. inventory). Otherwise they keep going (ex. dialogue)
+	BlockUpdates bool
+	// the scenes under it get no input, neither key actions nor UI (see GetsInput)
+	BlockInput bool
+	// its game objects update even while paused (ex. pause menu)
+	UpdatesWhilePaused bool
+}
+
+type overlay struct {
+	scene  *Scene
+	params OverlayParams
+}
+
+type stackChangeKind int
+
+const (
+	pushScene stackChangeKind = iota
+	popScene
+	replaceScene
+)
+
+// A push, pop or replace waiting for the next update
+type stackChange struct {
+	kind      stackChangeKind
+	sceneFunc func() *Scene
+	params    OverlayParams
+}
+
+// Makes a scene on top of the others on the next update. The ones under it are left as they are.
+// thread safe by locking
+func (gs *globalScene) PushScene(sceneFunc func() *Scene, params OverlayParams) {
+	gs.mu.Lock()
+	gs.stackChanges = append(
+		gs.stackChanges,
+		stackChange{kind: pushScene, sceneFunc: sceneFunc, params: params},
+	)
+	gs.mu.Unlock()
+}
+
+// Kills the top overlay on the next update. The current scene itself can't be popped, switch it
+// with the NextScene flag instead.
+// thread safe by locking
+func (gs *globalScene) PopScene() {
+	gs.mu.Lock()
+	gs.stackChanges = append(gs.stackChanges, stackChange{kind: popScene})
+	gs.mu.Unlock()
+}
+
+// Swaps the top overlay for a new one on the next update (ex. inventory to map), or pushes it if
+// there are none
+// thread safe by locking
+func (gs *globalScene) ReplaceScene(sceneFunc func() *Scene, params OverlayParams) {
+	gs.mu.Lock()
+	gs.stackChanges = append(
+		gs.stackChanges,
+		stackChange{kind: replaceScene, sceneFunc: sceneFunc, params: params},
+	)
+	gs.mu.Unlock()
+}
+
+// How many overlays are on top of the current scene, as of the last update
+// thread safe by locking
+func (gs *globalScene) OverlayCount() int {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	return len(gs.overlays)
+}
+
+// If gameObj should get input: it isn't in a scene (or the global game objects) under an overlay
+// that blocks input, and no scene switch is under way. Game objects in no scene at all (ex.
+// managers) always get it.
+// thread safe by locking
+func (gs *globalScene) GetsInput(gameObj GameObject) bool {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	blockedBelow := topBlocking(gs.overlays, func(params OverlayParams) bool {
+		return params.BlockInput
+	})
+	if gs.transitioning.Load() {
+		blockedBelow = len(gs.overlays)
+	}
+	if blockedBelow < 0 {
+		return true
+	}
+	if gs.outgoing != nil && gs.outgoing.holds(gameObj) {
+		return false
+	}
+	if slices.Contains(gs.GlobalGameObjects, gameObj) || sceneHolds(gs.currentScene, gameObj) {
+		return false
+	}
+	for _, under := range gs.overlays[:blockedBelow] {
+		if sceneHolds(under.scene, gameObj) {
+			return false
+		}
+	}
+	return true
+}
+
+// Input listeners that are game objects go through GetsInput
+func (gs *globalScene) listenerGetsInput(listener inputs.InputListener) bool {
+	gameObj, ok := listener.(GameObject)
+	return !ok || gs.GetsInput(gameObj)
+}
+
+func sceneHolds(s *Scene, gameObj GameObject) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Contains(s.GameObjects, gameObj)
+}
+
+// Index of the top most overlay that blocks, -1 if none do. Everything under it is blocked.
+func topBlocking(overlays []*overlay, blocks func(OverlayParams) bool) int {
+	for i := len(overlays) - 1; i >= 0; i-- {
+		if blocks(overlays[i].params) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Updates the current scene, global game objects and overlays, skipping whatever is under an
+// overlay that blocks updates. All of them go through each phase together (see updatePhases.go).
+// should only be called in the main thread
+func (gs *globalScene) updateStack() {
+	paused := gs.IsPaused()
+	gs.mu.Lock()
+	overlays := slices.Clone(gs.overlays)
+	outgoing := gs.outgoing
+	gs.mu.Unlock()
+
+	// any sprites the outgoing scene adds are drawn with it
+	if outgoing != nil {
+		drawQueue := sprites.GetDrawQueue()
+		drawGroup := drawQueue.Group()
+		drawQueue.SetGroup(outgoing.drawGroup)
+		gs.runPhases(stackGameObjects(outgoing.scene.updatableGameObjects, outgoing.overlays, paused))
+		drawQueue.SetGroup(drawGroup)
+	}
+	bottom := func(paused bool) []GameObject {
+		return append(gs.currentScene.updatableGameObjects(paused), gs.updatableGameObjects(paused)...)
+	}
+	gs.runPhases(stackGameObjects(bottom, overlays, paused))
+}
+
+// The bottom scene's game objects (unless an overlay blocks updates), then the overlays' from the
+// bottom up
+func stackGameObjects(
+	bottom func(paused bool) []GameObject,
+	overlays []*overlay,
+	paused bool,
+) []GameObject {
+	blockedBelow := topBlocking(overlays, func(params OverlayParams) bool {
+		return params.BlockUpdates
+	})
+	var gameObjects []GameObject
+	if blockedBelow < 0 {
+		gameObjects = append(gameObjects, bottom(paused)...)
+	}
+	for i, above := range overlays {
+		if i < blockedBelow {
+			continue
+		}
+		overlayPaused := paused && !above.params.UpdatesWhilePaused
+		gameObjects = append(gameObjects, above.scene.updatableGameObjects(overlayPaused)...)
+	}
+	return gameObjects
+}
+
+// should only be called in the main thread
+func (gs *globalScene) applyStackChanges() {
+	gs.mu.Lock()
+	changes := gs.stackChanges
+	gs.stackChanges = nil
+	gs.mu.Unlock()
+
+	for _, change := range changes {
+		switch change.kind {
+		case pushScene:
+			gs.pushOverlay(change)
+		case popScene:
+			if !gs.popOverlay() {
+				logger.LOG.Warn().Msg("No overlay scene to pop")
+			}
+		case replaceScene:
+			gs.popOverlay()
+			gs.pushOverlay(change)
+		}
+	}
+}
+
+// The scene is made without the lock held, as making it adds to scenes
+// should only be called in the main thread
+func (gs *globalScene) pushOverlay(change stackChange) {
+	logger.LOG.Debug().Msg("Pushing overlay scene")
+	pushed := &overlay{scene: change.sceneFunc(), params: change.params}
+	gs.mu.Lock()
+	gs.overlays = append(gs.overlays, pushed)
+	gs.mu.Unlock()
+}
+
+// Overlays are small and use what the scenes under them do, so their graphics objects are left
+// loaded for the next time they're pushed. Returns if there was one to pop.
+// should only be called in the main thread
+func (gs *globalScene) popOverlay() bool {
+	gs.mu.Lock()
+	if len(gs.overlays) == 0 {
+		gs.mu.Unlock()
+		return false
+	}
+	popped := gs.overlays[len(gs.overlays)-1]
+	gs.overlays = gs.overlays[:len(gs.overlays)-1]
+	gs.mu.Unlock()
+
+	logger.LOG.Debug().Msg("Popping overlay scene")
+	Kill(popped.scene)
+	return true
+}
+
+// Pops every overlay and drops any pushes still waiting, as they were for the old scene
+// should only be called in the main thread
+func (gs *globalScene) clearStack() {
+	gs.mu.Lock()
+	gs.stackChanges = nil
+	gs.mu.Unlock()
+	for gs.popOverlay() {
+	}
+}
@@ -0,0 +1,301 @@
+package scenes
+
+// Animated scene switches. The outgoing scene either fades to a color (and the incoming one fades
+// back from it) or stays alive while the incoming one crossfades, wipes or slides over it (see
+// sprites/transition.go). For the latter, both scenes (and the outgoing one's overlays) are
+// updated and drawn during the crossover, and the outgoing one is only killed once the incoming
+// one is all the way in. Their colliders are all in the same maps meanwhile, so keep anything
+// that can touch across scenes global. Scenes get no input until the transition is done.
+
+import (
+	"slices"
+	"time"
+
+	"github.com/PatrickKoch07/game-proj/internal/audio"
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+	"github.com/PatrickKoch07/game-proj/internal/sprites"
+)
+
+type TransitionEffect int
+
+const (
+	FadeThroughColor TransitionEffect = iota
+	Crossfade
+	Wipe
+	Slide
+)
+
+// The way the incoming scene comes in
+type TransitionDirection int
+
+const (
+	LeftToRight TransitionDirection = iota
+	RightToLeft
+	TopToBottom
+	BottomToTop
+)
+
+type Transition struct {
+	Effect TransitionEffect
+	// the whole transition (for fades, out and back in)
+	Duration time.Duration
+	// FadeThroughColor only
+	Color sprites.Color
+	// Wipe and Slide only
+	Direction TransitionDirection
+}
+
+// What scene switches use unless told otherwise (see SetNextTransition)
+var DefaultTransition = Transition{
+	Effect:   FadeThroughColor,
+	Duration: 500 * time.Millisecond,
+	Color:    sprites.Color{A: 1.0},
+}
+
+// making a scene takes a long frame, which shouldn't skip most of the transition
+const maxTransitionStep time.Duration = 50 * time.Millisecond
+
+type runningTransition struct {
+	Transition
+	// past the switch, showing the incoming scene
+	in bool
+	// from 0.0 to 1.0 through the current half (out or in)
+	progress float32
+	lastStep time.Time
+}
+
+// The scene switched away from, still updated & drawn while the incoming scene comes in
+type outgoingScene struct {
+	scene    *Scene
+	overlays []*overlay
+	// what its sprites were added to the draw queue with
+	drawGroup uint32
+	// scenes whose graphics objects are unloaded once it's gone, as it may still be using them
+	unload []*Scene
+}
+
+// The transition the next scene switch uses instead of DefaultTransition. Going through the
+// loading scene, it's used both ways.
+// thread safe by locking
+func (gs *globalScene) SetNextTransition(transition Transition) {
+	gs.mu.Lock()
+	gs.nextTransition = &transition
+	gs.mu.Unlock()
+}
+
+// If a scene switch is under way. Scenes get no input until it's done.
+// thread safe
+func (gs *globalScene) IsTransitioning() bool {
+	return gs.transitioning.Load()
+}
+
+// should only be called in the main thread
+func (gs *globalScene) peekNextTransition() Transition {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if gs.nextTransition == nil {
+		return DefaultTransition
+	}
+	return *gs.nextTransition
+}
+
+// should only be called in the main thread
+func (gs *globalScene) takeNextTransition() Transition {
+	transition := gs.peekNextTransition()
+	gs.mu.Lock()
+	gs.nextTransition = nil
+	gs.mu.Unlock()
+	return transition
+}
+
+// Moves the outgoing half on a frame, starting it if needed. Returns if the scene can be switched
+// (faded out, or right away if the scenes cross over).
+// should only be called in the main thread
+func (gs *globalScene) transitionOut(transition Transition) bool {
+	// the last switch has to finish crossing over first
+	if gs.outgoing != nil {
+		return false
+	}
+	if gs.transition == nil || gs.transition.in {
+		gs.transition = startTransition(transition, gs.transition)
+		gs.transitioning.Store(true)
+	}
+	t := gs.transition
+	if t.Effect != FadeThroughColor {
+		return true
+	}
+	t.progress = min(t.progress+t.step(), 1.0)
+	sprites.GetPostProcessor().SetFade(t.Color, t.progress)
+	return t.progress >= 1.0
+}
+
+// Starts showing the incoming scene, call right after making it
+// should only be called in the main thread
+func (gs *globalScene) beginTransitionIn() {
+	if gs.transition == nil {
+		return
+	}
+	gs.transition.in = true
+	gs.transition.progress = 0.0
+	// the incoming scene isn't shown over the outgoing one for the frame it's made
+	if gs.outgoing != nil {
+		gs.transition.show()
+	}
+}
+
+// Keeps the current scene and its overlays going as the outgoing scene, drawn apart from anything
+// made from now on (except the global sprites).
+// should only be called in the main thread
+func (gs *globalScene) keepOutgoing() {
+	drawQueue := sprites.GetDrawQueue()
+	drawGroup := drawQueue.Group()
+	drawQueue.SetGroup(drawGroup + 1)
+	sprites.GetPostProcessor().StartTransition(drawGroup)
+
+	gs.mu.Lock()
+	drawQueue.MoveToGroup(drawGroup+1, gs.GlobalSprites...)
+	gs.outgoing = &outgoingScene{
+		scene:     gs.currentScene,
+		overlays:  gs.overlays,
+		drawGroup: drawGroup,
+	}
+	gs.overlays = nil
+	// they were for the outgoing scene
+	gs.stackChanges = nil
+	gs.mu.Unlock()
+}
+
+// Kills the outgoing scene, if any, once the transition is done with it
+// should only be called in the main thread
+func (gs *globalScene) dropOutgoing() {
+	gs.mu.Lock()
+	outgoing := gs.outgoing
+	gs.outgoing = nil
+	gs.mu.Unlock()
+	if outgoing == nil {
+		return
+	}
+
+	logger.LOG.Debug().Msg("Transition done, killing outgoing scene")
+	// right away rather than like Kill, so it's gone from the queue before the next draw (where it
+	// would be drawn over the incoming scene)
+	for _, above := range outgoing.overlays {
+		killNow(above.scene)
+	}
+	killNow(outgoing.scene)
+	// nothing to unload going into the loading scene, which is still preloading the next scene
+	if len(outgoing.unload) == 0 {
+		return
+	}
+	for _, oldScene := range outgoing.unload {
+		unloadUncommonGraphicObjs(oldScene, gs.currentScene, gs.GlobalSprites)
+	}
+	audio.UnloadUnusedAudio()
+}
+
+// If gameObj is in the outgoing scene or one of its overlays
+// should be called with gs.mu locked
+func (o *outgoingScene) holds(gameObj GameObject) bool {
+	return sceneHolds(o.scene, gameObj) || slices.ContainsFunc(o.overlays, func(above *overlay) bool {
+		return sceneHolds(above.scene, gameObj)
+	})
+}
+
+// Moves the incoming half on a frame, if a transition is running. A transition still on its
+// outgoing half (ex. the switch was called off) turns around.
+// should only be called in the main thread
+func (gs *globalScene) transitionIn() {
+	t := gs.transition
+	if t == nil {
+		return
+	}
+	if !t.in {
+		t.in = true
+		// fade back from wherever it got to. Crossovers have no outgoing half to go back from
+		t.progress = 1.0 - t.progress
+		if t.Effect != FadeThroughColor {
+			t.progress = 1.0
+		}
+	}
+
+	t.progress = min(t.progress+t.step(), 1.0)
+	t.show()
+	if t.progress >= 1.0 {
+		gs.endTransition()
+	}
+}
+
+// should only be called in the main thread
+func (t *runningTransition) show() {
+	if t.Effect == FadeThroughColor {
+		sprites.GetPostProcessor().SetFade(t.Color, 1.0-t.progress)
+	} else {
+		sprites.GetPostProcessor().SetTransition(t.spriteEffect(), t.progress, t.direction())
+	}
+}
+
+// should only be called in the main thread
+func (gs *globalScene) endTransition() {
+	gs.dropOutgoing()
+	sprites.GetPostProcessor().SetFade(sprites.Color{}, 0.0)
+	sprites.GetPostProcessor().ClearTransition()
+	gs.transition = nil
+	gs.transitioning.Store(false)
+}
+
+// A fade into a fade (ex. leaving the loading scene while still fading into it) carries on from
+// how faded the screen already is. Anything else starts clean.
+// should only be called in the main thread
+func startTransition(transition Transition, previous *runningTransition) *runningTransition {
+	t := &runningTransition{Transition: transition}
+	sprites.GetPostProcessor().ClearTransition()
+	if transition.Effect != FadeThroughColor {
+		sprites.GetPostProcessor().SetFade(sprites.Color{}, 0.0)
+	} else if previous != nil && previous.Effect == FadeThroughColor {
+		t.progress = 1.0 - previous.progress
+	}
+	return t
+}
+
+// How much further along the current half this frame is
+func (t *runningTransition) step() float32 {
+	now := time.Now()
+	var elapsed time.Duration
+	if !t.lastStep.IsZero() {
+		elapsed = min(now.Sub(t.lastStep), maxTransitionStep)
+	}
+	t.lastStep = now
+
+	halfDuration := t.Duration
+	if t.Effect == FadeThroughColor {
+		halfDuration /= 2
+	}
+	if halfDuration <= 0 {
+		return 1.0
+	}
+	return float32(elapsed.Seconds() / halfDuration.Seconds())
+}
+
+func (t *runningTransition) spriteEffect() sprites.TransitionEffect {
+	switch t.Effect {
+	case Wipe:
+		return sprites.WipeEffect
+	case Slide:
+		return sprites.SlideEffect
+	default:
+		return sprites.CrossfadeEffect
+	}
+}
+
+func (t *runningTransition) direction() sprites.ScreenCoords {
+	switch t.Direction {
+	case RightToLeft:
+		return sprites.ScreenCoords{X: -1.0, Y: 0.0}
+	case TopToBottom:
+		return sprites.ScreenCoords{X: 0.0, Y: 1.0}
+	case BottomToTop:
+		return sprites.ScreenCoords{X: 0.0, Y: -1.0}
+	default:
+		return sprites.ScreenCoords{X: 1.0, Y: 0.0}
+	}
+}
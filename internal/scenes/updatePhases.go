@@ -0,0 +1,206 @@
+package scenes
+
+import (
+	"cmp"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// Game objects update in phases, every game object finishing a phase before any start the next:
+// PreUpdate, Update, PostUpdate, then LateUpdate (ex. a camera following the player once the
+// player has moved). Update is the one every game object has, the others are optional (see
+// PreUpdater, PostUpdater and LateUpdater). Within a phase game objects go by priority (see
+// Prioritized), then by scene (current scene, global game objects, then overlays bottom to top),
+// then in the order they were added. So every frame runs in the same order.
+//
+// Phases run sequentially unless set to Parallel (see SetPhaseMode), which runs the game objects
+// of each priority at the same time on a pool of workers, priorities still one after another.
+// Only use it for phases where the game objects don't touch each other's state.
+
+type Phase int
+
+const (
+	PreUpdatePhase Phase = iota
+	UpdatePhase
+	PostUpdatePhase
+	LateUpdatePhase
+	phaseCount
+)
+
+type PhaseMode int32
+
+const (
+	Sequential PhaseMode = iota
+	Parallel
+)
+
+// Optional for game objects, before any game object's Update
+type PreUpdater interface {
+	PreUpdate()
+}
+
+// Optional for game objects, after every game object's Update
+type PostUpdater interface {
+	PostUpdate()
+}
+
+// Optional for game objects, after every game object's PostUpdate
+type LateUpdater interface {
+	LateUpdate()
+}
+
+// Optional for game objects. Lower goes first in every phase, the default is 0
+type Prioritized interface {
+	UpdatePriority() int
+}
+
+// how many game objects a Parallel phase updates at once
+var updateWorkers = runtime.NumCPU()
+
+type phaseCall struct {
+	priority int
+	call     func()
+}
+
+// thread safe
+func (gs *globalScene) SetPhaseMode(phase Phase, mode PhaseMode) {
+	if phase < 0 || phase >= phaseCount {
+		return
+	}
+	gs.phaseModes[phase].Store(int32(mode))
+}
+
+// thread safe
+func (gs *globalScene) PhaseMode(phase Phase) PhaseMode {
+	if phase < 0 || phase >= phaseCount {
+		return Sequential
+	}
+	return PhaseMode(gs.phaseModes[phase].Load())
+}
+
+func updatePriority(gameObj GameObject) int {
+	prioritized, ok := gameObj.(Prioritized)
+	if !ok {
+		return 0
+	}
+	return prioritized.UpdatePriority()
+}
+
+// The game object's call for the phase, nil if it doesn't have one
+func phaseFunc(gameObj GameObject, phase Phase) func() {
+	switch phase {
+	case PreUpdatePhase:
+		if preUpdater, ok := gameObj.(PreUpdater); ok {
+			return preUpdater.PreUpdate
+		}
+	case UpdatePhase:
+		return gameObj.Update
+	case PostUpdatePhase:
+		if postUpdater, ok := gameObj.(PostUpdater); ok {
+			return postUpdater.PostUpdate
+		}
+	case LateUpdatePhase:
+		if lateUpdater, ok := gameObj.(LateUpdater); ok {
+			return lateUpdater.LateUpdate
+		}
+	}
+	return nil
+}
+
+// Game objects should already be in scene order, and only the ones to update this frame
+// should only be called in the main thread
+func (gs *globalScene) runPhases(gameObjects []GameObject) {
+	type prioritizedObject struct {
+		gameObj  GameObject
+		priority int
+	}
+	ordered := make([]prioritizedObject, len(gameObjects))
+	for i, gameObj := range gameObjects {
+		ordered[i] = prioritizedObject{gameObj: gameObj, priority: updatePriority(gameObj)}
+	}
+	slices.SortStableFunc(ordered, func(a prioritizedObject, b prioritizedObject) int {
+		return cmp.Compare(a.priority, b.priority)
+	})
+
+	for phase := PreUpdatePhase; phase < phaseCount; phase++ {
+		calls := make([]phaseCall, 0, len(ordered))
+		for _, o := range ordered {
+			call := phaseFunc(o.gameObj, phase)
+			if call != nil {
+				calls = append(calls, phaseCall{priority: o.priority, call: call})
+			}
+		}
+		if gs.PhaseMode(phase) == Parallel {
+			runParallel(calls)
+		} else {
+			for _, c := range calls {
+				c.call()
+			}
+		}
+	}
+}
+
+// Each priority in turn, its calls shared out between the workers
+// should only be called in the main thread
+func runParallel(calls []phaseCall) {
+	for start := 0; start < len(calls); {
+		end := start + 1
+		for end < len(calls) && calls[end].priority == calls[start].priority {
+			end++
+		}
+		runOnWorkers(calls[start:end])
+		start = end
+	}
+}
+
+func runOnWorkers(calls []phaseCall) {
+	if len(calls) == 1 {
+		calls[0].call()
+		return
+	}
+	jobs := make(chan func(), len(calls))
+	for _, c := range calls {
+		jobs <- c.call
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	for range min(updateWorkers, len(calls)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				job()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Drops the scene's dead game objects for good, then gives the ones to update this frame. While
+// paused, only game objects that opt out of pausing (see Unpausable) are updated.
+// thread safe by locking
+func (s *Scene) updatableGameObjects(paused bool) []GameObject {
+	s.mu.Lock()
+	s.GameObjects = slices.DeleteFunc(s.GameObjects, GameObject.IsDead)
+	gameObjects := slices.Clone(s.GameObjects)
+	s.mu.Unlock()
+	return updatable(gameObjects, paused)
+}
+
+// Like Scene.updatableGameObjects, for the global game objects
+// thread safe by locking
+func (gs *globalScene) updatableGameObjects(paused bool) []GameObject {
+	gs.mu.Lock()
+	gs.GlobalGameObjects = slices.DeleteFunc(gs.GlobalGameObjects, GameObject.IsDead)
+	gameObjects := slices.Clone(gs.GlobalGameObjects)
+	gs.mu.Unlock()
+	return updatable(gameObjects, paused)
+}
+
+func updatable(gameObjects []GameObject, paused bool) []GameObject {
+	return slices.DeleteFunc(gameObjects, func(gameObj GameObject) bool {
+		return gameObj.ShouldSkipUpdate() || (paused && !updatesWhilePaused(gameObj))
+	})
+}
@@ -0,0 +1,144 @@
+package scenes
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/PatrickKoch07/game-proj/internal/audio"
+	"github.com/PatrickKoch07/game-proj/internal/sprites"
+)
+
+// what got called, in order. Locked for parallel phases
+type callLog struct {
+	calls []string
+	mu    sync.Mutex
+}
+
+func (l *callLog) add(call string) {
+	l.mu.Lock()
+	l.calls = append(l.calls, call)
+	l.mu.Unlock()
+}
+
+// only updates
+type testObject struct {
+	name     string
+	priority int
+	log      *callLog
+}
+
+func (o *testObject) InitInstance() ([]GameObject, []*sprites.Sprite, []audio.Player, bool) {
+	return []GameObject{o}, nil, nil, true
+}
+func (o *testObject) Update()                { o.log.add("update " + o.name) }
+func (o *testObject) ShouldSkipUpdate() bool { return false }
+func (o *testObject) Kill()                  {}
+func (o *testObject) IsDead() bool           { return false }
+func (o *testObject) UpdatePriority() int    { return o.priority }
+
+// in every phase
+type phasedObject struct {
+	testObject
+}
+
+func (o *phasedObject) PreUpdate()  { o.log.add("pre " + o.name) }
+func (o *phasedObject) PostUpdate() { o.log.add("post " + o.name) }
+func (o *phasedObject) LateUpdate() { o.log.add("late " + o.name) }
+
+func TestRunPhases(t *testing.T) {
+	type objectSpec struct {
+		name     string
+		priority int
+		phased   bool
+	}
+
+	tests := []struct {
+		name    string
+		objects []objectSpec
+		want    []string
+	}{
+		{
+			name:    "added order",
+			objects: []objectSpec{{name: "a"}, {name: "b"}, {name: "c"}},
+			want:    []string{"update a", "update b", "update c"},
+		},
+		{
+			name: "by priority",
+			objects: []objectSpec{
+				{name: "a"}, {name: "b", priority: -1}, {name: "c", priority: 1}, {name: "d"},
+			},
+			want: []string{"update b", "update a", "update d", "update c"},
+		},
+		{
+			name: "phases",
+			objects: []objectSpec{
+				{name: "a", phased: true}, {name: "b"}, {name: "c", phased: true, priority: -1},
+			},
+			want: []string{
+				"pre c", "pre a",
+				"update c", "update a", "update b",
+				"post c", "post a",
+				"late c", "late a",
+			},
+		},
+		{
+			name:    "none",
+			objects: []objectSpec{},
+			want:    []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			log := &callLog{calls: []string{}}
+			gameObjects := make([]GameObject, len(test.objects))
+			for i, spec := range test.objects {
+				object := testObject{name: spec.name, priority: spec.priority, log: log}
+				if spec.phased {
+					gameObjects[i] = &phasedObject{object}
+				} else {
+					gameObjects[i] = &object
+				}
+			}
+
+			var gs globalScene
+			gs.runPhases(gameObjects)
+			if !slices.Equal(log.calls, test.want) {
+				t.Errorf("got %v, want %v", log.calls, test.want)
+			}
+		})
+	}
+}
+
+func TestRunPhasesParallel(t *testing.T) {
+	log := &callLog{}
+	priorities := []int{1, 0, 1, 0, 2, 1, 0}
+	gameObjects := make([]GameObject, len(priorities))
+	for i, priority := range priorities {
+		gameObjects[i] = &testObject{name: string(rune('a' + i)), priority: priority, log: log}
+	}
+	// names by priority, each priority can be in any order
+	wantGroups := [][]string{
+		{"update b", "update d", "update g"},
+		{"update a", "update c", "update f"},
+		{"update e"},
+	}
+
+	var gs globalScene
+	gs.SetPhaseMode(UpdatePhase, Parallel)
+	gs.runPhases(gameObjects)
+
+	if len(log.calls) != len(priorities) {
+		t.Fatalf("got %v calls, want %v: %v", len(log.calls), len(priorities), log.calls)
+	}
+	start := 0
+	for _, group := range wantGroups {
+		got := slices.Clone(log.calls[start : start+len(group)])
+		slices.Sort(got)
+		if !slices.Equal(got, group) {
+			t.Errorf("calls %v to %v were %v, want %v", start, start+len(group), got, group)
+		}
+		start += len(group)
+	}
+}
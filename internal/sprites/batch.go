@@ -0,0 +1,253 @@
+package sprites
+
+// Batch sprites draw many copies (instances) of the same texture in one draw call, each with its
+// own position, size, rotation and color (ex. particles). They go through the draw queue like any
+// other sprite, but their shader gets the instances as vertex attributes instead of the transform
+// and scale uniforms.
+
+import (
+	"runtime"
+	"slices"
+	"sync"
+	"unsafe"
+
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// floats per instance: screen x, screen y, width, height, r, g, b, a,
+// region x, region y, region width, region height, rotation
+const floatsPerInstance int = 13
+
+type BatchInstance struct {
+	// Where the middle of the instance is on the screen
+	ScreenCenter ScreenCoords
+	// In pixels
+	Width  float32
+	Height float32
+	// Radians, clockwise on screen, around the middle
+	Rotation float32
+	// Multiplied with the texture color
+	Color Color
+	// Part of the texture to draw. The zero value is the whole texture
+	TextureRegion TextureRegion
+}
+
+// In texture coords (0.0 to 1.0), with 0.0, 0.0 being the top left of the image. A negative
+// Width or Height mirrors the region.
+type TextureRegion struct {
+	X      float32
+	Y      float32
+	Width  float32
+	Height float32
+}
+
+type instanceBatch struct {
+	buffers batchBuffers
+	// what the game objects set, uploaded when drawn (main thread)
+	instances []float32
+	count     int32
+	changed   bool
+	mu        sync.Mutex
+}
+
+type batchBuffers struct {
+	vao         uint32
+	vertexVBO   uint32
+	instanceVBO uint32
+	// instances the instance buffer can hold before it needs to grow
+	capacity int
+}
+
+// buffers of batch sprites that got garbage collected, deleted in the next Draw (main thread)
+var batchesToDelete []batchBuffers
+var batchesToDeleteMu sync.Mutex
+
+// Should never be called concurrently because it *COULD* use glfw/gl
+// which must be called from main thread
+func CreateBatchSprite(initParams *SpriteInitParams) (*Sprite, error) {
+	logger.LOG.Info().Msg("Creating new batch sprite")
+
+	sprite := Sprite{}
+	sprite.lazyDeletionMark.Store(false)
+
+	var err error
+	sprite.shaderId, err = getShader(initParams.ShaderRelPaths)
+	if err != nil {
+		return nil, err
+	}
+	sprite.Tex, err = getTexture(initParams.TextureRelPath, initParams.TextureCoords)
+	if err != nil {
+		return nil, err
+	}
+	sprite.Tint = Color{R: 1.0, G: 1.0, B: 1.0, A: 1.0}
+	sprite.Opacity = 1.0
+	sprite.Material = initParams.Material
+
+	// every batch has its own VAO since the instance buffer is part of it
+	sprite.batch = new(instanceBatch)
+	sprite.batch.buffers = makeBatchVAO(initParams.TextureCoords)
+	sprite.vao = sprite.batch.buffers.vao
+	runtime.AddCleanup(&sprite, queueBatchDeletion, sprite.batch.buffers)
+
+	return &sprite, nil
+}
+
+// Replaces every instance drawn by the batch sprite. Does nothing for normal sprites.
+// thread safe by locking
+func (s *Sprite) SetInstances(instances []BatchInstance) {
+	if s.batch == nil {
+		logger.LOG.Warn().Msg("Setting instances on a sprite that isn't a batch sprite")
+		return
+	}
+	s.batch.mu.Lock()
+	defer s.batch.mu.Unlock()
+
+	s.batch.instances = slices.Grow(s.batch.instances[:0], len(instances)*floatsPerInstance)
+	for _, instance := range instances {
+		region := instance.TextureRegion
+		if region == (TextureRegion{}) {
+			region = TextureRegion{Width: 1.0, Height: 1.0}
+		}
+		s.batch.instances = append(
+			s.batch.instances,
+			instance.ScreenCenter.X,
+			instance.ScreenCenter.Y,
+			instance.Width,
+			instance.Height,
+			instance.Color.R,
+			instance.Color.G,
+			instance.Color.B,
+			instance.Color.A,
+			region.X,
+			region.Y,
+			region.Width,
+			region.Height,
+			instance.Rotation,
+		)
+	}
+	s.batch.count = int32(len(instances))
+	s.batch.changed = true
+}
+
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func (s *Sprite) drawBatch() {
+	b := s.batch
+	b.mu.Lock()
+	if b.changed {
+		b.upload()
+		b.changed = false
+	}
+	count := b.count
+	b.mu.Unlock()
+	if count == 0 {
+		return
+	}
+
+	gl.UseProgram(s.shaderId)
+	setColor(s.shaderId, s.Tint, s.Opacity)
+	applyMaterial(s.shaderId, s.Material)
+
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, s.Tex.textureId)
+
+	gl.BindVertexArray(b.buffers.vao)
+	gl.DrawArraysInstanced(gl.TRIANGLES, 0, 6, count)
+	gl.BindVertexArray(0)
+}
+
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func (b *instanceBatch) upload() {
+	if len(b.instances) == 0 {
+		return
+	}
+	gl.BindBuffer(gl.ARRAY_BUFFER, b.buffers.instanceVBO)
+	defer gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+
+	size := len(b.instances) * 4
+	if int(b.count) > b.buffers.capacity {
+		// grow to double so a growing emitter doesn't reallocate every frame
+		b.buffers.capacity = 2 * int(b.count)
+		gl.BufferData(
+			gl.ARRAY_BUFFER,
+			b.buffers.capacity*floatsPerInstance*4,
+			nil,
+			gl.STREAM_DRAW,
+		)
+	}
+	gl.BufferSubData(gl.ARRAY_BUFFER, 0, size, unsafe.Pointer(&b.instances[0]))
+}
+
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func makeBatchVAO(textureCoords [12]float32) batchBuffers {
+	logger.LOG.Info().Msg("Initializing batch VAO & VBOs")
+	buffers := batchBuffers{}
+	var spritePosCoords [12]float32 = [12]float32{
+		// Bottom left starting position
+		0.0, 0.0,
+		0.0, 1.0,
+		1.0, 1.0,
+
+		0.0, 0.0,
+		1.0, 1.0,
+		1.0, 0.0,
+	}
+	var vertexCoords [24]float32
+	// Position X Y, Texture X Y
+	for i := 0; i < 6; i++ {
+		vertexCoords[4*i] = spritePosCoords[2*i]
+		vertexCoords[4*i+1] = spritePosCoords[2*i+1]
+
+		vertexCoords[4*i+2] = textureCoords[2*i]
+		vertexCoords[4*i+3] = textureCoords[2*i+1]
+	}
+	gl.GenVertexArrays(1, &buffers.vao)
+	gl.GenBuffers(1, &buffers.vertexVBO)
+	gl.GenBuffers(1, &buffers.instanceVBO)
+
+	gl.BindVertexArray(buffers.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, buffers.vertexVBO)
+	gl.BufferData(gl.ARRAY_BUFFER, 24*4, unsafe.Pointer(&vertexCoords[0]), gl.STATIC_DRAW)
+	gl.VertexAttribPointer(0, 4, gl.FLOAT, false, 4*4, nil)
+	gl.EnableVertexAttribArray(0)
+
+	// per instance: placement (x, y, width, height), color (r, g, b, a),
+	// texture region (x, y, width, height) and rotation. See include/batchInstance.glsl
+	gl.BindBuffer(gl.ARRAY_BUFFER, buffers.instanceVBO)
+	stride := int32(floatsPerInstance * 4)
+	attributeSizes := [4]int32{4, 4, 4, 1}
+	var offset uintptr = 0
+	for i, attributeSize := range attributeSizes {
+		location := uint32(i + 1)
+		gl.VertexAttribPointerWithOffset(location, attributeSize, gl.FLOAT, false, stride, offset)
+		gl.EnableVertexAttribArray(location)
+		gl.VertexAttribDivisor(location, 1)
+		offset += uintptr(attributeSize) * 4
+	}
+
+	// unbind
+	gl.BindVertexArray(0)
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+	return buffers
+}
+
+func queueBatchDeletion(buffers batchBuffers) {
+	batchesToDeleteMu.Lock()
+	batchesToDelete = append(batchesToDelete, buffers)
+	batchesToDeleteMu.Unlock()
+}
+
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func deleteQueuedBatches() {
+	batchesToDeleteMu.Lock()
+	toDelete := batchesToDelete
+	batchesToDelete = nil
+	batchesToDeleteMu.Unlock()
+
+	for _, buffers := range toDelete {
+		gl.DeleteVertexArrays(1, &buffers.vao)
+		gl.DeleteBuffers(1, &buffers.vertexVBO)
+		gl.DeleteBuffers(1, &buffers.instanceVBO)
+	}
+}
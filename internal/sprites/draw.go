@@ -18,6 +18,22 @@ type Sprite struct {
 	ScreenCenter ScreenCoords
 	// from 0.0 to 1.0, Where the origin of the object is on the sprite (top left is 0.0, 0.0)
 	SpriteCenter SpriteCoords
+	// Radians, clockwise on screen, around SpriteCenter
+	Rotation float32
+	// Mirrors the sprite in place (around the middle of the sprite, not SpriteCenter)
+	FlipX bool
+	FlipY bool
+	// Multiplied with the texture color. White (the default) changes nothing
+	Tint Color
+	// from 0.0 (invisible) to 1.0 (default). Note that sprites are not sorted back to front, so
+	// see-through sprites can hide sprites behind them that are drawn later.
+	Opacity float32
+	// Optional. Extra uniforms for the shader (see material.go)
+	Material *Material
+	// only set for batch sprites (see batch.go)
+	batch *instanceBatch
+	// the draw queue's group when it was added (see transition.go)
+	drawGroup atomic.Uint32
 
 	// Sprites cannot be deleted in isolation because the shaderId, textureId, or VAO might be used
 	// by some other object. So this is marked for lazy deletion (do not draw), to be deleted
@@ -33,6 +49,14 @@ func (s *Sprite) GetTextureId() uint32 {
 	return s.Tex.textureId
 }
 
+// The sprite's texture and any extra textures its material uses
+func (s *Sprite) GetTextureIds() []uint32 {
+	if s.Material == nil {
+		return []uint32{s.Tex.textureId}
+	}
+	return append([]uint32{s.Tex.textureId}, s.Material.TextureIds()...)
+}
+
 func (s *Sprite) GetVAO() uint32 {
 	return s.vao
 }
@@ -70,10 +94,14 @@ type SpriteInitParams struct {
 	// default is 0.0 & 0.0. This means the object is 0x0. Please make this 1.0
 	StretchX float32
 	StretchY float32
+	// Optional. Can be shared between sprites to change all of them at once
+	Material *Material
 }
 
 type drawingQueue struct {
 	queue *list.List
+	// what sprites added to the queue are tagged with (see transition.go)
+	group atomic.Uint32
 	mu    sync.Mutex
 }
 
@@ -119,6 +147,9 @@ func CreateSprite(initParams *SpriteInitParams) (*Sprite, error) {
 	sprite.ScreenCenter = initParams.ScreenCenter
 	// default origin of sprite: upper left (0, 0)
 	sprite.SpriteCenter = initParams.SpriteCenter
+	sprite.Tint = Color{R: 1.0, G: 1.0, B: 1.0, A: 1.0}
+	sprite.Opacity = 1.0
+	sprite.Material = initParams.Material
 
 	return &sprite, nil
 }
@@ -149,6 +180,7 @@ func (dq *drawingQueue) AddToQueue(w weak.Pointer[Sprite]) {
 		listElem = nextListElem
 	}
 
+	w.Value().drawGroup.Store(dq.group.Load())
 	dq.queue.PushFront(w)
 
 	if dq.queue.Len() > 100 {
@@ -199,6 +231,11 @@ func (dq *drawingQueue) RemoveFromQueue(w weak.Pointer[Sprite]) (ok bool) {
 
 // should always be called in the main thread (glfw & gl)
 func (dq *drawingQueue) Draw() {
+	deleteQueuedBatches()
+	// the outgoing scene's sprites are drawn after, into a frame of their own
+	outgoingGroup, transitioning := GetPostProcessor().drawingOutgoing()
+	var outgoing []*Sprite
+
 	listElem := dq.queue.Front()
 	for listElem != nil {
 		nextListElem := listElem.Next()
@@ -212,28 +249,59 @@ func (dq *drawingQueue) Draw() {
 		if strongSprite == nil || strongSprite.IsNil() {
 			logger.LOG.Debug().Msg("Removed a nil draw Object (object got Gc'd)")
 			dq.queue.Remove(listElem)
+		} else if transitioning && strongSprite.drawGroup.Load() == outgoingGroup {
+			outgoing = append(outgoing, strongSprite)
 		} else {
-			// The graphics card thinks the sprite center is the bottom left.
-			// The minus on the Y is because the Y coordinate direction is flipped in openGL,
-			// meaning its a right-handed system. So the bottom left is 0, 0
-			openGlScreenCenter := ScreenCoords{
-				X: strongSprite.ScreenCenter.X - strongSprite.SpriteCenter.X*strongSprite.Tex.DimX,
-				Y: strongSprite.ScreenCenter.Y - strongSprite.SpriteCenter.Y*strongSprite.Tex.DimY,
-			}
-
-			gl.UseProgram(strongSprite.shaderId)
-			setTransform(strongSprite.shaderId, openGlScreenCenter)
-			setScale(strongSprite.shaderId, strongSprite.Tex.DimX, strongSprite.Tex.DimY)
-
-			gl.ActiveTexture(gl.TEXTURE0)
-			gl.BindTexture(gl.TEXTURE_2D, strongSprite.Tex.textureId)
-
-			gl.BindVertexArray(strongSprite.vao)
-			gl.DrawArrays(gl.TRIANGLES, 0, 6)
-			gl.BindVertexArray(0)
+			strongSprite.draw()
 		}
 		listElem = nextListElem
 	}
+	drawPrimitives()
+	if transitioning {
+		GetPostProcessor().drawOutgoing(outgoing)
+	}
+}
+
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func (s *Sprite) draw() {
+	if s.batch != nil {
+		s.drawBatch()
+		return
+	}
+	gl.UseProgram(s.shaderId)
+	setTransform(s.shaderId, s)
+	setScale(s.shaderId, s.Tex.DimX, s.Tex.DimY)
+	setColor(s.shaderId, s.Tint, s.Opacity)
+	applyMaterial(s.shaderId, s.Material)
+
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, s.Tex.textureId)
+
+	gl.BindVertexArray(s.vao)
+	gl.DrawArrays(gl.TRIANGLES, 0, 6)
+	gl.BindVertexArray(0)
+}
+
+// What sprites added to the queue from now on are tagged with. During a scene transition, the
+// outgoing scene's sprites are the ones with its group.
+// thread safe
+func (dq *drawingQueue) Group() uint32 {
+	return dq.group.Load()
+}
+
+// thread safe
+func (dq *drawingQueue) SetGroup(group uint32) {
+	dq.group.Store(group)
+}
+
+// Retags sprites already in the queue (ex. global sprites, so they stay in the live frame)
+// thread safe
+func (dq *drawingQueue) MoveToGroup(group uint32, moved ...*Sprite) {
+	for _, sprite := range moved {
+		if sprite != nil {
+			sprite.drawGroup.Store(group)
+		}
+	}
 }
 
 func (s *Sprite) SpriteCoordsToScreenCoords(spriteCoords SpriteCoords) ScreenCoords {
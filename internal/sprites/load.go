@@ -1,6 +1,7 @@
 package sprites
 
 import (
+	"fmt"
 	"image"
 	"image/draw"
 	_ "image/png"
@@ -10,48 +11,31 @@ import (
 	"github.com/PatrickKoch07/game-proj/internal/logger"
 )
 
-func loadShaderCode(fileName string) ([]byte, error) {
-	file, err := os.Open(filepath.Join(".", "assets", "shaders", fileName))
-	if err != nil {
-		logger.LOG.Fatal().Msgf("Opening shader file %v failed", fileName)
-		return make([]byte, 0), err
-	}
-	defer file.Close()
-
-	// seems like a safe limit given our current shaders
-	data := make([]byte, 1000)
-	count, err := file.Read(data)
+func loadTextures(relativePath string) (*image.RGBA, error) {
+	rgba_image, err := decodeTextureFile(relativePath)
 	if err != nil {
-		logger.LOG.Fatal().Msgf("Loading from shader file %v failed", fileName)
-		return make([]byte, 0), err
-	}
-	if count > 900 {
-		logger.LOG.Warn().Msgf("Shader file (%v) is getting long. (%v/1000 char)", fileName, count)
+		logger.LOG.Fatal().Err(err).Msgf("Opening texture file %v failed", relativePath)
+		return nil, err
 	}
-
-	shaderCode := data[:count]
-
-	return shaderCode, nil
+	return rgba_image, nil
 }
 
-func loadTextures(relativePath string) (*image.RGBA, error) {
+// Same as loadTextures, but leaves it to the caller to decide if an error is fatal
+func decodeTextureFile(relativePath string) (*image.RGBA, error) {
 	fileReader, err := os.Open(filepath.Join(".", "assets", "sprites", relativePath))
 	if err != nil {
-		logger.LOG.Fatal().Msgf("Opening texture file %v failed", relativePath)
 		return nil, err
 	}
 	defer fileReader.Close()
 
 	raw_image, _, err := image.Decode(fileReader)
 	if err != nil {
-		logger.LOG.Fatal().Msgf("Opening texture file %v failed", relativePath)
 		return nil, err
 	}
 	b := raw_image.Bounds()
 
 	if sz := (b.Max.Y - b.Min.Y) * (b.Max.X - b.Min.X); sz > (screenWidth * screenHeight) {
-		logger.LOG.Fatal().Msgf("File to load has too much data: %v bytes", sz)
-		return nil, err
+		return nil, fmt.Errorf("file to load has too much data: %v bytes", sz)
 	}
 
 	rgba_image := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
@@ -0,0 +1,205 @@
+package sprites
+
+// Per sprite uniform values, so effects (tinting, flashing, dissolving, ...) only need a shader
+// and never a new go code path.
+// Uniforms are stored on the shader program, which is shared by every sprite using it. So any
+// uniform a material set is reset to 0 (the value it had after linking) before drawing a sprite
+// whose material doesn't set it. Write effect shaders so 0 means "effect off".
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/PatrickKoch07/game-proj/internal/utils"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// Texture unit 0 is always the sprite's own texture (uniform "tex")
+const firstMaterialTextureUnit int32 = 1
+
+// 16 is the minimum number of fragment texture units guaranteed by openGL
+const maxMaterialTextures int = 15
+
+type Color struct {
+	R float32
+	G float32
+	B float32
+	A float32
+}
+
+type uniformValue struct {
+	// number of used values (float = 1, vec2 = 2, ...)
+	size   int
+	values [4]float32
+}
+
+type materialTexture struct {
+	uniformName string
+	textureId   uint32
+}
+
+type Material struct {
+	uniforms map[string]uniformValue
+	textures []materialTexture
+	// values can be changed by game objects while the main thread draws
+	mu sync.Mutex
+}
+
+func NewMaterial() *Material {
+	m := new(Material)
+	m.uniforms = make(map[string]uniformValue)
+	return m
+}
+
+// thread safe by locking
+func (m *Material) SetFloat(uniformName string, x float32) {
+	m.set(uniformName, uniformValue{size: 1, values: [4]float32{x}})
+}
+
+// thread safe by locking
+func (m *Material) SetVec2(uniformName string, x, y float32) {
+	m.set(uniformName, uniformValue{size: 2, values: [4]float32{x, y}})
+}
+
+// thread safe by locking
+func (m *Material) SetVec3(uniformName string, x, y, z float32) {
+	m.set(uniformName, uniformValue{size: 3, values: [4]float32{x, y, z}})
+}
+
+// thread safe by locking
+func (m *Material) SetVec4(uniformName string, x, y, z, w float32) {
+	m.set(uniformName, uniformValue{size: 4, values: [4]float32{x, y, z, w}})
+}
+
+// Same as a vec4 (r, g, b, a) in the shader. thread safe by locking
+func (m *Material) SetColor(uniformName string, color Color) {
+	m.SetVec4(uniformName, color.R, color.G, color.B, color.A)
+}
+
+// The uniform goes back to 0 for this sprite. thread safe by locking
+func (m *Material) Unset(uniformName string) {
+	m.mu.Lock()
+	delete(m.uniforms, uniformName)
+	m.mu.Unlock()
+}
+
+func (m *Material) set(uniformName string, value uniformValue) {
+	m.mu.Lock()
+	m.uniforms[uniformName] = value
+	m.mu.Unlock()
+}
+
+// Binds an extra texture (ex. a noise texture for dissolving) to a sampler2D uniform.
+// Should never be called concurrently because it *COULD* use glfw/gl
+func (m *Material) SetTexture(uniformName string, textureRelPath string) error {
+	tex, err := getTexture(textureRelPath, TexCoordOneSpritePerImg)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, materialTex := range m.textures {
+		if materialTex.uniformName == uniformName {
+			m.textures[i].textureId = tex.textureId
+			return nil
+		}
+	}
+	if len(m.textures) >= maxMaterialTextures {
+		return fmt.Errorf("materials can have at most %v extra textures", maxMaterialTextures)
+	}
+	m.textures = append(m.textures, materialTexture{uniformName: uniformName, textureId: tex.textureId})
+	return nil
+}
+
+// Ids of the extra textures, so they aren't unloaded while still in use.
+func (m *Material) TextureIds() []uint32 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	textureIds := make([]uint32, len(m.textures))
+	for i, materialTex := range m.textures {
+		textureIds[i] = materialTex.textureId
+	}
+	return textureIds
+}
+
+// Uniforms set by the last material applied to each shader program, with their size so they can
+// be reset with a matching type. 0 is used for sampler2Ds. (main thread only)
+var setByMaterial map[uint32]map[string]int = make(map[uint32]map[string]int)
+
+// Sets the material's uniforms on the (already in use) program and resets ones left over from the
+// previous sprite's material. m can be nil.
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func applyMaterial(shaderId uint32, m *Material) {
+	var uniforms map[string]uniformValue
+	var textures []materialTexture
+	if m != nil {
+		m.mu.Lock()
+		uniforms = maps.Clone(m.uniforms)
+		textures = slices.Clone(m.textures)
+		m.mu.Unlock()
+	}
+
+	nowSet := make(map[string]int, len(uniforms)+len(textures))
+	for uniformName, value := range uniforms {
+		setUniform(shaderId, uniformName, value)
+		nowSet[uniformName] = value.size
+	}
+	for i, materialTex := range textures {
+		textureUnit := firstMaterialTextureUnit + int32(i)
+		gl.ActiveTexture(gl.TEXTURE0 + uint32(textureUnit))
+		gl.BindTexture(gl.TEXTURE_2D, materialTex.textureId)
+		gl.Uniform1i(uniformLocation(shaderId, materialTex.uniformName), textureUnit)
+		nowSet[materialTex.uniformName] = 0
+	}
+	if len(textures) != 0 {
+		gl.ActiveTexture(gl.TEXTURE0)
+	}
+
+	for uniformName, size := range setByMaterial[shaderId] {
+		if _, ok := nowSet[uniformName]; ok {
+			continue
+		}
+		if size == 0 {
+			// back to the sprite's own texture
+			gl.Uniform1i(uniformLocation(shaderId, uniformName), 0)
+			continue
+		}
+		setUniform(shaderId, uniformName, uniformValue{size: size})
+	}
+
+	if len(nowSet) == 0 {
+		delete(setByMaterial, shaderId)
+	} else {
+		setByMaterial[shaderId] = nowSet
+	}
+}
+
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func uniformLocation(shaderId uint32, uniformName string) int32 {
+	// go strings aren't null terminated
+	nullTerminated := uniformName + "\x00"
+	return gl.GetUniformLocation(shaderId, utils.StringToUint8(&nullTerminated))
+}
+
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func setUniform(shaderId uint32, uniformName string, value uniformValue) {
+	location := uniformLocation(shaderId, uniformName)
+	if location == -1 {
+		// not in the shader (or optimized out), nothing to do
+		return
+	}
+	switch value.size {
+	case 1:
+		gl.Uniform1f(location, value.values[0])
+	case 2:
+		gl.Uniform2f(location, value.values[0], value.values[1])
+	case 3:
+		gl.Uniform3f(location, value.values[0], value.values[1], value.values[2])
+	case 4:
+		gl.Uniform4f(location, value.values[0], value.values[1], value.values[2], value.values[3])
+	}
+}
@@ -0,0 +1,363 @@
+package sprites
+
+// Package level state held by private singleton initialized at program start.
+// When any post process pass is enabled, the draw queue is drawn into an offscreen framebuffer.
+// During a scene transition the outgoing scene's sprites get one of their own (see transition.go).
+// Each enabled pass then draws the previous result through its fragment shader onto a full screen
+// quad, and the last one draws to the window. With nothing enabled, the draw queue is drawn
+// straight to the window like before.
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// Vertex shader shared by every pass, it only places the full screen quad
+const postProcessVertexShader string = "postProcess.vs"
+
+// The fade pass is always the very last pass (see SetFade)
+const fadePassName string = "fade"
+
+// The transition pass is always the very first pass (see SetTransition)
+const transitionPassName string = "transition"
+
+// Texture unit the transition pass reads the outgoing frame from (the live frame is unit 0)
+const outgoingTextureUnit int32 = 1
+
+type PostProcessPass struct {
+	Name   string
+	shader uint32
+	// Extra uniforms for the pass (ex. vignette strength). The uniforms "tex" (the previous
+	// result), "screenSize" and "time" are always set.
+	Material *Material
+	enabled  atomic.Bool
+}
+
+// thread safe
+func (p *PostProcessPass) SetEnabled(enabled bool) {
+	p.enabled.Store(enabled)
+}
+
+// thread safe
+func (p *PostProcessPass) IsEnabled() bool {
+	return p.enabled.Load()
+}
+
+type renderTarget struct {
+	fbo       uint32
+	textureId uint32
+}
+
+type postProcessor struct {
+	// in order they are applied
+	passes         []*PostProcessPass
+	fadePass       *PostProcessPass
+	transitionPass *PostProcessPass
+
+	// what the draw queue draws into (has a depth buffer)
+	sceneTarget renderTarget
+	depthBuffer uint32
+	// passes ping pong between these two
+	passTargets [2]renderTarget
+	// the outgoing scene's frame, for the transition pass to blend from (shares the depth buffer)
+	outgoingTarget renderTarget
+	// the draw group drawn into outgoingTarget, while transitioning (see StartTransition)
+	outgoingGroup atomic.Uint32
+	transitioning atomic.Bool
+	quadVAO       uint32
+	initialized   bool
+	// true between BeginFrame and EndFrame if the frame is going through the passes
+	capturing bool
+	startTime time.Time
+	mu        sync.Mutex
+}
+
+var activePostProcessor *postProcessor
+var oncePostProcessor sync.Once
+
+func initPostProcessor() {
+	logger.LOG.Info().Msg("Creating new post processor")
+	activePostProcessor = new(postProcessor)
+	activePostProcessor.startTime = time.Now()
+}
+
+func GetPostProcessor() *postProcessor {
+	oncePostProcessor.Do(initPostProcessor)
+	return activePostProcessor
+}
+
+// Adds a pass to the end of the chain (before the fade). The pass starts enabled.
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func (pp *postProcessor) AddPass(name string, fragmentPath string) (*PostProcessPass, error) {
+	if _, ok := pp.GetPass(name); ok || name == fadePassName || name == transitionPassName {
+		return nil, errors.New("post process pass already exists: " + name)
+	}
+	pass, err := newPostProcessPass(name, fragmentPath)
+	if err != nil {
+		return nil, err
+	}
+	pp.mu.Lock()
+	pp.passes = append(pp.passes, pass)
+	pp.mu.Unlock()
+	return pass, nil
+}
+
+// thread safe by locking
+func (pp *postProcessor) RemovePass(name string) {
+	pp.mu.Lock()
+	pp.passes = slices.DeleteFunc(pp.passes, func(p *PostProcessPass) bool { return p.Name == name })
+	pp.mu.Unlock()
+}
+
+// thread safe by locking
+func (pp *postProcessor) GetPass(name string) (*PostProcessPass, bool) {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	index := slices.IndexFunc(pp.passes, func(p *PostProcessPass) bool { return p.Name == name })
+	if index == -1 {
+		return nil, false
+	}
+	return pp.passes[index], true
+}
+
+// Fades the whole screen to the color. amount goes from 0.0 (no fade) to 1.0 (only the color).
+// thread safe
+func (pp *postProcessor) SetFade(color Color, amount float32) {
+	fadePass := pp.getFadePass()
+	if fadePass == nil {
+		return
+	}
+	fadePass.Material.SetColor("fadeColor", color)
+	fadePass.Material.SetFloat("fadeAmount", amount)
+	fadePass.SetEnabled(amount > 0.0)
+}
+
+func (pp *postProcessor) getFadePass() *PostProcessPass {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	return pp.fadePass
+}
+
+func (pp *postProcessor) getTransitionPass() *PostProcessPass {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	return pp.transitionPass
+}
+
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func newPostProcessPass(name string, fragmentPath string) (*PostProcessPass, error) {
+	shaderId, err := getShader(
+		ShaderFiles{VertexPath: postProcessVertexShader, FragmentPath: fragmentPath},
+	)
+	if err != nil {
+		return nil, err
+	}
+	pass := &PostProcessPass{Name: name, shader: shaderId, Material: NewMaterial()}
+	pass.enabled.Store(true)
+	return pass, nil
+}
+
+// Call before clearing & drawing the frame.
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func (pp *postProcessor) BeginFrame() {
+	if !pp.initialized {
+		pp.initialize()
+	}
+	pp.capturing = len(pp.enabledPasses()) != 0
+	if pp.capturing {
+		gl.BindFramebuffer(gl.FRAMEBUFFER, pp.sceneTarget.fbo)
+	}
+}
+
+// Call after drawing the frame, before swapping buffers.
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func (pp *postProcessor) EndFrame() {
+	if !pp.capturing {
+		return
+	}
+	pp.capturing = false
+
+	passes := pp.enabledPasses()
+	if len(passes) == 0 {
+		// the passes were turned off during the frame
+		copyFrame(pp.sceneTarget.fbo, 0)
+		return
+	}
+
+	gl.Disable(gl.DEPTH_TEST)
+	defer gl.Enable(gl.DEPTH_TEST)
+	gl.BindVertexArray(pp.quadVAO)
+	defer gl.BindVertexArray(0)
+
+	source := pp.sceneTarget.textureId
+	elapsed := float32(time.Since(pp.startTime).Seconds())
+	for i, pass := range passes {
+		if i == len(passes)-1 {
+			gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+		} else {
+			gl.BindFramebuffer(gl.FRAMEBUFFER, pp.passTargets[i%2].fbo)
+		}
+		gl.Clear(gl.COLOR_BUFFER_BIT)
+
+		gl.UseProgram(pass.shader)
+		gl.ActiveTexture(gl.TEXTURE0)
+		gl.BindTexture(gl.TEXTURE_2D, source)
+		setUniform(pass.shader, "screenSize", uniformValue{
+			size: 2, values: [4]float32{float32(screenWidth), float32(screenHeight)},
+		})
+		setUniform(pass.shader, "time", uniformValue{size: 1, values: [4]float32{elapsed}})
+		applyMaterial(pass.shader, pass.Material)
+		if pass == pp.transitionPass {
+			pp.bindOutgoing(pass.shader)
+		}
+		gl.DrawArrays(gl.TRIANGLES, 0, 6)
+
+		source = pp.passTargets[i%2].textureId
+	}
+}
+
+func (pp *postProcessor) enabledPasses() []*PostProcessPass {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	passes := make([]*PostProcessPass, 0, len(pp.passes)+2)
+	if pp.transitionPass != nil && pp.transitionPass.IsEnabled() {
+		passes = append(passes, pp.transitionPass)
+	}
+	for _, pass := range pp.passes {
+		if pass.IsEnabled() {
+			passes = append(passes, pass)
+		}
+	}
+	if pp.fadePass != nil && pp.fadePass.IsEnabled() {
+		passes = append(passes, pp.fadePass)
+	}
+	return passes
+}
+
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func (pp *postProcessor) initialize() {
+	logger.LOG.Info().Msg("Creating post process framebuffers")
+	pp.initialized = true
+
+	pp.sceneTarget = makeRenderTarget()
+	gl.BindFramebuffer(gl.FRAMEBUFFER, pp.sceneTarget.fbo)
+	gl.GenRenderbuffers(1, &pp.depthBuffer)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, pp.depthBuffer)
+	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH_COMPONENT24, int32(screenWidth), int32(screenHeight))
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, pp.depthBuffer)
+	if gl.CheckFramebufferStatus(gl.FRAMEBUFFER) != gl.FRAMEBUFFER_COMPLETE {
+		logger.LOG.Error().Msg("Post process scene framebuffer is incomplete")
+	}
+	gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
+
+	for i := range pp.passTargets {
+		pp.passTargets[i] = makeRenderTarget()
+	}
+	pp.outgoingTarget = makeRenderTarget()
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, pp.depthBuffer)
+	if gl.CheckFramebufferStatus(gl.FRAMEBUFFER) != gl.FRAMEBUFFER_COMPLETE {
+		logger.LOG.Error().Msg("Post process outgoing framebuffer is incomplete")
+	}
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+
+	pp.quadVAO = makeScreenQuadVAO()
+
+	fadePass, err := newPostProcessPass(fadePassName, "postProcess/fade.fs")
+	if err != nil {
+		logger.LOG.Error().Err(err).Msg("Fade post process pass failed to be made")
+		return
+	}
+	fadePass.SetEnabled(false)
+	pp.mu.Lock()
+	pp.fadePass = fadePass
+	pp.mu.Unlock()
+
+	transitionPass, err := newPostProcessPass(transitionPassName, "postProcess/transition.fs")
+	if err != nil {
+		logger.LOG.Error().Err(err).Msg("Transition post process pass failed to be made")
+		return
+	}
+	transitionPass.SetEnabled(false)
+	pp.mu.Lock()
+	pp.transitionPass = transitionPass
+	pp.mu.Unlock()
+}
+
+// Copies the color of one framebuffer to another (0 is the window). Leaves the window bound.
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func copyFrame(fromFBO uint32, toFBO uint32) {
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, fromFBO)
+	gl.BindFramebuffer(gl.DRAW_FRAMEBUFFER, toFBO)
+	gl.BlitFramebuffer(
+		0, 0, int32(screenWidth), int32(screenHeight),
+		0, 0, int32(screenWidth), int32(screenHeight),
+		gl.COLOR_BUFFER_BIT,
+		gl.NEAREST,
+	)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+}
+
+// Leaves the framebuffer bound.
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func makeRenderTarget() renderTarget {
+	target := renderTarget{}
+	gl.GenFramebuffers(1, &target.fbo)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, target.fbo)
+
+	gl.GenTextures(1, &target.textureId)
+	gl.BindTexture(gl.TEXTURE_2D, target.textureId)
+	gl.TexImage2D(
+		gl.TEXTURE_2D,
+		0,
+		gl.RGBA,
+		int32(screenWidth),
+		int32(screenHeight),
+		0,
+		gl.RGBA,
+		gl.UNSIGNED_BYTE,
+		nil,
+	)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, target.textureId, 0)
+	return target
+}
+
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func makeScreenQuadVAO() uint32 {
+	var VAO, VBO uint32
+	// Position X Y (-1 to 1), Texture X Y (0 to 1)
+	var vertexCoords [24]float32 = [24]float32{
+		-1.0, -1.0, 0.0, 0.0,
+		-1.0, 1.0, 0.0, 1.0,
+		1.0, 1.0, 1.0, 1.0,
+
+		-1.0, -1.0, 0.0, 0.0,
+		1.0, 1.0, 1.0, 1.0,
+		1.0, -1.0, 1.0, 0.0,
+	}
+	gl.GenVertexArrays(1, &VAO)
+	gl.GenBuffers(1, &VBO)
+
+	gl.BindVertexArray(VAO)
+	gl.BindBuffer(gl.ARRAY_BUFFER, VBO)
+
+	gl.BufferData(gl.ARRAY_BUFFER, 24*4, unsafe.Pointer(&vertexCoords[0]), gl.STATIC_DRAW)
+	gl.VertexAttribPointer(0, 4, gl.FLOAT, false, 4*4, nil)
+	gl.EnableVertexAttribArray(0)
+
+	gl.BindVertexArray(0)
+	return VAO
+}
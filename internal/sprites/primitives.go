@@ -0,0 +1,117 @@
+package sprites
+
+// Package level state held by private singleton initialized at program start.
+// Untextured, colored triangles straight from screen coordinates, batched into one draw per frame
+// (see the primitives package for lines, rects and circles built out of them). Anything can queue
+// triangles during a frame, they are drawn after every sprite in the order they were queued and
+// then forgotten, so call every frame to keep them on screen.
+
+import (
+	"sync"
+	"unsafe"
+
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// x, y, depth, r, g, b, a
+const floatsPerPrimitiveVertex int = 7
+
+var primitiveShaderFiles = ShaderFiles{VertexPath: "primitive.vs", FragmentPath: "primitive.fs"}
+
+type PrimitiveVertex struct {
+	Position ScreenCoords
+	// same range as the sprite shaders' depth (-1.0 in front to 1.0 behind). Primitives don't hide
+	// each other, only sprites in front of them do
+	Depth float32
+	Color Color
+}
+
+type primitiveBatch struct {
+	// this frame's vertices, three per triangle
+	vertices []float32
+	mu       sync.Mutex
+
+	// gl side, made on the first draw
+	vao      uint32
+	vbo      uint32
+	capacity int
+}
+
+var activePrimitives *primitiveBatch
+var oncePrimitives sync.Once
+
+func getPrimitives() *primitiveBatch {
+	oncePrimitives.Do(func() { activePrimitives = new(primitiveBatch) })
+	return activePrimitives
+}
+
+// Queues triangles (every three vertices is one) for this frame.
+// thread safe by locking
+func DrawTriangles(vertices []PrimitiveVertex) {
+	if len(vertices)%3 != 0 {
+		logger.LOG.Warn().Msgf("Primitive vertices not in threes (%v), dropping them", len(vertices))
+		return
+	}
+	p := getPrimitives()
+	p.mu.Lock()
+	for _, v := range vertices {
+		p.vertices = append(p.vertices,
+			v.Position.X, v.Position.Y, v.Depth, v.Color.R, v.Color.G, v.Color.B, v.Color.A,
+		)
+	}
+	p.mu.Unlock()
+}
+
+// Draws and clears everything queued this frame.
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func drawPrimitives() {
+	p := getPrimitives()
+	p.mu.Lock()
+	vertices := p.vertices
+	p.vertices = make([]float32, 0, len(vertices))
+	p.mu.Unlock()
+	if len(vertices) == 0 {
+		return
+	}
+
+	shaderId, err := getShader(primitiveShaderFiles)
+	if err != nil {
+		logger.LOG.Error().Err(err).Msg("Can't draw primitives without their shader")
+		return
+	}
+	p.upload(vertices)
+
+	// tested against the sprites, but not against each other (drawn in order instead)
+	gl.DepthMask(false)
+	defer gl.DepthMask(true)
+	gl.UseProgram(shaderId)
+	gl.BindVertexArray(p.vao)
+	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(vertices)/floatsPerPrimitiveVertex))
+	gl.BindVertexArray(0)
+}
+
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func (p *primitiveBatch) upload(vertices []float32) {
+	if p.vao == 0 {
+		gl.GenVertexArrays(1, &p.vao)
+		gl.GenBuffers(1, &p.vbo)
+		gl.BindVertexArray(p.vao)
+		gl.BindBuffer(gl.ARRAY_BUFFER, p.vbo)
+		stride := int32(floatsPerPrimitiveVertex * 4)
+		gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, stride, 0)
+		gl.EnableVertexAttribArray(0)
+		gl.VertexAttribPointerWithOffset(1, 4, gl.FLOAT, false, stride, 3*4)
+		gl.EnableVertexAttribArray(1)
+		gl.BindVertexArray(0)
+	}
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, p.vbo)
+	defer gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+	if len(vertices) > p.capacity {
+		// grow to double so a growing frame doesn't reallocate every time
+		p.capacity = 2 * len(vertices)
+		gl.BufferData(gl.ARRAY_BUFFER, p.capacity*4, nil, gl.DYNAMIC_DRAW)
+	}
+	gl.BufferSubData(gl.ARRAY_BUFFER, 0, len(vertices)*4, unsafe.Pointer(&vertices[0]))
+}
@@ -0,0 +1,124 @@
+package sprites
+
+// Hot reloading of graphics objects that are already on the graphics card. Everything is
+// reloaded into the ids it already has so sprites holding those ids pick up the change.
+
+import (
+	"errors"
+	"runtime"
+	"slices"
+	"unsafe"
+
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// Recompiles every active shader program using this file (vertex, fragment or #include). If
+// anything fails to compile or link, the old program is kept as is.
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func ReloadShaderFile(fileName string) error {
+	var errs []error
+	for key, shader := range getActiveGraphicsObjects().CurrentlyActiveShaders {
+		if !slices.Contains(shader.dependencies, fileName) {
+			continue
+		}
+		dependencies, err := relinkShader(shader.shaderId, shader.files)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		// includes might have been added or removed
+		shader.dependencies = dependencies
+		getActiveGraphicsObjects().CurrentlyActiveShaders[key] = shader
+		logger.LOG.Info().Msgf(
+			"Reloaded shader %v (%v + %v)",
+			shader.shaderId,
+			shader.files.VertexPath,
+			shader.files.FragmentPath,
+		)
+	}
+	return errors.Join(errs...)
+}
+
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func relinkShader(shaderId uint32, shaderFiles ShaderFiles) ([]string, error) {
+	// build a scratch program first, a failed link would leave the live program unusable
+	testId, dependencies, err := buildShaderProgram(shaderFiles)
+	if err != nil {
+		return nil, err
+	}
+	defer gl.DeleteProgram(testId)
+
+	var newCount int32
+	newShaders := make([]uint32, 2)
+	gl.GetAttachedShaders(testId, int32(len(newShaders)), &newCount, &newShaders[0])
+
+	// swap the shaders of the live program. The old ones were flagged for deletion when they were
+	// linked, so detaching them frees them.
+	var oldCount int32
+	oldShaders := make([]uint32, 2)
+	gl.GetAttachedShaders(shaderId, int32(len(oldShaders)), &oldCount, &oldShaders[0])
+	for _, oldShader := range oldShaders[:oldCount] {
+		gl.DetachShader(shaderId, oldShader)
+	}
+	for _, newShader := range newShaders[:newCount] {
+		gl.AttachShader(shaderId, newShader)
+	}
+	gl.LinkProgram(shaderId)
+
+	initShaderUniforms(shaderId)
+	return dependencies, nil
+}
+
+// Re-decodes the texture and uploads it into the texture id it already has. Does nothing if the
+// texture isn't loaded.
+// Sprites keep the dimensions they were made with, so a texture changing size will look stretched
+// until the sprite is made again.
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func ReloadTexture(relativePath string) error {
+	tex, ok := getActiveGraphicsObjects().CurrentlyActiveTextures[relativePath]
+	if !ok {
+		return nil
+	}
+
+	img, err := decodeTextureFile(relativePath)
+	if err != nil {
+		return err
+	}
+	if len(img.Pix) == 0 {
+		return errors.New("empty texture")
+	}
+	p := runtime.Pinner{}
+	defer p.Unpin()
+	p.Pin(&img.Pix[0])
+
+	gl.BindTexture(gl.TEXTURE_2D, tex.textureId)
+	defer gl.BindTexture(gl.TEXTURE_2D, 0)
+	gl.TexImage2D(
+		gl.TEXTURE_2D,
+		0,
+		gl.RGBA,
+		int32(img.Bounds().Dx()),
+		int32(img.Bounds().Dy()),
+		0,
+		gl.RGBA,
+		gl.UNSIGNED_BYTE,
+		unsafe.Pointer(&img.Pix[0]),
+	)
+
+	newDimX := float32(img.Bounds().Dx())
+	newDimY := float32(img.Bounds().Dy())
+	if newDimX != tex.DimX || newDimY != tex.DimY {
+		logger.LOG.Warn().Msgf(
+			"Texture %v changed size (%vx%v -> %vx%v). Existing sprites keep the old size.",
+			relativePath, tex.DimX, tex.DimY, newDimX, newDimY,
+		)
+		tex.DimX = newDimX
+		tex.DimY = newDimY
+		getActiveGraphicsObjects().CurrentlyActiveTextures[relativePath] = tex
+	}
+
+	logger.LOG.Info().Msgf("Reloaded texture %v (%v)", tex.textureId, relativePath)
+	return nil
+}
@@ -4,8 +4,12 @@ package sprites
 // Holds the currently active graphics objects so things can be properly deleted & not duplicated.
 
 import (
-	"errors"
+	"fmt"
+	"image"
+	"math"
 	"runtime"
+	"slices"
+	"strings"
 	"sync"
 	"unsafe"
 
@@ -30,6 +34,16 @@ func SetScreenSize(sWidth int, sHeight int) {
 type ShaderFiles struct {
 	VertexPath   string
 	FragmentPath string
+	// Optional. Inserted as #define NAME VALUE into both shaders, right after the #version line.
+	// Same files with different defines are different shaders.
+	Defines []ShaderDefine
+}
+
+type activeShader struct {
+	shaderId uint32
+	files    ShaderFiles
+	// every file that went into the shader, including #includes (for hot reloading)
+	dependencies []string
 }
 
 type texture struct {
@@ -39,14 +53,14 @@ type texture struct {
 }
 
 type graphicsObjects struct {
-	CurrentlyActiveShaders  map[string]uint32
+	CurrentlyActiveShaders  map[string]activeShader
 	CurrentlyActiveTextures map[string]texture
 	CurrentlyActiveVAOs     map[string]uint32
 }
 
 func initActiveGraphicsObjs() {
 	activeGraphicsObjects = new(graphicsObjects)
-	activeGraphicsObjects.CurrentlyActiveShaders = make(map[string]uint32)
+	activeGraphicsObjects.CurrentlyActiveShaders = make(map[string]activeShader)
 	// 32 is the max set by openGL
 	activeGraphicsObjects.CurrentlyActiveTextures = make(map[string]texture, 32)
 	activeGraphicsObjects.CurrentlyActiveVAOs = make(map[string]uint32)
@@ -62,8 +76,9 @@ func DeleteShaderById(shaderId uint32) bool {
 	// delete from active objs and the graphics card
 	activeGraphicsObjs := getActiveGraphicsObjects()
 	for key, val := range activeGraphicsObjs.CurrentlyActiveShaders {
-		if shaderId == val {
+		if shaderId == val.shaderId {
 			delete(activeGraphicsObjs.CurrentlyActiveShaders, key)
+			delete(setByMaterial, shaderId)
 			gl.DeleteProgram(shaderId)
 			return true
 		}
@@ -125,17 +140,11 @@ func getTexture(
 func getShader(
 	shaderFiles ShaderFiles,
 ) (uint32, error) {
-	vShaderFileName := shaderFiles.VertexPath
-	fShaderFileName := shaderFiles.FragmentPath
-	shaderId, ok := getActiveGraphicsObjects().CurrentlyActiveShaders[vShaderFileName+fShaderFileName]
+	shader, ok := getActiveGraphicsObjects().CurrentlyActiveShaders[shaderFiles.cacheKey()]
 	if !ok {
-		var err error
-		shaderId, err = makeShader(shaderFiles)
-		if err != nil {
-			return shaderId, err
-		}
+		return makeShader(shaderFiles)
 	}
-	return shaderId, nil
+	return shader.shaderId, nil
 }
 
 func getVAO(textureCoords [12]float32) (uint32, error) {
@@ -148,16 +157,41 @@ func getVAO(textureCoords [12]float32) (uint32, error) {
 	return vao, nil
 }
 
+// Places the (already scaled) sprite: mirrors it, rotates it around SpriteCenter and moves it to
+// ScreenCenter.
 // NOT THREAD SAFE (never will be b/c glfw & gl)
 func setTransform(
-	shaderId uint32, screenCoords ScreenCoords,
+	shaderId uint32, s *Sprite,
 ) {
 	gl.UseProgram(shaderId)
-	// transform := mgl32.Translate3D(screenX, screenY/float32(screenHeight), screenY)
+	// The graphics card thinks the sprite center is the bottom left.
+	// The minus on the Y is because the Y coordinate direction is flipped in openGL,
+	// meaning its a right-handed system. So the bottom left is 0, 0
+	pivotX := s.SpriteCenter.X * s.Tex.DimX
+	pivotY := s.SpriteCenter.Y * s.Tex.DimY
+
+	// mirroring happens on the scaled quad (0 to DimX), so flip it back into the same spot after
+	var flipX, flipY float32 = 1.0, 1.0
+	var flipOffsetX, flipOffsetY float32 = 0.0, 0.0
+	if s.FlipX {
+		flipX = -1.0
+		flipOffsetX = s.Tex.DimX
+	}
+	if s.FlipY {
+		flipY = -1.0
+		flipOffsetY = s.Tex.DimY
+	}
+
+	sin := float32(math.Sin(float64(s.Rotation)))
+	cos := float32(math.Cos(float64(s.Rotation)))
+	// rotated offset from the pivot to the (flipped) quad's corner
+	offsetX := flipOffsetX - pivotX
+	offsetY := flipOffsetY - pivotY
 	trans := [16]float32{
-		1.0, 0.0, 0.0, screenCoords.X,
-		0.0, 1.0, 0.0, screenCoords.Y,
-		0.0, 0.0, 1.0, screenCoords.Y,
+		cos * flipX, -sin * flipY, 0.0, s.ScreenCenter.X + cos*offsetX - sin*offsetY,
+		sin * flipX, cos * flipY, 0.0, s.ScreenCenter.Y + sin*offsetX + cos*offsetY,
+		// depth still comes from the unrotated bottom of the sprite
+		0.0, 0.0, 1.0, s.ScreenCenter.Y - pivotY,
 		0.0, 0.0, 0.0, 1.0,
 	}
 	// logger.LOG.Error().Msgf("%v", trans)
@@ -170,6 +204,12 @@ func setTransform(
 	)
 }
 
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func setColor(shaderId uint32, tint Color, opacity float32) {
+	setUniform(shaderId, "tint", uniformValue{size: 4, values: [4]float32{tint.R, tint.G, tint.B, tint.A}})
+	setUniform(shaderId, "opacity", uniformValue{size: 1, values: [4]float32{opacity}})
+}
+
 // NOT THREAD SAFE (never will be b/c glfw & gl)
 func setScale(shaderId uint32, stretchX float32, stretchY float32) {
 	gl.UseProgram(shaderId)
@@ -250,14 +290,42 @@ func makeVAO(textureCoords [12]float32) uint32 {
 
 // NOT THREAD SAFE (never will be b/c glfw & gl)
 func makeTexture(relativePath string) (texture, error) {
-
 	logger.LOG.Debug().Msg("Creating new texture")
-	tex := texture{}
 
 	img, err := loadTextures(relativePath)
 	if err != nil {
 		return texture{}, err
 	}
+	return uploadTexture(relativePath, img), nil
+}
+
+// Decodes a texture from disk without touching the graphics card, so it is safe to call from
+// any goroutine. Pair with UploadTexture on the main thread. Errors are left to the caller (ex. a
+// missing texture in a scene manifest shouldn't end the game).
+func DecodeTexture(relativePath string) (*image.RGBA, error) {
+	return decodeTextureFile(relativePath)
+}
+
+// Returns if the texture is already on the graphics card.
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func IsTextureLoaded(relativePath string) bool {
+	_, ok := getActiveGraphicsObjects().CurrentlyActiveTextures[relativePath]
+	return ok
+}
+
+// Uploads an already decoded texture (see DecodeTexture) so later sprites using the same path
+// skip the disk entirely.
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func UploadTexture(relativePath string, img *image.RGBA) {
+	if IsTextureLoaded(relativePath) {
+		return
+	}
+	uploadTexture(relativePath, img)
+}
+
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func uploadTexture(relativePath string, img *image.RGBA) texture {
+	tex := texture{}
 	tex.DimX = float32(img.Bounds().Dx())
 	tex.DimY = float32(img.Bounds().Dy())
 	p := runtime.Pinner{}
@@ -288,100 +356,111 @@ func makeTexture(relativePath string) (texture, error) {
 
 	getActiveGraphicsObjects().CurrentlyActiveTextures[relativePath] = tex
 
-	return tex, nil
+	return tex
 }
 
 // NOT THREAD SAFE (never will be b/c glfw & gl)
 func makeShader(
 	shaderFiles ShaderFiles,
 ) (uint32, error) {
-	vShaderFileName := shaderFiles.VertexPath
-	fShaderFileName := shaderFiles.FragmentPath
 	logger.LOG.Debug().Msg("Creating new shader")
 
-	vertexCode, err := loadShaderCode(vShaderFileName)
+	shaderId, dependencies, err := buildShaderProgram(shaderFiles)
 	if err != nil {
 		return 0, err
 	}
-	vertexCodes := make([]*uint8, 1)
-	vertexCodes[0] = &vertexCode[0]
 
-	fragmentCode, err := loadShaderCode(fShaderFileName)
+	initShaderUniforms(shaderId)
+
+	getActiveGraphicsObjects().CurrentlyActiveShaders[shaderFiles.cacheKey()] = activeShader{
+		shaderId:     shaderId,
+		files:        shaderFiles,
+		dependencies: dependencies,
+	}
+
+	return shaderId, nil
+}
+
+// Loads, compiles and links both shaders. Nothing is left on the graphics card if this fails.
+// Also returns every file read along the way.
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func buildShaderProgram(shaderFiles ShaderFiles) (uint32, []string, error) {
+	vertexSource, err := loadShaderSource(shaderFiles.VertexPath, shaderFiles.Defines)
 	if err != nil {
-		return 0, err
+		return 0, nil, err
 	}
-	fragmentCodes := make([]*uint8, 1)
-	fragmentCodes[0] = &fragmentCode[0]
-
-	sV, sF, ok := compileShader(
-		&vertexCodes[0],
-		int32(len(vertexCode)),
-		&fragmentCodes[0],
-		int32(len(fragmentCode)),
-	)
-	if !ok {
-		return 0, errors.New("error compiling shader")
+	fragmentSource, err := loadShaderSource(shaderFiles.FragmentPath, shaderFiles.Defines)
+	if err != nil {
+		return 0, nil, err
 	}
+	dependencies := append(slices.Clone(vertexSource.files), fragmentSource.files...)
 
-	shaderId, ok := linkShader(sV, sF)
-	if !ok {
-		return 0, errors.New("error linking shader")
+	sV, err := compileShader(gl.VERTEX_SHADER, &vertexSource)
+	if err != nil {
+		return 0, dependencies, err
+	}
+	sF, err := compileShader(gl.FRAGMENT_SHADER, &fragmentSource)
+	if err != nil {
+		gl.DeleteShader(sV)
+		return 0, dependencies, err
 	}
 
+	shaderId, err := linkShader(sV, sF)
+	if err != nil {
+		return 0, dependencies, fmt.Errorf(
+			"%v + %v: %w", shaderFiles.VertexPath, shaderFiles.FragmentPath, err,
+		)
+	}
+	return shaderId, dependencies, nil
+}
+
+// Uniforms that never change between draws. Linking (or relinking) a program resets them.
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func initShaderUniforms(shaderId uint32) {
 	gl.UseProgram(shaderId)
 	var uniformName string = "tex"
 	gl.Uniform1i(gl.GetUniformLocation(shaderId, utils.StringToUint8(&uniformName)), 0)
 
 	setProjection(shaderId)
-
-	getActiveGraphicsObjects().CurrentlyActiveShaders[vShaderFileName+fShaderFileName] = shaderId
-
-	return shaderId, nil
 }
 
 // NOT THREAD SAFE (never will be b/c glfw & gl)
-func compileShader(
-	vertexCode **uint8, lengthVCode int32, fragmentCode **uint8, lengthFCode int32,
-) (
-	shaderVertex uint32, shaderFragment uint32, ok bool,
-) {
-	ok = true
+func compileShader(shaderType uint32, source *shaderSource) (uint32, error) {
+	if len(source.code) == 0 {
+		return 0, fmt.Errorf("%v: empty shader source", source.fileName)
+	}
 	p := runtime.Pinner{}
 	defer p.Unpin()
-	p.Pin(*vertexCode)
-	p.Pin(*fragmentCode)
+	p.Pin(&source.code[0])
+	code := &source.code[0]
+	length := int32(len(source.code))
 
-	shaderVertex = gl.CreateShader(gl.VERTEX_SHADER)
-	gl.ShaderSource(shaderVertex, 1, vertexCode, &lengthVCode)
-	gl.CompileShader(shaderVertex)
-	var okay int32
-	gl.GetShaderiv(shaderVertex, gl.COMPILE_STATUS, &okay)
-	if okay == 0 {
-		logger.LOG.Error().Msg("Vertex shader failed to compile")
-		log := make([]byte, 1000)
-		gl.GetShaderInfoLog(shaderVertex, 1000, nil, &log[0])
-		logger.LOG.Error().Msgf("Error:%v", string(log))
-		ok = false
-	}
+	shader := gl.CreateShader(shaderType)
+	gl.ShaderSource(shader, 1, &code, &length)
+	gl.CompileShader(shader)
 
-	shaderFragment = gl.CreateShader(gl.FRAGMENT_SHADER)
-	gl.ShaderSource(shaderFragment, 1, fragmentCode, &lengthFCode)
-	gl.CompileShader(shaderFragment)
-	gl.GetShaderiv(shaderFragment, gl.COMPILE_STATUS, &okay)
+	var okay int32
+	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &okay)
 	if okay == 0 {
-		logger.LOG.Error().Msg("Fragment shader failed to compile")
-		log := make([]byte, 1000)
-		gl.GetShaderInfoLog(shaderFragment, 1000, nil, &log[0])
-		logger.LOG.Error().Msgf("Error: %v", string(log))
-		ok = false
+		var logLength int32
+		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
+		log := make([]byte, logLength+1)
+		gl.GetShaderInfoLog(shader, logLength, nil, &log[0])
+		gl.DeleteShader(shader)
+		return 0, fmt.Errorf(
+			"%v failed to compile:\n%v",
+			source.fileName,
+			source.mapErrorLog(strings.TrimRight(string(log), "\x00")),
+		)
 	}
 
-	return shaderVertex, shaderFragment, ok
+	return shader, nil
 }
 
+// The shaders are flagged for deletion either way, so they are freed with the program.
 // NOT THREAD SAFE (never will be b/c glfw & gl)
-func linkShader(shaderVertex uint32, shaderFragment uint32) (shaderId uint32, ok bool) {
-	shaderId = gl.CreateProgram()
+func linkShader(shaderVertex uint32, shaderFragment uint32) (uint32, error) {
+	shaderId := gl.CreateProgram()
 	gl.AttachShader(shaderId, shaderVertex)
 	defer gl.DeleteShader(shaderVertex)
 	gl.AttachShader(shaderId, shaderFragment)
@@ -391,11 +470,12 @@ func linkShader(shaderVertex uint32, shaderFragment uint32) (shaderId uint32, ok
 	var okay int32
 	gl.GetProgramiv(shaderId, gl.LINK_STATUS, &okay)
 	if okay == 0 {
-		logger.LOG.Error().Msg("Shader failed to link")
-		log := make([]byte, 1000)
-		gl.GetProgramInfoLog(shaderId, 1000, nil, &log[0])
-		logger.LOG.Error().Msgf("Error: %v", string(log))
-		return shaderId, false
+		var logLength int32
+		gl.GetProgramiv(shaderId, gl.INFO_LOG_LENGTH, &logLength)
+		log := make([]byte, logLength+1)
+		gl.GetProgramInfoLog(shaderId, logLength, nil, &log[0])
+		gl.DeleteProgram(shaderId)
+		return 0, fmt.Errorf("failed to link: %v", strings.TrimRight(string(log), "\x00"))
 	}
-	return shaderId, true
+	return shaderId, nil
 }
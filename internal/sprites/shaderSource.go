@@ -0,0 +1,155 @@
+package sprites
+
+// Reads shader files from assets/shaders and resolves our own preprocessor additions:
+//   - #include "someFile.glsl" pastes in another file from assets/shaders (each file only once)
+//   - defines given with ShaderFiles are inserted right after the #version line
+// Since the graphics driver only sees one big string, every line remembers where it came from so
+// compile errors can point at the real file and line.
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const maxIncludeDepth int = 8
+
+type ShaderDefine struct {
+	Name  string
+	Value string
+}
+
+// where a line of the final source came from
+type sourceLine struct {
+	file string
+	line int
+}
+
+type shaderSource struct {
+	fileName string
+	code     []byte
+	lines    []sourceLine
+	// every file read to make this source (the file itself and all includes)
+	files []string
+}
+
+var includeRegex = regexp.MustCompile(`^\s*#include\s+"([^"]+)"\s*$`)
+
+// Drivers report errors as "0:12(5): ...", "0(12) : ..." or "ERROR: 0:12: ...", where 0 is the
+// source string index (we only ever pass one string) and 12 the line.
+var errorLineRegex = regexp.MustCompile(`\b0(?::(\d+)|\((\d+)\))`)
+
+func shaderFilePath(fileName string) string {
+	return filepath.Join(".", "assets", "shaders", fileName)
+}
+
+func loadShaderSource(fileName string, defines []ShaderDefine) (shaderSource, error) {
+	src := shaderSource{fileName: fileName}
+	var buf bytes.Buffer
+
+	data, err := os.ReadFile(shaderFilePath(fileName))
+	if err != nil {
+		return src, err
+	}
+	src.files = append(src.files, fileName)
+	lines := strings.Split(string(data), "\n")
+
+	// #version has to be the first thing in the source, so defines go right after it
+	versionIndex := slices.IndexFunc(lines, func(line string) bool {
+		return strings.HasPrefix(strings.TrimSpace(line), "#version")
+	})
+	if versionIndex == -1 {
+		return src, fmt.Errorf("%v: missing #version", fileName)
+	}
+	for i, line := range lines[:versionIndex+1] {
+		src.addLine(&buf, line, fileName, i+1)
+	}
+	for i, define := range defines {
+		src.addLine(&buf, fmt.Sprintf("#define %v %v", define.Name, define.Value), "<define>", i+1)
+	}
+
+	err = src.addLines(&buf, lines[versionIndex+1:], fileName, versionIndex+2, 0)
+	if err != nil {
+		return src, err
+	}
+
+	src.code = buf.Bytes()
+	return src, nil
+}
+
+func (src *shaderSource) addLines(
+	buf *bytes.Buffer, lines []string, fileName string, firstLineNum int, depth int,
+) error {
+	for i, line := range lines {
+		lineNum := firstLineNum + i
+		match := includeRegex.FindStringSubmatch(line)
+		if match == nil {
+			src.addLine(buf, line, fileName, lineNum)
+			continue
+		}
+
+		includeName := match[1]
+		if slices.Contains(src.files, includeName) {
+			// already pasted in, most snippets define functions which can't be defined twice
+			src.addLine(buf, "", fileName, lineNum)
+			continue
+		}
+		if depth >= maxIncludeDepth {
+			return fmt.Errorf("%v:%v: includes nested too deep (> %v)", fileName, lineNum, maxIncludeDepth)
+		}
+		data, err := os.ReadFile(shaderFilePath(includeName))
+		if err != nil {
+			return fmt.Errorf("%v:%v: %w", fileName, lineNum, err)
+		}
+		src.files = append(src.files, includeName)
+		includeLines := strings.Split(string(data), "\n")
+		if slices.ContainsFunc(includeLines, func(line string) bool {
+			return strings.HasPrefix(strings.TrimSpace(line), "#version")
+		}) {
+			return fmt.Errorf("%v: included files can't have a #version", includeName)
+		}
+		err = src.addLines(buf, includeLines, includeName, 1, depth+1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (src *shaderSource) addLine(buf *bytes.Buffer, line string, fileName string, lineNum int) {
+	buf.WriteString(strings.TrimRight(line, "\r"))
+	buf.WriteByte('\n')
+	src.lines = append(src.lines, sourceLine{file: fileName, line: lineNum})
+}
+
+// Swaps the driver's line numbers for file:line of where the line really came from
+func (src *shaderSource) mapErrorLog(log string) string {
+	return errorLineRegex.ReplaceAllStringFunc(log, func(match string) string {
+		groups := errorLineRegex.FindStringSubmatch(match)
+		lineNum, err := strconv.Atoi(groups[1] + groups[2])
+		if err != nil || lineNum < 1 || lineNum > len(src.lines) {
+			return match
+		}
+		line := src.lines[lineNum-1]
+		return fmt.Sprintf("%v:%v", line.file, line.line)
+	})
+}
+
+func (sf ShaderFiles) cacheKey() string {
+	var key strings.Builder
+	key.WriteString(sf.VertexPath)
+	key.WriteString("|")
+	key.WriteString(sf.FragmentPath)
+	for _, define := range sf.Defines {
+		key.WriteString("|")
+		key.WriteString(define.Name)
+		key.WriteString("=")
+		key.WriteString(define.Value)
+	}
+	return key.String()
+}
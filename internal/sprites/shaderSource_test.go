@@ -0,0 +1,175 @@
+package sprites
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writes the files into assets/shaders of a fresh working directory
+func setupShaderFiles(t *testing.T, files map[string]string) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	err := os.MkdirAll(filepath.Join(dir, "assets", "shaders"), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, contents := range files {
+		err = os.WriteFile(shaderFilePath(name), []byte(contents), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadShaderSource(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		defines   []ShaderDefine
+		wantCode  string
+		wantLines []sourceLine
+		wantFiles []string
+		wantErr   string
+	}{
+		{
+			name:      "plain",
+			files:     map[string]string{"a.vs": "#version 410\nvoid main() {}"},
+			wantCode:  "#version 410\nvoid main() {}\n",
+			wantLines: []sourceLine{{"a.vs", 1}, {"a.vs", 2}},
+			wantFiles: []string{"a.vs"},
+		},
+		{
+			name:     "defines after version",
+			files:    map[string]string{"a.vs": "// comment\n#version 410\nvoid main() {}"},
+			defines:  []ShaderDefine{{"A", "1"}, {"B", "2.0"}},
+			wantCode: "// comment\n#version 410\n#define A 1\n#define B 2.0\nvoid main() {}\n",
+			wantLines: []sourceLine{
+				{"a.vs", 1}, {"a.vs", 2}, {"<define>", 1}, {"<define>", 2}, {"a.vs", 3},
+			},
+			wantFiles: []string{"a.vs"},
+		},
+		{
+			name: "include",
+			files: map[string]string{
+				"a.fs":   "#version 410\n#include \"b.glsl\"\nvoid main() {}",
+				"b.glsl": "float b() {\n\treturn 1.0;\n}",
+			},
+			wantCode: "#version 410\nfloat b() {\n\treturn 1.0;\n}\nvoid main() {}\n",
+			wantLines: []sourceLine{
+				{"a.fs", 1}, {"b.glsl", 1}, {"b.glsl", 2}, {"b.glsl", 3}, {"a.fs", 3},
+			},
+			wantFiles: []string{"a.fs", "b.glsl"},
+		},
+		{
+			name: "included once",
+			files: map[string]string{
+				"a.fs":   "#version 410\n#include \"b.glsl\"\n#include \"c.glsl\"",
+				"b.glsl": "#include \"c.glsl\"\nb",
+				"c.glsl": "c",
+			},
+			wantCode: "#version 410\nc\nb\n\n",
+			wantLines: []sourceLine{
+				{"a.fs", 1}, {"c.glsl", 1}, {"b.glsl", 2}, {"a.fs", 3},
+			},
+			wantFiles: []string{"a.fs", "b.glsl", "c.glsl"},
+		},
+		{
+			name:      "crlf",
+			files:     map[string]string{"a.vs": "#version 410\r\nvoid main() {}\r\n"},
+			wantCode:  "#version 410\nvoid main() {}\n\n",
+			wantLines: []sourceLine{{"a.vs", 1}, {"a.vs", 2}, {"a.vs", 3}},
+			wantFiles: []string{"a.vs"},
+		},
+		{
+			name:    "missing version",
+			files:   map[string]string{"a.vs": "void main() {}"},
+			wantErr: "a.vs: missing #version",
+		},
+		{
+			name: "missing include",
+			files: map[string]string{
+				"a.vs": "#version 410\n\n#include \"nope.glsl\"",
+			},
+			wantErr: "a.vs:3:",
+		},
+		{
+			name: "version in include",
+			files: map[string]string{
+				"a.vs":   "#version 410\n#include \"b.glsl\"",
+				"b.glsl": "#version 410",
+			},
+			wantErr: "b.glsl: included files can't have a #version",
+		},
+		{
+			name: "include cycle",
+			files: map[string]string{
+				"a.vs":   "#version 410\n#include \"b.glsl\"",
+				"b.glsl": "#include \"a.vs\"",
+			},
+			wantCode:  "#version 410\n\n",
+			wantLines: []sourceLine{{"a.vs", 1}, {"b.glsl", 1}},
+			wantFiles: []string{"a.vs", "b.glsl"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setupShaderFiles(t, test.files)
+			var mainFile string
+			for name := range test.files {
+				if !strings.HasSuffix(name, ".glsl") {
+					mainFile = name
+				}
+			}
+
+			src, err := loadShaderSource(mainFile, test.defines)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(src.code) != test.wantCode {
+				t.Errorf("got code %q, want %q", src.code, test.wantCode)
+			}
+			if !slices.Equal(src.lines, test.wantLines) {
+				t.Errorf("got lines %v, want %v", src.lines, test.wantLines)
+			}
+			if !slices.Equal(src.files, test.wantFiles) {
+				t.Errorf("got files %v, want %v", src.files, test.wantFiles)
+			}
+		})
+	}
+}
+
+func TestMapErrorLog(t *testing.T) {
+	src := shaderSource{
+		lines: []sourceLine{{"a.fs", 1}, {"<define>", 1}, {"b.glsl", 7}, {"a.fs", 2}},
+	}
+	tests := []struct {
+		log  string
+		want string
+	}{
+		{"0:3(5): error: bad", "b.glsl:7(5): error: bad"},
+		{"0(4) : error C0000: bad", "a.fs:2 : error C0000: bad"},
+		{"ERROR: 0:2: 'A' : redefinition", "ERROR: <define>:1: 'A' : redefinition"},
+		{"0:1(1): one\n0:4(2): two", "a.fs:1(1): one\na.fs:2(2): two"},
+		// past the end or not a line, left alone
+		{"0:9(1): error", "0:9(1): error"},
+		{"0:0(1): error", "0:0(1): error"},
+		{"no line here", "no line here"},
+	}
+
+	for _, test := range tests {
+		got := src.mapErrorLog(test.log)
+		if got != test.want {
+			t.Errorf("mapErrorLog(%q) = %q, want %q", test.log, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package sprites
+
+// Scene transitions that show two scenes at once. Sprites are tagged with the draw queue's group
+// when they're added to it (see drawingQueue.SetGroup). While a transition is running, the sprites
+// of its outgoing group are drawn into a frame of their own, which the transition pass then
+// blends with the live frame (everything else) as the first post process pass. Both frames are
+// drawn fresh every frame, so both scenes keep moving. Fading through a color doesn't need any of
+// this, see SetFade.
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+type TransitionEffect int
+
+const (
+	// the outgoing frame fades out as the live frame fades in
+	CrossfadeEffect TransitionEffect = iota
+	// a hard edge moves across the screen, with the live frame behind it
+	WipeEffect
+	// the outgoing frame slides off screen as the live frame slides in after it
+	SlideEffect
+)
+
+// Sprites in the draw group are drawn as the outgoing frame until ClearTransition. Nothing is
+// shown differently until SetTransition.
+// thread safe
+func (pp *postProcessor) StartTransition(outgoingGroup uint32) {
+	pp.outgoingGroup.Store(outgoingGroup)
+	pp.transitioning.Store(true)
+}
+
+// Shows the outgoing and live frames together. progress goes from 0.0 (only the outgoing frame)
+// to 1.0 (only the live frame). direction is the way the live frame comes in, in screen coords
+// (ex. {X: 1.0} is left to right), for wipes and slides.
+// thread safe
+func (pp *postProcessor) SetTransition(
+	effect TransitionEffect, progress float32, direction ScreenCoords) {
+	transitionPass := pp.getTransitionPass()
+	if transitionPass == nil {
+		return
+	}
+	transitionPass.Material.SetFloat("effect", float32(effect))
+	transitionPass.Material.SetFloat("progress", progress)
+	// texture coords go up, screen coords go down
+	transitionPass.Material.SetVec2("direction", direction.X, -direction.Y)
+	transitionPass.SetEnabled(pp.transitioning.Load())
+}
+
+// Back to only the live frame, with every sprite in it
+// thread safe
+func (pp *postProcessor) ClearTransition() {
+	pp.transitioning.Store(false)
+	transitionPass := pp.getTransitionPass()
+	if transitionPass == nil {
+		return
+	}
+	transitionPass.SetEnabled(false)
+}
+
+// The draw group going into the outgoing frame, if there is one this frame
+func (pp *postProcessor) drawingOutgoing() (uint32, bool) {
+	transitionPass := pp.getTransitionPass()
+	if transitionPass == nil || !transitionPass.IsEnabled() {
+		return 0, false
+	}
+	return pp.outgoingGroup.Load(), true
+}
+
+// Draws the outgoing frame, called by the draw queue once the live frame is drawn
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func (pp *postProcessor) drawOutgoing(outgoing []*Sprite) {
+	if !pp.capturing {
+		return
+	}
+	gl.BindFramebuffer(gl.FRAMEBUFFER, pp.outgoingTarget.fbo)
+	gl.Clear(gl.COLOR_BUFFER_BIT)
+	gl.Clear(gl.DEPTH_BUFFER_BIT)
+	for _, sprite := range outgoing {
+		sprite.draw()
+	}
+	gl.BindFramebuffer(gl.FRAMEBUFFER, pp.sceneTarget.fbo)
+}
+
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func (pp *postProcessor) bindOutgoing(shaderId uint32) {
+	gl.ActiveTexture(gl.TEXTURE0 + uint32(outgoingTextureUnit))
+	gl.BindTexture(gl.TEXTURE_2D, pp.outgoingTarget.textureId)
+	gl.Uniform1i(uniformLocation(shaderId, "outgoing"), outgoingTextureUnit)
+	gl.ActiveTexture(gl.TEXTURE0)
+}
@@ -45,15 +45,46 @@ func TextToSprites(
 	return textSprites, ok
 }
 
-func runeToSprite(screenCoords sprites.ScreenCoords, scale float32, char rune) *sprites.Sprite {
-	var runeToCoords = map[rune][2]int{
-		'a': {0, 0}, 'b': {1, 0}, 'c': {2, 0}, 'd': {3, 0}, 'e': {4, 0}, 'f': {5, 0}, 'g': {6, 0},
-		'h': {0, 1}, 'i': {1, 1}, 'j': {2, 1}, 'k': {3, 1}, 'l': {4, 1}, 'm': {5, 1}, 'n': {6, 1},
-		'o': {0, 2}, 'p': {1, 2}, 'q': {2, 2}, 'r': {3, 2}, 's': {4, 2}, 't': {5, 2}, 'u': {6, 2},
-		'v': {0, 3}, 'w': {1, 3}, 'x': {2, 3}, 'y': {3, 3}, 'z': {4, 3}, '?': {5, 3}, '!': {6, 3},
-		'1': {0, 4}, '2': {1, 4}, '3': {2, 4}, '4': {3, 4}, '5': {4, 4}, '6': {5, 4}, '7': {6, 4},
-		'8': {0, 5}, '9': {1, 5}, '0': {2, 5}, '.': {3, 5}, '$': {4, 5}, '-': {5, 5}, ' ': {6, 5},
+// column, row of each character in ui/font.png
+var runeToCoords = map[rune][2]int{
+	'a': {0, 0}, 'b': {1, 0}, 'c': {2, 0}, 'd': {3, 0}, 'e': {4, 0}, 'f': {5, 0}, 'g': {6, 0},
+	'h': {0, 1}, 'i': {1, 1}, 'j': {2, 1}, 'k': {3, 1}, 'l': {4, 1}, 'm': {5, 1}, 'n': {6, 1},
+	'o': {0, 2}, 'p': {1, 2}, 'q': {2, 2}, 'r': {3, 2}, 's': {4, 2}, 't': {5, 2}, 'u': {6, 2},
+	'v': {0, 3}, 'w': {1, 3}, 'x': {2, 3}, 'y': {3, 3}, 'z': {4, 3}, '?': {5, 3}, '!': {6, 3},
+	'1': {0, 4}, '2': {1, 4}, '3': {2, 4}, '4': {3, 4}, '5': {4, 4}, '6': {5, 4}, '7': {6, 4},
+	'8': {0, 5}, '9': {1, 5}, '0': {2, 5}, '.': {3, 5}, '$': {4, 5}, '-': {5, 5}, ' ': {6, 5},
+}
+
+// If the font has the character (upper case letters are drawn lower case)
+func HasGlyph(char rune) bool {
+	_, ok := runeToCoords[unicode.ToLower(char)]
+	return ok
+}
+
+// Size of the text in screen pixels, the same way TextToSprites lays it out
+func MeasureText(message string, scale float32, maxCharWidth int) (float32, float32) {
+	var rowCount int = 1
+	var colCount int = 0
+	var widestRow int = 0
+	for _, char := range message {
+		if char == '\n' {
+			rowCount++
+			colCount = 0
+			continue
+		}
+		if colCount > maxCharWidth {
+			// wrapped after the previous character
+			rowCount++
+			colCount = 0
+		}
+		colCount++
+		widestRow = max(widestRow, colCount)
 	}
+	glyphSize := float32(baseFontSize) * scale
+	return float32(widestRow) * glyphSize, float32(rowCount) * glyphSize
+}
+
+func runeToSprite(screenCoords sprites.ScreenCoords, scale float32, char rune) *sprites.Sprite {
 	char = unicode.ToLower(char)
 	// row number is actually the second value, ie. first row has a, b, c, ...
 	return makeSprite(screenCoords, scale, runeToCoords[char][1], runeToCoords[char][0])
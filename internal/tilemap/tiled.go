@@ -0,0 +1,594 @@
+package tilemap
+
+// Reads maps made with Tiled (https://www.mapeditor.org), saved as .tmx (xml) or .json, with
+// their tilesets either embedded or in their own .tsx / .tsj / .json files.
+// Only finite, orthogonal maps are supported. Tile layer data can be csv, base64, or base64
+// compressed with zlib or gzip.
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Tiled keeps flipping in the top bits of every gid
+const (
+	flippedHorizontally uint32 = 0x80000000
+	flippedVertically   uint32 = 0x40000000
+	flippedDiagonally   uint32 = 0x20000000
+	rotatedHexagonal120 uint32 = 0x10000000
+	gidMask             uint32 = ^(flippedHorizontally | flippedVertically |
+		flippedDiagonally | rotatedHexagonal120)
+)
+
+// What the two file formats get read into
+type mapData struct {
+	width        int
+	height       int
+	tileWidth    int
+	tileHeight   int
+	tilesets     []tilesetData
+	tileLayers   []tileLayerData
+	objectLayers []objectLayerData
+}
+
+type tilesetData struct {
+	firstGid uint32
+	// relative to assets/sprites, like every other texture
+	imageRelPath string
+	imageWidth   int
+	imageHeight  int
+	tileWidth    int
+	tileHeight   int
+	tileCount    int
+	columns      int
+	margin       int
+	spacing      int
+	// local tile id -> properties
+	tileProperties map[uint32]map[string]string
+}
+
+type tileLayerData struct {
+	name       string
+	visible    bool
+	properties map[string]string
+	// row by row from the top left, 0 is no tile. Still has the flip bits
+	gids []uint32
+}
+
+type objectLayerData struct {
+	name       string
+	properties map[string]string
+	objects    []objectData
+}
+
+// In map pixels, top left of the object (y goes down like in Tiled)
+type objectData struct {
+	name       string
+	class      string
+	x          float32
+	y          float32
+	width      float32
+	height     float32
+	properties map[string]string
+}
+
+func mapsFolder() string {
+	return filepath.Join(".", "assets", "maps")
+}
+
+func spritesFolder() string {
+	return filepath.Join(".", "assets", "sprites")
+}
+
+// relPath is relative to assets/maps
+func readMapFile(relPath string) (mapData, error) {
+	path := filepath.Join(mapsFolder(), relPath)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return mapData{}, err
+	}
+
+	var m mapData
+	switch strings.ToLower(filepath.Ext(relPath)) {
+	case ".tmx":
+		m, err = parseTmx(data, filepath.Dir(path))
+	case ".json", ".tmj":
+		m, err = parseTiledJson(data, filepath.Dir(path))
+	default:
+		err = errors.New("unknown tilemap file type (expected .tmx, .tmj or .json)")
+	}
+	if err != nil {
+		return m, fmt.Errorf("%v: %w", relPath, err)
+	}
+	return m, m.validate()
+}
+
+func (m *mapData) validate() error {
+	if m.tileWidth <= 0 || m.tileHeight <= 0 {
+		return errors.New("map has no tile size")
+	}
+	for _, layer := range m.tileLayers {
+		if len(layer.gids) != m.width*m.height {
+			return fmt.Errorf(
+				"layer %v has %v tiles, expected %v", layer.name, len(layer.gids), m.width*m.height,
+			)
+		}
+	}
+	for _, tileset := range m.tilesets {
+		if tileset.columns <= 0 || tileset.imageWidth <= 0 || tileset.imageHeight <= 0 {
+			return fmt.Errorf("tileset %v has no image", tileset.imageRelPath)
+		}
+	}
+	return nil
+}
+
+// imagePath is relative to the folder of the file it was in
+func imageRelPath(imagePath string, fileDir string) (string, error) {
+	relPath, err := filepath.Rel(spritesFolder(), filepath.Join(fileDir, imagePath))
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return "", fmt.Errorf("tileset image %v is not in %v", imagePath, spritesFolder())
+	}
+	return filepath.ToSlash(relPath), nil
+}
+
+func decodeLayerData(encoding string, compression string, content string) ([]uint32, error) {
+	switch encoding {
+	case "csv":
+		var gids []uint32
+		for _, field := range strings.Split(content, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			gid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			gids = append(gids, uint32(gid))
+		}
+		return gids, nil
+	case "base64":
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(content))
+		if err != nil {
+			return nil, err
+		}
+		var reader io.Reader = bytes.NewReader(raw)
+		switch compression {
+		case "":
+		case "zlib":
+			reader, err = zlib.NewReader(reader)
+		case "gzip":
+			reader, err = gzip.NewReader(reader)
+		default:
+			return nil, fmt.Errorf("unsupported layer compression: %v", compression)
+		}
+		if err != nil {
+			return nil, err
+		}
+		raw, err = io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		gids := make([]uint32, len(raw)/4)
+		for i := range gids {
+			gids[i] = binary.LittleEndian.Uint32(raw[4*i:])
+		}
+		return gids, nil
+	}
+	return nil, fmt.Errorf("unsupported layer encoding: %v", encoding)
+}
+
+// TMX (xml)
+
+type tmxProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+	// multiline strings are kept as text instead of the value attribute
+	Text string `xml:",chardata"`
+}
+
+type tmxImage struct {
+	Source string `xml:"source,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+}
+
+type tmxTile struct {
+	Id         uint32        `xml:"id,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+type tmxTileset struct {
+	FirstGid   uint32    `xml:"firstgid,attr"`
+	Source     string    `xml:"source,attr"`
+	TileWidth  int       `xml:"tilewidth,attr"`
+	TileHeight int       `xml:"tileheight,attr"`
+	TileCount  int       `xml:"tilecount,attr"`
+	Columns    int       `xml:"columns,attr"`
+	Margin     int       `xml:"margin,attr"`
+	Spacing    int       `xml:"spacing,attr"`
+	Image      tmxImage  `xml:"image"`
+	Tiles      []tmxTile `xml:"tile"`
+}
+
+type tmxData struct {
+	Encoding    string `xml:"encoding,attr"`
+	Compression string `xml:"compression,attr"`
+	Content     string `xml:",chardata"`
+	// no encoding means one element per tile
+	Tiles []struct {
+		Gid uint32 `xml:"gid,attr"`
+	} `xml:"tile"`
+}
+
+type tmxLayer struct {
+	Name       string        `xml:"name,attr"`
+	Visible    string        `xml:"visible,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+	Data       tmxData       `xml:"data"`
+}
+
+type tmxObject struct {
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float32       `xml:"x,attr"`
+	Y          float32       `xml:"y,attr"`
+	Width      float32       `xml:"width,attr"`
+	Height     float32       `xml:"height,attr"`
+	Gid        uint32        `xml:"gid,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+type tmxObjectGroup struct {
+	Name       string        `xml:"name,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+	Objects    []tmxObject   `xml:"object"`
+}
+
+type tmxMap struct {
+	Orientation  string           `xml:"orientation,attr"`
+	Infinite     int              `xml:"infinite,attr"`
+	Width        int              `xml:"width,attr"`
+	Height       int              `xml:"height,attr"`
+	TileWidth    int              `xml:"tilewidth,attr"`
+	TileHeight   int              `xml:"tileheight,attr"`
+	Tilesets     []tmxTileset     `xml:"tileset"`
+	Layers       []tmxLayer       `xml:"layer"`
+	ObjectGroups []tmxObjectGroup `xml:"objectgroup"`
+}
+
+func tmxProperties(properties []tmxProperty) map[string]string {
+	propertyMap := make(map[string]string, len(properties))
+	for _, property := range properties {
+		if property.Value == "" {
+			propertyMap[property.Name] = property.Text
+		} else {
+			propertyMap[property.Name] = property.Value
+		}
+	}
+	return propertyMap
+}
+
+func parseTmx(data []byte, dir string) (mapData, error) {
+	var tmx tmxMap
+	if err := xml.Unmarshal(data, &tmx); err != nil {
+		return mapData{}, err
+	}
+	if tmx.Orientation != "orthogonal" {
+		return mapData{}, fmt.Errorf("unsupported map orientation: %v", tmx.Orientation)
+	}
+	if tmx.Infinite != 0 {
+		return mapData{}, errors.New("infinite maps are not supported")
+	}
+
+	m := mapData{
+		width:      tmx.Width,
+		height:     tmx.Height,
+		tileWidth:  tmx.TileWidth,
+		tileHeight: tmx.TileHeight,
+	}
+	for _, tmxSet := range tmx.Tilesets {
+		tileset, err := tmxTilesetData(tmxSet, dir)
+		if err != nil {
+			return m, err
+		}
+		m.tilesets = append(m.tilesets, tileset)
+	}
+
+	for _, tmxLayer := range tmx.Layers {
+		layer := tileLayerData{
+			name:       tmxLayer.Name,
+			visible:    tmxLayer.Visible != "0",
+			properties: tmxProperties(tmxLayer.Properties),
+		}
+		if tmxLayer.Data.Encoding == "" {
+			for _, tile := range tmxLayer.Data.Tiles {
+				layer.gids = append(layer.gids, tile.Gid)
+			}
+		} else {
+			gids, err := decodeLayerData(
+				tmxLayer.Data.Encoding, tmxLayer.Data.Compression, tmxLayer.Data.Content,
+			)
+			if err != nil {
+				return m, fmt.Errorf("layer %v: %w", tmxLayer.Name, err)
+			}
+			layer.gids = gids
+		}
+		m.tileLayers = append(m.tileLayers, layer)
+	}
+
+	for _, group := range tmx.ObjectGroups {
+		layer := objectLayerData{name: group.Name, properties: tmxProperties(group.Properties)}
+		for _, tmxObj := range group.Objects {
+			obj := objectData{
+				name:       tmxObj.Name,
+				class:      tmxObj.Class,
+				x:          tmxObj.X,
+				y:          tmxObj.Y,
+				width:      tmxObj.Width,
+				height:     tmxObj.Height,
+				properties: tmxProperties(tmxObj.Properties),
+			}
+			if obj.class == "" {
+				// Tiled before 1.9 called it type
+				obj.class = tmxObj.Type
+			}
+			if tmxObj.Gid != 0 {
+				// tile objects are placed by their bottom left
+				obj.y -= obj.height
+			}
+			layer.objects = append(layer.objects, obj)
+		}
+		m.objectLayers = append(m.objectLayers, layer)
+	}
+	return m, nil
+}
+
+func tmxTilesetData(tmxSet tmxTileset, dir string) (tilesetData, error) {
+	firstGid := tmxSet.FirstGid
+	if tmxSet.Source != "" {
+		path := filepath.Join(dir, tmxSet.Source)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return tilesetData{}, err
+		}
+		dir = filepath.Dir(path)
+		if ext := strings.ToLower(filepath.Ext(path)); ext == ".tsj" || ext == ".json" {
+			var jsonSet jsonTileset
+			if err := json.Unmarshal(data, &jsonSet); err != nil {
+				return tilesetData{}, fmt.Errorf("%v: %w", tmxSet.Source, err)
+			}
+			jsonSet.FirstGid = firstGid
+			return jsonTilesetData(jsonSet, dir)
+		}
+		if err := xml.Unmarshal(data, &tmxSet); err != nil {
+			return tilesetData{}, fmt.Errorf("%v: %w", tmxSet.Source, err)
+		}
+	}
+
+	imagePath, err := imageRelPath(tmxSet.Image.Source, dir)
+	if err != nil {
+		return tilesetData{}, err
+	}
+	tileset := tilesetData{
+		firstGid:       firstGid,
+		imageRelPath:   imagePath,
+		imageWidth:     tmxSet.Image.Width,
+		imageHeight:    tmxSet.Image.Height,
+		tileWidth:      tmxSet.TileWidth,
+		tileHeight:     tmxSet.TileHeight,
+		tileCount:      tmxSet.TileCount,
+		columns:        tmxSet.Columns,
+		margin:         tmxSet.Margin,
+		spacing:        tmxSet.Spacing,
+		tileProperties: make(map[uint32]map[string]string),
+	}
+	for _, tile := range tmxSet.Tiles {
+		tileset.tileProperties[tile.Id] = tmxProperties(tile.Properties)
+	}
+	return tileset, nil
+}
+
+// JSON
+
+type jsonProperty struct {
+	Name  string `json:"name"`
+	Value any    `json:"value"`
+}
+
+type jsonTile struct {
+	Id         uint32         `json:"id"`
+	Properties []jsonProperty `json:"properties"`
+}
+
+type jsonTileset struct {
+	FirstGid    uint32     `json:"firstgid"`
+	Source      string     `json:"source"`
+	Image       string     `json:"image"`
+	ImageWidth  int        `json:"imagewidth"`
+	ImageHeight int        `json:"imageheight"`
+	TileWidth   int        `json:"tilewidth"`
+	TileHeight  int        `json:"tileheight"`
+	TileCount   int        `json:"tilecount"`
+	Columns     int        `json:"columns"`
+	Margin      int        `json:"margin"`
+	Spacing     int        `json:"spacing"`
+	Tiles       []jsonTile `json:"tiles"`
+}
+
+type jsonObject struct {
+	Name       string         `json:"name"`
+	Type       string         `json:"type"`
+	Class      string         `json:"class"`
+	X          float32        `json:"x"`
+	Y          float32        `json:"y"`
+	Width      float32        `json:"width"`
+	Height     float32        `json:"height"`
+	Gid        uint32         `json:"gid"`
+	Properties []jsonProperty `json:"properties"`
+}
+
+type jsonLayer struct {
+	Type        string         `json:"type"`
+	Name        string         `json:"name"`
+	Visible     *bool          `json:"visible"`
+	Properties  []jsonProperty `json:"properties"`
+	Encoding    string         `json:"encoding"`
+	Compression string         `json:"compression"`
+	// an array of gids, or a base64 string
+	Data    json.RawMessage `json:"data"`
+	Objects []jsonObject    `json:"objects"`
+}
+
+type jsonMap struct {
+	Orientation string        `json:"orientation"`
+	Infinite    bool          `json:"infinite"`
+	Width       int           `json:"width"`
+	Height      int           `json:"height"`
+	TileWidth   int           `json:"tilewidth"`
+	TileHeight  int           `json:"tileheight"`
+	Tilesets    []jsonTileset `json:"tilesets"`
+	Layers      []jsonLayer   `json:"layers"`
+}
+
+func jsonProperties(properties []jsonProperty) map[string]string {
+	propertyMap := make(map[string]string, len(properties))
+	for _, property := range properties {
+		propertyMap[property.Name] = fmt.Sprint(property.Value)
+	}
+	return propertyMap
+}
+
+func parseTiledJson(data []byte, dir string) (mapData, error) {
+	var jm jsonMap
+	if err := json.Unmarshal(data, &jm); err != nil {
+		return mapData{}, err
+	}
+	if jm.Orientation != "orthogonal" {
+		return mapData{}, fmt.Errorf("unsupported map orientation: %v", jm.Orientation)
+	}
+	if jm.Infinite {
+		return mapData{}, errors.New("infinite maps are not supported")
+	}
+
+	m := mapData{
+		width:      jm.Width,
+		height:     jm.Height,
+		tileWidth:  jm.TileWidth,
+		tileHeight: jm.TileHeight,
+	}
+	for _, jsonSet := range jm.Tilesets {
+		tileset, err := jsonTilesetData(jsonSet, dir)
+		if err != nil {
+			return m, err
+		}
+		m.tilesets = append(m.tilesets, tileset)
+	}
+
+	for _, jsonLayer := range jm.Layers {
+		switch jsonLayer.Type {
+		case "tilelayer":
+			layer := tileLayerData{
+				name:       jsonLayer.Name,
+				visible:    jsonLayer.Visible == nil || *jsonLayer.Visible,
+				properties: jsonProperties(jsonLayer.Properties),
+			}
+			var err error
+			if jsonLayer.Encoding == "base64" {
+				var content string
+				err = json.Unmarshal(jsonLayer.Data, &content)
+				if err == nil {
+					layer.gids, err = decodeLayerData("base64", jsonLayer.Compression, content)
+				}
+			} else {
+				err = json.Unmarshal(jsonLayer.Data, &layer.gids)
+			}
+			if err != nil {
+				return m, fmt.Errorf("layer %v: %w", jsonLayer.Name, err)
+			}
+			m.tileLayers = append(m.tileLayers, layer)
+		case "objectgroup":
+			layer := objectLayerData{
+				name:       jsonLayer.Name,
+				properties: jsonProperties(jsonLayer.Properties),
+			}
+			for _, jsonObj := range jsonLayer.Objects {
+				obj := objectData{
+					name:       jsonObj.Name,
+					class:      jsonObj.Class,
+					x:          jsonObj.X,
+					y:          jsonObj.Y,
+					width:      jsonObj.Width,
+					height:     jsonObj.Height,
+					properties: jsonProperties(jsonObj.Properties),
+				}
+				if obj.class == "" {
+					obj.class = jsonObj.Type
+				}
+				if jsonObj.Gid != 0 {
+					obj.y -= obj.height
+				}
+				layer.objects = append(layer.objects, obj)
+			}
+			m.objectLayers = append(m.objectLayers, layer)
+		}
+	}
+	return m, nil
+}
+
+func jsonTilesetData(jsonSet jsonTileset, dir string) (tilesetData, error) {
+	if jsonSet.Source != "" {
+		if strings.ToLower(filepath.Ext(jsonSet.Source)) == ".tsx" {
+			return tmxTilesetData(
+				tmxTileset{FirstGid: jsonSet.FirstGid, Source: jsonSet.Source}, dir,
+			)
+		}
+		path := filepath.Join(dir, jsonSet.Source)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return tilesetData{}, err
+		}
+		firstGid := jsonSet.FirstGid
+		jsonSet = jsonTileset{}
+		if err := json.Unmarshal(data, &jsonSet); err != nil {
+			return tilesetData{}, fmt.Errorf("%v: %w", path, err)
+		}
+		jsonSet.FirstGid = firstGid
+		dir = filepath.Dir(path)
+	}
+
+	imagePath, err := imageRelPath(jsonSet.Image, dir)
+	if err != nil {
+		return tilesetData{}, err
+	}
+	tileset := tilesetData{
+		firstGid:       jsonSet.FirstGid,
+		imageRelPath:   imagePath,
+		imageWidth:     jsonSet.ImageWidth,
+		imageHeight:    jsonSet.ImageHeight,
+		tileWidth:      jsonSet.TileWidth,
+		tileHeight:     jsonSet.TileHeight,
+		tileCount:      jsonSet.TileCount,
+		columns:        jsonSet.Columns,
+		margin:         jsonSet.Margin,
+		spacing:        jsonSet.Spacing,
+		tileProperties: make(map[uint32]map[string]string),
+	}
+	for _, tile := range jsonSet.Tiles {
+		tileset.tileProperties[tile.Id] = jsonProperties(tile.Properties)
+	}
+	return tileset, nil
+}
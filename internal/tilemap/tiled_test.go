@@ -0,0 +1,257 @@
+package tilemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// little endian gids as Tiled writes them, compressed and base64 encoded
+func encodeGids(t *testing.T, gids []uint32, compression string) string {
+	var raw bytes.Buffer
+	var writer io.WriteCloser
+	switch compression {
+	case "zlib":
+		writer = zlib.NewWriter(&raw)
+	case "gzip":
+		writer = gzip.NewWriter(&raw)
+	default:
+		writer = nopCloser{&raw}
+	}
+	for _, gid := range gids {
+		if err := binary.Write(writer, binary.LittleEndian, gid); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(raw.Bytes())
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+func TestDecodeLayerData(t *testing.T) {
+	// the last one is gid 3 flipped horizontally, the flip bits are kept
+	gids := []uint32{0, 1, 2, 0x80000003}
+
+	tests := []struct {
+		name        string
+		encoding    string
+		compression string
+		content     string
+		want        []uint32
+		wantErr     bool
+	}{
+		{
+			name:     "csv",
+			encoding: "csv",
+			content:  "0,1,2,2147483651",
+			want:     gids,
+		},
+		{
+			name:     "csv over lines",
+			encoding: "csv",
+			content:  "\n0,1,\n2,2147483651\n",
+			want:     gids,
+		},
+		{
+			name:     "base64",
+			encoding: "base64",
+			content:  "\n   " + encodeGids(t, gids, "") + "\n  ",
+			want:     gids,
+		},
+		{
+			name:        "base64 zlib",
+			encoding:    "base64",
+			compression: "zlib",
+			content:     encodeGids(t, gids, "zlib"),
+			want:        gids,
+		},
+		{
+			name:        "base64 gzip",
+			encoding:    "base64",
+			compression: "gzip",
+			content:     encodeGids(t, gids, "gzip"),
+			want:        gids,
+		},
+		{
+			name:     "bad csv",
+			encoding: "csv",
+			content:  "0,1,x",
+			wantErr:  true,
+		},
+		{
+			name:     "bad base64",
+			encoding: "base64",
+			content:  "not base64!",
+			wantErr:  true,
+		},
+		{
+			name:        "wrong compression",
+			encoding:    "base64",
+			compression: "zlib",
+			content:     encodeGids(t, gids, "gzip"),
+			wantErr:     true,
+		},
+		{
+			name:        "unknown compression",
+			encoding:    "base64",
+			compression: "zstd",
+			content:     encodeGids(t, gids, ""),
+			wantErr:     true,
+		},
+		{
+			name:     "unknown encoding",
+			encoding: "xml",
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := decodeLayerData(test.encoding, test.compression, test.content)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("got %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// a 2x2 map, without the closing tag
+const tmxHeader string = `<map orientation="orthogonal" width="2" height="2" tilewidth="16"
+	tileheight="16">`
+
+func TestParseLayers(t *testing.T) {
+	gids := []uint32{1, 0, 0x40000002, 1}
+
+	tests := []struct {
+		name  string
+		parse func([]byte, string) (mapData, error)
+		data  string
+	}{
+		{
+			name:  "tmx csv",
+			parse: parseTmx,
+			data: tmxHeader + `
+				<layer name="ground"><data encoding="csv">1,0,
+				1073741826,1</data></layer></map>`,
+		},
+		{
+			name:  "tmx tile elements",
+			parse: parseTmx,
+			data: tmxHeader + `
+				<layer name="ground"><data><tile gid="1"/><tile/><tile gid="1073741826"/>
+				<tile gid="1"/></data></layer></map>`,
+		},
+		{
+			name:  "tmx base64 zlib",
+			parse: parseTmx,
+			data: tmxHeader + `
+				<layer name="ground"><data encoding="base64" compression="zlib">` +
+				encodeGids(t, gids, "zlib") + `</data></layer></map>`,
+		},
+		{
+			name:  "json array",
+			parse: parseTiledJson,
+			data: `{"orientation": "orthogonal", "width": 2, "height": 2, "tilewidth": 16,
+				"tileheight": 16, "layers": [{"type": "tilelayer", "name": "ground",
+				"data": [1, 0, 1073741826, 1]}]}`,
+		},
+		{
+			name:  "json base64 gzip",
+			parse: parseTiledJson,
+			data: `{"orientation": "orthogonal", "width": 2, "height": 2, "tilewidth": 16,
+				"tileheight": 16, "layers": [{"type": "tilelayer", "name": "ground",
+				"encoding": "base64", "compression": "gzip",
+				"data": "` + encodeGids(t, gids, "gzip") + `"}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, err := test.parse([]byte(test.data), ".")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := m.validate(); err != nil {
+				t.Fatal(err)
+			}
+			if len(m.tileLayers) != 1 {
+				t.Fatalf("got %v tile layers, want 1", len(m.tileLayers))
+			}
+			layer := m.tileLayers[0]
+			if layer.name != "ground" || !layer.visible {
+				t.Errorf("got layer %v (visible %v), want ground", layer.name, layer.visible)
+			}
+			if !slices.Equal(layer.gids, gids) {
+				t.Errorf("got gids %v, want %v", layer.gids, gids)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		parse   func([]byte, string) (mapData, error)
+		data    string
+		wantErr string
+	}{
+		{
+			name:    "tmx isometric",
+			parse:   parseTmx,
+			data:    `<map orientation="isometric" width="1" height="1"></map>`,
+			wantErr: "unsupported map orientation",
+		},
+		{
+			name:    "tmx infinite",
+			parse:   parseTmx,
+			data:    `<map orientation="orthogonal" infinite="1"></map>`,
+			wantErr: "infinite maps",
+		},
+		{
+			name:    "json infinite",
+			parse:   parseTiledJson,
+			data:    `{"orientation": "orthogonal", "infinite": true}`,
+			wantErr: "infinite maps",
+		},
+		{
+			name:  "json bad layer",
+			parse: parseTiledJson,
+			data: `{"orientation": "orthogonal", "layers": [{"type": "tilelayer",
+				"name": "ground", "encoding": "base64", "data": "not base64!"}]}`,
+			wantErr: "layer ground",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.parse([]byte(test.data), ".")
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("got error %v, want one containing %q", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,408 @@
+package tilemap
+
+// A Tiled map as a game object. Tile layers are drawn in chunks (one batch sprite per chunk and
+// tileset) and only chunks the camera can see get instances. Solid tiles and collision objects
+// become Collider2Ds on the EnvironmentLayer.
+//
+// What is solid:
+//   - every tile of a tile layer named "collision" or with the layer property collides = true
+//   - tiles whose tileset tile has the property collides = true (on any layer)
+//   - every object of an object layer named "collision" or with the layer property collides = true
+//
+// Hidden tile layers are not drawn, but still count for collisions.
+
+import (
+	"strings"
+	"sync/atomic"
+	"weak"
+
+	"github.com/PatrickKoch07/game-proj/internal/audio"
+	"github.com/PatrickKoch07/game-proj/internal/camera"
+	"github.com/PatrickKoch07/game-proj/internal/colliders"
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+	"github.com/PatrickKoch07/game-proj/internal/scenes"
+	"github.com/PatrickKoch07/game-proj/internal/sprites"
+)
+
+// chunks are chunkTiles x chunkTiles tiles
+const chunkTiles int = 16
+
+const collisionLayerName string = "collision"
+const collidesProperty string = "collides"
+
+// Something placed on an object layer, in world coords
+type Object struct {
+	Name       string
+	Class      string
+	Center     colliders.WorldCoords
+	Width      float32
+	Height     float32
+	Properties map[string]string
+}
+
+type chunkTile struct {
+	// world coords
+	center colliders.WorldCoords
+	width  float32
+	height float32
+	region sprites.TextureRegion
+}
+
+// all tiles of one layer, in one chunk, from one tileset
+type chunk struct {
+	sprite *sprites.Sprite
+	tiles  []chunkTile
+	// world coords bounds, for culling
+	left    float32
+	right   float32
+	bottom  float32
+	top     float32
+	visible bool
+}
+
+type Tilemap struct {
+	relPath string
+	// world coords of the map's top left corner
+	origin colliders.WorldCoords
+
+	data       mapData
+	chunks     []*chunk
+	colliders  []*colliders.Collider2D
+	gameObject scenes.GameObject
+	instances  []sprites.BatchInstance
+	// chunk instances only change when the camera moves
+	lastCameraCenter colliders.WorldCoords
+	placed           bool
+	dead             atomic.Bool
+}
+
+// relPath is relative to assets/maps. origin is where the top left corner of the map goes in the
+// world. Add it to a scene with scenes.InitOnScene (or any of the others).
+func NewTilemap(relPath string, origin colliders.WorldCoords) *Tilemap {
+	return &Tilemap{relPath: relPath, origin: origin}
+}
+
+// Should never be called concurrently because it *COULD* use glfw/gl
+func (tm *Tilemap) InitInstance() ([]scenes.GameObject, []*sprites.Sprite, []audio.Player, bool) {
+	logger.LOG.Info().Msgf("Loading tilemap %v", tm.relPath)
+	var err error
+	tm.data, err = readMapFile(tm.relPath)
+	if err != nil {
+		logger.LOG.Error().Err(err).Msg("Failed to read tilemap")
+		return nil, nil, nil, false
+	}
+	tm.gameObject = tm
+
+	creationSuccess := true
+	layerIndex := 0
+	for _, layer := range tm.data.tileLayers {
+		if !layer.visible {
+			continue
+		}
+		if err := tm.makeLayerChunks(layer, layerIndex); err != nil {
+			logger.LOG.Error().Err(err).Msgf("Failed to make tile layer %v", layer.name)
+			creationSuccess = false
+		}
+		layerIndex++
+	}
+	tm.makeColliders()
+
+	Sprites := make([]*sprites.Sprite, 0, len(tm.chunks))
+	for _, c := range tm.chunks {
+		Sprites = append(Sprites, c.sprite)
+		sprites.GetDrawQueue().AddToQueue(weak.Make(c.sprite))
+	}
+	return []scenes.GameObject{tm}, Sprites, []audio.Player{}, creationSuccess
+}
+
+// NOT THREAD SAFE (never will be b/c glfw & gl)
+func (tm *Tilemap) makeLayerChunks(layer tileLayerData, layerIndex int) error {
+	// every layer is drawn over the ones before it
+	material := sprites.NewMaterial()
+	material.SetFloat("layerIndex", float32(layerIndex))
+
+	chunksX := (tm.data.width + chunkTiles - 1) / chunkTiles
+	chunksY := (tm.data.height + chunkTiles - 1) / chunkTiles
+	for chunkY := range chunksY {
+		for chunkX := range chunksX {
+			// tileset index -> chunk
+			layerChunks := make(map[int]*chunk)
+			for row := chunkY * chunkTiles; row < min((chunkY+1)*chunkTiles, tm.data.height); row++ {
+				for col := chunkX * chunkTiles; col < min((chunkX+1)*chunkTiles, tm.data.width); col++ {
+					gid := layer.gids[row*tm.data.width+col]
+					tilesetIndex, ok := tm.data.tilesetOf(gid)
+					if !ok {
+						continue
+					}
+					c, ok := layerChunks[tilesetIndex]
+					if !ok {
+						c = tm.newChunk(chunkX, chunkY)
+						layerChunks[tilesetIndex] = c
+					}
+					c.tiles = append(c.tiles, tm.makeChunkTile(gid, tilesetIndex, col, row))
+				}
+			}
+
+			for tilesetIndex, c := range layerChunks {
+				sprite, err := sprites.CreateBatchSprite(
+					&sprites.SpriteInitParams{
+						ShaderRelPaths: sprites.ShaderFiles{
+							VertexPath:   "tile.vs",
+							FragmentPath: "tile.fs",
+						},
+						TextureRelPath: tm.data.tilesets[tilesetIndex].imageRelPath,
+						TextureCoords:  sprites.TexCoordOneSpritePerImg,
+						Material:       material,
+					},
+				)
+				if err != nil {
+					return err
+				}
+				c.sprite = sprite
+				tm.chunks = append(tm.chunks, c)
+			}
+		}
+	}
+	return nil
+}
+
+func (tm *Tilemap) newChunk(chunkX int, chunkY int) *chunk {
+	tileWidth := float32(tm.data.tileWidth)
+	tileHeight := float32(tm.data.tileHeight)
+	return &chunk{
+		left:   tm.origin.X + float32(chunkX*chunkTiles)*tileWidth,
+		right:  tm.origin.X + float32((chunkX+1)*chunkTiles)*tileWidth,
+		top:    tm.origin.Y - float32(chunkY*chunkTiles)*tileHeight,
+		bottom: tm.origin.Y - float32((chunkY+1)*chunkTiles)*tileHeight,
+	}
+}
+
+// Tiles bigger than the map's tiles stick out of the top right of their cell, like in Tiled.
+func (tm *Tilemap) makeChunkTile(gid uint32, tilesetIndex int, col int, row int) chunkTile {
+	tileset := tm.data.tilesets[tilesetIndex]
+	localId := int((gid & gidMask) - tileset.firstGid)
+	pixelX := tileset.margin + (localId%tileset.columns)*(tileset.tileWidth+tileset.spacing)
+	pixelY := tileset.margin + (localId/tileset.columns)*(tileset.tileHeight+tileset.spacing)
+	region := sprites.TextureRegion{
+		X:      float32(pixelX) / float32(tileset.imageWidth),
+		Y:      float32(pixelY) / float32(tileset.imageHeight),
+		Width:  float32(tileset.tileWidth) / float32(tileset.imageWidth),
+		Height: float32(tileset.tileHeight) / float32(tileset.imageHeight),
+	}
+	// diagonal flips (tile rotations) aren't supported, those tiles are drawn unrotated
+	if gid&flippedHorizontally != 0 {
+		region.X += region.Width
+		region.Width = -region.Width
+	}
+	if gid&flippedVertically != 0 {
+		region.Y += region.Height
+		region.Height = -region.Height
+	}
+
+	width := float32(tileset.tileWidth)
+	height := float32(tileset.tileHeight)
+	cellLeft := tm.origin.X + float32(col*tm.data.tileWidth)
+	cellBottom := tm.origin.Y - float32((row+1)*tm.data.tileHeight)
+	return chunkTile{
+		center: colliders.WorldCoords{X: cellLeft + width/2.0, Y: cellBottom + height/2.0},
+		width:  width,
+		height: height,
+		region: region,
+	}
+}
+
+// Index of the tileset the gid belongs to (the last one starting at or before it)
+func (m *mapData) tilesetOf(gid uint32) (int, bool) {
+	gid &= gidMask
+	if gid == 0 {
+		return 0, false
+	}
+	index := -1
+	for i, tileset := range m.tilesets {
+		if tileset.firstGid <= gid && (index == -1 || tileset.firstGid > m.tilesets[index].firstGid) {
+			index = i
+		}
+	}
+	return index, index != -1
+}
+
+func isCollisionLayer(name string, properties map[string]string) bool {
+	return strings.EqualFold(name, collisionLayerName) || properties[collidesProperty] == "true"
+}
+
+func (tm *Tilemap) isSolidTile(gid uint32) bool {
+	tilesetIndex, ok := tm.data.tilesetOf(gid)
+	if !ok {
+		return false
+	}
+	tileset := tm.data.tilesets[tilesetIndex]
+	localId := (gid & gidMask) - tileset.firstGid
+	return tileset.tileProperties[localId][collidesProperty] == "true"
+}
+
+func (tm *Tilemap) makeColliders() {
+	solid := make([]bool, tm.data.width*tm.data.height)
+	for _, layer := range tm.data.tileLayers {
+		wholeLayer := isCollisionLayer(layer.name, layer.properties)
+		for i, gid := range layer.gids {
+			if gid&gidMask == 0 {
+				continue
+			}
+			if wholeLayer || tm.isSolidTile(gid) {
+				solid[i] = true
+			}
+		}
+	}
+
+	// merge solid tiles into as few rectangles as we (greedily) can
+	tileWidth := float32(tm.data.tileWidth)
+	tileHeight := float32(tm.data.tileHeight)
+	for row := range tm.data.height {
+		for col := range tm.data.width {
+			if !solid[row*tm.data.width+col] {
+				continue
+			}
+			width := 1
+			for col+width < tm.data.width && solid[row*tm.data.width+col+width] {
+				width++
+			}
+			height := 1
+			for row+height < tm.data.height && tm.isSolidRun(solid, row+height, col, width) {
+				height++
+			}
+			for dy := range height {
+				for dx := range width {
+					solid[(row+dy)*tm.data.width+col+dx] = false
+				}
+			}
+			tm.addCollider(
+				colliders.WorldCoords{
+					X: tm.origin.X + (float32(col)+float32(width)/2.0)*tileWidth,
+					Y: tm.origin.Y - (float32(row)+float32(height)/2.0)*tileHeight,
+				},
+				float32(width)*tileWidth,
+				float32(height)*tileHeight,
+			)
+		}
+	}
+
+	for _, layer := range tm.data.objectLayers {
+		if !isCollisionLayer(layer.name, layer.properties) {
+			continue
+		}
+		for _, obj := range layer.objects {
+			if obj.width <= 0.0 || obj.height <= 0.0 {
+				continue
+			}
+			tm.addCollider(tm.objectCenter(obj), obj.width, obj.height)
+		}
+	}
+	logger.LOG.Debug().Msgf("Tilemap %v made %v colliders", tm.relPath, len(tm.colliders))
+}
+
+func (tm *Tilemap) isSolidRun(solid []bool, row int, col int, width int) bool {
+	for dx := range width {
+		if !solid[row*tm.data.width+col+dx] {
+			return false
+		}
+	}
+	return true
+}
+
+func (tm *Tilemap) addCollider(center colliders.WorldCoords, width float32, height float32) {
+	collider := &colliders.Collider2D{
+		Layers:           colliders.EnvironmentLayer,
+		CenterCoords:     center,
+		Width:            width,
+		Height:           height,
+		OnEnterCollision: func(c *colliders.Collider2D) {},
+		OnExitCollision:  func(c *colliders.Collider2D) {},
+		Parent:           &tm.gameObject,
+	}
+	colliders.AddColliderToMaps(collider)
+	tm.colliders = append(tm.colliders, collider)
+}
+
+func (tm *Tilemap) objectCenter(obj objectData) colliders.WorldCoords {
+	return colliders.WorldCoords{
+		X: tm.origin.X + obj.x + obj.width/2.0,
+		Y: tm.origin.Y - obj.y - obj.height/2.0,
+	}
+}
+
+// Every object on the object layer with this name (ex. spawn points), in world coords.
+func (tm *Tilemap) Objects(layerName string) []Object {
+	var objects []Object
+	for _, layer := range tm.data.objectLayers {
+		if layer.name != layerName {
+			continue
+		}
+		for _, obj := range layer.objects {
+			objects = append(objects, Object{
+				Name:       obj.name,
+				Class:      obj.class,
+				Center:     tm.objectCenter(obj),
+				Width:      obj.width,
+				Height:     obj.height,
+				Properties: obj.properties,
+			})
+		}
+	}
+	return objects
+}
+
+func (tm *Tilemap) Update() {
+	cameraCenter := camera.GetCamera().WorldCenter
+	if tm.placed && cameraCenter == tm.lastCameraCenter {
+		return
+	}
+	tm.placed = true
+	tm.lastCameraCenter = cameraCenter
+
+	halfWidth := float32(camera.GetCamera().ScreenWidth) / 2.0
+	halfHeight := float32(camera.GetCamera().ScreenHeight) / 2.0
+	for _, c := range tm.chunks {
+		visible := c.right >= cameraCenter.X-halfWidth && c.left <= cameraCenter.X+halfWidth &&
+			c.top >= cameraCenter.Y-halfHeight && c.bottom <= cameraCenter.Y+halfHeight
+		if !visible {
+			if c.visible {
+				c.sprite.SetInstances(nil)
+				c.visible = false
+			}
+			continue
+		}
+		c.visible = true
+
+		tm.instances = tm.instances[:0]
+		for _, tile := range c.tiles {
+			tm.instances = append(tm.instances, sprites.BatchInstance{
+				ScreenCenter:  camera.WorldCoordsToScreenCoords(tile.center),
+				Width:         tile.width,
+				Height:        tile.height,
+				Color:         sprites.Color{R: 1.0, G: 1.0, B: 1.0, A: 1.0},
+				TextureRegion: tile.region,
+			})
+		}
+		c.sprite.SetInstances(tm.instances)
+	}
+}
+
+func (tm *Tilemap) ShouldSkipUpdate() bool {
+	return false
+}
+
+func (tm *Tilemap) Kill() {
+	if tm.dead.Swap(true) {
+		return
+	}
+	for _, c := range tm.chunks {
+		c.sprite.Clear()
+	}
+	for _, collider := range tm.colliders {
+		colliders.RemoveColliderFromMaps(collider)
+	}
+}
+
+func (tm *Tilemap) IsDead() bool {
+	return tm.dead.Load()
+}
@@ -0,0 +1,172 @@
+package tilemap
+
+import (
+	"testing"
+
+	"github.com/PatrickKoch07/game-proj/internal/colliders"
+)
+
+// one string per row: '#' is gid 1, '~' gid 2, 'f' gid 1 flipped and '.' no tile
+func testLayer(name string, properties map[string]string, rows ...string) tileLayerData {
+	layer := tileLayerData{name: name, visible: true, properties: properties}
+	for _, row := range rows {
+		for _, tile := range row {
+			switch tile {
+			case '#':
+				layer.gids = append(layer.gids, 1)
+			case '~':
+				layer.gids = append(layer.gids, 2)
+			case 'f':
+				layer.gids = append(layer.gids, 1|flippedHorizontally)
+			default:
+				layer.gids = append(layer.gids, 0)
+			}
+		}
+	}
+	return layer
+}
+
+type testRect struct {
+	center colliders.WorldCoords
+	width  float32
+	height float32
+}
+
+func TestMakeColliders(t *testing.T) {
+	collides := map[string]string{collidesProperty: "true"}
+	// local tile 1 (gid 2) collides wherever it is
+	tileset := tilesetData{
+		firstGid:       1,
+		tileProperties: map[uint32]map[string]string{1: collides},
+	}
+
+	tests := []struct {
+		name         string
+		width        int
+		height       int
+		tileLayers   []tileLayerData
+		objectLayers []objectLayerData
+		want         []testRect
+	}{
+		{
+			name:   "rows and columns merged",
+			width:  4,
+			height: 3,
+			tileLayers: []tileLayerData{
+				testLayer(collisionLayerName, nil, "###.", "###.", "..#."),
+			},
+			want: []testRect{
+				{colliders.WorldCoords{X: 124, Y: 42}, 48, 16},
+				{colliders.WorldCoords{X: 140, Y: 30}, 16, 8},
+			},
+		},
+		{
+			name:   "l shape",
+			width:  2,
+			height: 2,
+			tileLayers: []tileLayerData{
+				testLayer("Collision", nil, "##", "#."),
+			},
+			want: []testRect{
+				{colliders.WorldCoords{X: 116, Y: 46}, 32, 8},
+				{colliders.WorldCoords{X: 108, Y: 38}, 16, 8},
+			},
+		},
+		{
+			name:   "layer property",
+			width:  1,
+			height: 1,
+			tileLayers: []tileLayerData{
+				testLayer("walls", collides, "#"),
+			},
+			want: []testRect{
+				{colliders.WorldCoords{X: 108, Y: 46}, 16, 8},
+			},
+		},
+		{
+			name:   "tile property",
+			width:  3,
+			height: 1,
+			tileLayers: []tileLayerData{
+				testLayer("ground", nil, "#~~"),
+			},
+			want: []testRect{
+				{colliders.WorldCoords{X: 132, Y: 46}, 32, 8},
+			},
+		},
+		{
+			name:   "layers merged together",
+			width:  2,
+			height: 1,
+			tileLayers: []tileLayerData{
+				testLayer(collisionLayerName, nil, "#."),
+				testLayer("walls", collides, ".#"),
+			},
+			want: []testRect{
+				{colliders.WorldCoords{X: 116, Y: 46}, 32, 8},
+			},
+		},
+		{
+			name:   "hidden layer and flipped tile",
+			width:  1,
+			height: 1,
+			tileLayers: []tileLayerData{
+				func() tileLayerData {
+					layer := testLayer(collisionLayerName, nil, "f")
+					layer.visible = false
+					return layer
+				}(),
+			},
+			want: []testRect{
+				{colliders.WorldCoords{X: 108, Y: 46}, 16, 8},
+			},
+		},
+		{
+			name:   "collision objects",
+			width:  1,
+			height: 1,
+			objectLayers: []objectLayerData{
+				{name: collisionLayerName, objects: []objectData{
+					{x: 16, y: 8, width: 32, height: 16},
+					// points have nothing to collide with
+					{name: "point", x: 4, y: 4},
+				}},
+				{name: "spawns", objects: []objectData{{x: 0, y: 0, width: 16, height: 16}}},
+			},
+			want: []testRect{
+				{colliders.WorldCoords{X: 132, Y: 34}, 32, 16},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tm := NewTilemap(test.name, colliders.WorldCoords{X: 100, Y: 50})
+			tm.data = mapData{
+				width:        test.width,
+				height:       test.height,
+				tileWidth:    16,
+				tileHeight:   8,
+				tilesets:     []tilesetData{tileset},
+				tileLayers:   test.tileLayers,
+				objectLayers: test.objectLayers,
+			}
+			tm.makeColliders()
+			t.Cleanup(func() {
+				for _, collider := range tm.colliders {
+					colliders.RemoveColliderFromMaps(collider)
+				}
+			})
+
+			if len(tm.colliders) != len(test.want) {
+				t.Fatalf("got %v colliders, want %v", len(tm.colliders), len(test.want))
+			}
+			for i, collider := range tm.colliders {
+				got := testRect{collider.CenterCoords, collider.Width, collider.Height}
+				if got != test.want[i] {
+					t.Errorf("collider %v is %v, want %v", i, got, test.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package ui
+
+import (
+	"io"
+
+	"github.com/PatrickKoch07/game-proj/internal/audio"
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+	"github.com/PatrickKoch07/game-proj/internal/sprites"
+)
+
+// A sprite sheet with the button's look for each State, frames side by side in State order
+// (normal, hovered, pressed, disabled). States past FrameCount use the first frame, darkened.
+type ButtonSkin struct {
+	TextureRelPath string
+	FrameCount     int
+}
+
+var DefaultButtonSkin = ButtonSkin{TextureRelPath: "ui/buttonSheet.png", FrameCount: 4}
+
+const defaultClickSound string = "assets/audio/buttonPress.mp3"
+
+// Clicks when the mouse button is pressed and released over it. Moving off the button while
+// holding cancels the click, even if the cursor comes back.
+type Button struct {
+	Base
+	// Optional. The button was pressed down on. Nothing should happen yet (see OnClick)
+	OnPress func()
+	// Optional. The button was clicked, or confirm was pressed while it had focus
+	OnClick func()
+	// Optional. The cursor (or keyboard/gamepad focus) moved onto the button
+	OnHover func()
+
+	// set these before the button is first drawn
+	Skin ButtonSkin
+	// Optional. Audio files played on hover and click, "" plays nothing
+	HoverSound string
+	ClickSound string
+
+	label *Label
+	// one sprite per frame, only the current state's is shown
+	frames      []*sprites.Sprite
+	hoverPlayer audio.Player
+	clickPlayer audio.Player
+	failed      bool
+	// the cursor left the button while it was held
+	cancelled bool
+}
+
+func NewButton(message string, width float32, height float32) *Button {
+	return &Button{
+		Base:       Base{Width: width, Height: height},
+		Skin:       DefaultButtonSkin,
+		ClickSound: defaultClickSound,
+		label:      NewLabel(message, 1.75),
+	}
+}
+
+func (b *Button) Label() *Label {
+	return b.label
+}
+
+func (b *Button) layout(rect Rect) {
+	b.rect = rect
+	b.label.layout(rect)
+}
+
+func (b *Button) preferredSize() (float32, float32) {
+	width, height := b.label.preferredSize()
+	// some room around the text
+	return width + 2.0*glyphSize, height + glyphSize
+}
+
+func (b *Button) children() []Widget {
+	return []Widget{b.label}
+}
+
+// Texture coords of one frame of a sheet of frames side by side
+func frameTexCoords(frame int, frameCount int) [12]float32 {
+	left := float32(frame) / float32(frameCount)
+	right := float32(frame+1) / float32(frameCount)
+	return [12]float32{
+		left, 0.0,
+		left, 1.0,
+		right, 1.0,
+
+		left, 0.0,
+		right, 1.0,
+		right, 0.0,
+	}
+}
+
+// should only be called in the main thread (gl)
+func (b *Button) load(r *Root) {
+	frameCount := max(b.Skin.FrameCount, 1)
+	for frame := range frameCount {
+		sprite, err := sprites.CreateSprite(
+			&sprites.SpriteInitParams{
+				ShaderRelPaths: sprites.ShaderFiles{
+					VertexPath:   "uiShader.vs",
+					FragmentPath: "alphaTextureShader.fs",
+				},
+				TextureRelPath: b.Skin.TextureRelPath,
+				TextureCoords:  frameTexCoords(frame, frameCount),
+				SpriteCenter:   sprites.SpriteCoords{X: 0.0, Y: 0.0},
+				// Tex Dim is set to the button's size every frame anyway
+				StretchX: 1.0,
+				StretchY: 1.0,
+			},
+		)
+		if err != nil {
+			logger.LOG.Error().Err(err).Msgf("UI button skin %v", b.Skin.TextureRelPath)
+			r.removeSprites(b.frames...)
+			b.frames = nil
+			b.failed = true
+			return
+		}
+		b.frames = append(b.frames, sprite)
+	}
+	r.addSprites(b.frames...)
+
+	b.hoverPlayer = loadSound(r, b.HoverSound)
+	b.clickPlayer = loadSound(r, b.ClickSound)
+}
+
+func loadSound(r *Root, mp3FilePath string) audio.Player {
+	if mp3FilePath == "" {
+		return nil
+	}
+	audioPlayer, err := audio.CreatePlayer(mp3FilePath)
+	if err != nil {
+		logger.LOG.Error().Err(err).Msgf("UI sound %v", mp3FilePath)
+		return nil
+	}
+	r.addAudio(audioPlayer)
+	return audioPlayer
+}
+
+// from the start, even if it's still playing
+func playSound(audioPlayer audio.Player) {
+	if audioPlayer == nil {
+		return
+	}
+	_, err := audioPlayer.Seek(0, io.SeekStart)
+	if err != nil {
+		logger.LOG.Warn().Err(err).Msg("Couldn't rewind UI sound")
+	}
+	audioPlayer.Play()
+}
+
+func (b *Button) draw(r *Root, state State, hidden bool) {
+	if b.frames == nil && !b.failed {
+		b.load(r)
+	}
+	if b.frames == nil {
+		return
+	}
+	if state == Pressed && b.cancelled {
+		state = Normal
+		if b.hovered {
+			state = Hovered
+		}
+	}
+
+	shown := int(state)
+	tint := sprites.Color{R: 1.0, G: 1.0, B: 1.0, A: 1.0}
+	if shown >= len(b.frames) {
+		shown = 0
+		tint = shaded(tint, state)
+	}
+	for frame, sprite := range b.frames {
+		placeSprite(sprite, b.rect, tint, Hovered, hidden || frame != shown)
+	}
+}
+
+func (b *Button) onHover() {
+	playSound(b.hoverPlayer)
+	if b.OnHover != nil {
+		b.OnHover()
+	}
+}
+
+// a whole click at once
+func (b *Button) activate() {
+	if b.OnPress != nil {
+		b.OnPress()
+	}
+	b.click()
+}
+
+func (b *Button) click() {
+	playSound(b.clickPlayer)
+	if b.OnClick != nil {
+		b.OnClick()
+	}
+}
+
+func (b *Button) onPress(point sprites.ScreenCoords) {
+	logger.LOG.Debug().Msgf("Mouse pressed at (%v, %v)", point.X, point.Y)
+	b.cancelled = false
+	if b.OnPress != nil {
+		b.OnPress()
+	}
+}
+
+func (b *Button) onDrag(point sprites.ScreenCoords) {
+	if !b.rect.Contains(point) {
+		b.cancelled = true
+	}
+}
+
+func (b *Button) onRelease(point sprites.ScreenCoords, inside bool) {
+	if !inside || b.cancelled {
+		return
+	}
+	logger.LOG.Debug().Msgf("Mouse released at (%v, %v)", point.X, point.Y)
+	b.click()
+}
@@ -0,0 +1,408 @@
+package ui
+
+import (
+	"math"
+	"time"
+
+	"github.com/PatrickKoch07/game-proj/internal/inputs"
+	"github.com/PatrickKoch07/game-proj/internal/primitives"
+	"github.com/PatrickKoch07/game-proj/internal/sprites"
+	"github.com/PatrickKoch07/game-proj/internal/text"
+)
+
+const checkboxSize float32 = 32.0
+
+// A box that toggles when clicked, with text to its right
+type Checkbox struct {
+	Base
+	Checked bool
+	// Optional. Called with the new value when clicked
+	OnChange func(bool)
+
+	label *Label
+}
+
+func NewCheckbox(message string, checked bool) *Checkbox {
+	label := NewLabel(message, 1.5)
+	label.Align = AlignStart
+	return &Checkbox{Checked: checked, label: label}
+}
+
+func (c *Checkbox) preferredSize() (float32, float32) {
+	width, height := c.label.preferredSize()
+	return checkboxSize + glyphSize + width, max(checkboxSize, height)
+}
+
+func (c *Checkbox) layout(rect Rect) {
+	c.rect = rect
+	c.label.layout(Rect{
+		X:      rect.X + checkboxSize + glyphSize,
+		Y:      rect.Y,
+		Width:  max(rect.Width-checkboxSize-glyphSize, 0.0),
+		Height: rect.Height,
+	})
+}
+
+func (c *Checkbox) children() []Widget {
+	return []Widget{c.label}
+}
+
+func (c *Checkbox) box() Rect {
+	return Rect{X: c.rect.X, Y: c.rect.Y + (c.rect.Height-checkboxSize)/2.0, Width: checkboxSize, Height: checkboxSize}
+}
+
+func (c *Checkbox) draw(r *Root, state State, hidden bool) {
+	if hidden {
+		return
+	}
+	box := c.box()
+	primitives.FillRect(rectCenter(box), box.Width, box.Height, screenStyle(fieldColor))
+	outline := screenStyle(shaded(borderColor, state))
+	outline.Thickness = 2.0
+	primitives.DrawRect(rectCenter(box), box.Width, box.Height, outline)
+	if c.Checked {
+		inner := box.inset(checkboxSize / 4.0)
+		primitives.FillRect(rectCenter(inner), inner.Width, inner.Height, screenStyle(shaded(accentColor, state)))
+	}
+}
+
+func (c *Checkbox) onPress(point sprites.ScreenCoords) {}
+
+func (c *Checkbox) onDrag(point sprites.ScreenCoords) {}
+
+func (c *Checkbox) onRelease(point sprites.ScreenCoords, inside bool) {
+	if inside {
+		c.activate()
+	}
+}
+
+func (c *Checkbox) activate() {
+	c.Checked = !c.Checked
+	if c.OnChange != nil {
+		c.OnChange(c.Checked)
+	}
+}
+
+const sliderKnobRadius float32 = 10.0
+
+// Picks a number between Min and Max by dragging a knob along a track
+type Slider struct {
+	Base
+	Min   float32
+	Max   float32
+	Value float32
+	// Optional. Values snap to Min plus a multiple of Step
+	Step float32
+	// Optional. Called with the new value every time it changes
+	OnChange func(float32)
+}
+
+func NewSlider(minValue float32, maxValue float32, value float32) *Slider {
+	return &Slider{Min: minValue, Max: maxValue, Value: min(max(value, minValue), maxValue)}
+}
+
+func (s *Slider) preferredSize() (float32, float32) {
+	return 256.0, checkboxSize
+}
+
+// the track, inset so the knob doesn't stick out at the ends
+func (s *Slider) track() (float32, float32, float32) {
+	return s.rect.X + sliderKnobRadius, s.rect.X + s.rect.Width - sliderKnobRadius, s.rect.Y + s.rect.Height/2.0
+}
+
+func (s *Slider) fraction() float32 {
+	if s.Max == s.Min {
+		return 0.0
+	}
+	return (s.Value - s.Min) / (s.Max - s.Min)
+}
+
+func (s *Slider) draw(r *Root, state State, hidden bool) {
+	if hidden {
+		return
+	}
+	start, end, y := s.track()
+	knobX := start + (end-start)*s.fraction()
+
+	track := screenStyle(shaded(fieldColor, state))
+	track.Thickness = 6.0
+	primitives.DrawLine(primitives.Point{X: start, Y: y}, primitives.Point{X: end, Y: y}, track)
+	track.Color = shaded(accentColor, state)
+	primitives.DrawLine(primitives.Point{X: start, Y: y}, primitives.Point{X: knobX, Y: y}, track)
+	primitives.FillCircle(primitives.Point{X: knobX, Y: y}, sliderKnobRadius, screenStyle(shaded(borderColor, state)))
+}
+
+func (s *Slider) setFromPoint(point sprites.ScreenCoords) {
+	start, end, _ := s.track()
+	fraction := min(max((point.X-start)/max(end-start, 1.0), 0.0), 1.0)
+	s.setValue(s.Min + fraction*(s.Max-s.Min))
+}
+
+// snaps and clamps value, then calls OnChange if it changed
+func (s *Slider) setValue(value float32) {
+	if s.Step > 0.0 {
+		value = s.Min + s.Step*float32(math.Round(float64((value-s.Min)/s.Step)))
+	}
+	value = min(max(value, min(s.Min, s.Max)), max(s.Min, s.Max))
+	if value == s.Value {
+		return
+	}
+	s.Value = value
+	if s.OnChange != nil {
+		s.OnChange(value)
+	}
+}
+
+func (s *Slider) onPress(point sprites.ScreenCoords) {
+	s.setFromPoint(point)
+}
+
+func (s *Slider) onDrag(point sprites.ScreenCoords) {
+	s.setFromPoint(point)
+}
+
+func (s *Slider) onRelease(point sprites.ScreenCoords, inside bool) {}
+
+// by Step, or a tenth of the range without one
+func (s *Slider) adjust(steps int) {
+	step := s.Step
+	if step <= 0.0 {
+		step = (s.Max - s.Min) / 10.0
+	}
+	s.setValue(s.Value + float32(steps)*step)
+}
+
+const textInputPadding float32 = 8.0
+const caretBlinkTime time.Duration = 500 * time.Millisecond
+
+// One line of typed text. Click it to type, click anywhere else to stop
+type TextInput struct {
+	Base
+	// in characters
+	MaxLength int
+	// Optional. Called after every edit
+	OnChange func(string)
+	// Optional. Called when enter is pressed
+	OnSubmit func(string)
+
+	label  *Label
+	typing bool
+}
+
+func NewTextInput(message string, maxLength int) *TextInput {
+	label := NewLabel(message, 1.5)
+	label.Align = AlignStart
+	return &TextInput{MaxLength: maxLength, label: label}
+}
+
+func (t *TextInput) Text() string {
+	return t.label.Text()
+}
+
+func (t *TextInput) SetText(message string) {
+	t.label.SetText(message)
+}
+
+func (t *TextInput) preferredSize() (float32, float32) {
+	glyph := glyphSize * t.label.Scale
+	return float32(max(t.MaxLength, 1))*glyph + 2.0*textInputPadding, glyph + 2.0*textInputPadding
+}
+
+func (t *TextInput) layout(rect Rect) {
+	t.rect = rect
+	t.label.layout(rect.inset(textInputPadding))
+}
+
+func (t *TextInput) children() []Widget {
+	return []Widget{t.label}
+}
+
+func (t *TextInput) draw(r *Root, state State, hidden bool) {
+	if hidden {
+		return
+	}
+	primitives.FillRect(rectCenter(t.rect), t.rect.Width, t.rect.Height, screenStyle(fieldColor))
+	outline := screenStyle(shaded(borderColor, state))
+	if t.typing {
+		outline.Color = accentColor
+	}
+	outline.Thickness = 2.0
+	primitives.DrawRect(rectCenter(t.rect), t.rect.Width, t.rect.Height, outline)
+
+	if t.typing && time.Now().UnixMilli()/caretBlinkTime.Milliseconds()%2 == 0 {
+		width, height := t.label.preferredSize()
+		glyph := glyphSize * t.label.Scale
+		caretX := t.label.rect.X + width + 2.0
+		centerY := t.label.rect.Y + t.label.rect.Height/2.0
+		caret := screenStyle(borderColor)
+		caret.Thickness = 2.0
+		primitives.DrawLine(
+			primitives.Point{X: caretX, Y: centerY - max(height, glyph)/2.0},
+			primitives.Point{X: caretX, Y: centerY + max(height, glyph)/2.0},
+			caret,
+		)
+	}
+}
+
+func (t *TextInput) onPress(point sprites.ScreenCoords) {
+	t.typing = true
+}
+
+func (t *TextInput) onDrag(point sprites.ScreenCoords) {}
+
+func (t *TextInput) onRelease(point sprites.ScreenCoords, inside bool) {}
+
+func (t *TextInput) onText(char rune) {
+	message := []rune(t.label.Text())
+	if char == '\n' || !text.HasGlyph(char) || len(message) >= t.MaxLength {
+		return
+	}
+	t.label.SetText(string(append(message, char)))
+	if t.OnChange != nil {
+		t.OnChange(t.label.Text())
+	}
+}
+
+func (t *TextInput) onTextKey(key inputs.Key) {
+	switch key {
+	case inputs.KeyBackspace:
+		message := []rune(t.label.Text())
+		if len(message) == 0 {
+			return
+		}
+		t.label.SetText(string(message[:len(message)-1]))
+		if t.OnChange != nil {
+			t.OnChange(t.label.Text())
+		}
+	case inputs.KeyEnter:
+		if t.OnSubmit != nil {
+			t.OnSubmit(t.label.Text())
+		}
+	}
+}
+
+func (t *TextInput) onBlur() {
+	t.typing = false
+}
+
+// how far one notch of the mouse wheel scrolls
+const scrollStep float32 = 40.0
+const scrollbarWidth float32 = 8.0
+
+// Children top to bottom in a fixed height box, scrolled with the mouse wheel. Children that
+// aren't fully in view are hidden.
+type ScrollList struct {
+	Base
+	Spacing float32
+	Padding float32
+
+	Children []Widget
+
+	// how far down the content is scrolled, in pixels
+	scroll        float32
+	contentHeight float32
+}
+
+// Height is how much of the list is shown at once
+func NewScrollList(width float32, height float32, spacing float32, children ...Widget) *ScrollList {
+	return &ScrollList{
+		Base:     Base{Width: width, Height: height},
+		Spacing:  spacing,
+		Padding:  8.0,
+		Children: children,
+	}
+}
+
+func (s *ScrollList) Add(children ...Widget) *ScrollList {
+	s.Children = append(s.Children, children...)
+	return s
+}
+
+func (s *ScrollList) ScrollToTop() {
+	s.scroll = 0.0
+}
+
+// only the width comes from the children, the height should be fixed
+func (s *ScrollList) preferredSize() (float32, float32) {
+	var width float32
+	for _, child := range s.Children {
+		childWidth, _ := sizeOf(child)
+		width = max(width, childWidth)
+	}
+	return width + 2.0*s.Padding + scrollbarWidth, s.Height
+}
+
+func (s *ScrollList) view() Rect {
+	inner := s.rect.inset(s.Padding)
+	inner.Width = max(inner.Width-scrollbarWidth, 0.0)
+	return inner
+}
+
+// children are stretched across
+func (s *ScrollList) layout(rect Rect) {
+	s.rect = rect
+	view := s.view()
+
+	s.contentHeight = 0.0
+	for _, child := range s.Children {
+		if child.base().Hidden {
+			continue
+		}
+		_, height := sizeOf(child)
+		if s.contentHeight > 0.0 {
+			s.contentHeight += s.Spacing
+		}
+		s.contentHeight += height
+	}
+	s.scroll = min(max(s.scroll, 0.0), max(s.contentHeight-view.Height, 0.0))
+
+	next := view.Y - s.scroll
+	for _, child := range s.Children {
+		if child.base().Hidden {
+			continue
+		}
+		_, height := sizeOf(child)
+		childRect := Rect{X: view.X, Y: next, Width: view.Width, Height: height}
+		child.layout(childRect)
+		child.base().clipped = !view.holds(childRect)
+		next += height + s.Spacing
+	}
+}
+
+func (s *ScrollList) children() []Widget {
+	return s.Children
+}
+
+func (s *ScrollList) draw(r *Root, state State, hidden bool) {
+	if hidden {
+		return
+	}
+	background := screenStyle(fieldColor)
+	background.Depth = backgroundDepth
+	primitives.FillRect(rectCenter(s.rect), s.rect.Width, s.rect.Height, background)
+
+	view := s.view()
+	if s.contentHeight <= view.Height {
+		return
+	}
+	// thumb is as much of the bar as the view is of the content
+	thumbHeight := view.Height * view.Height / s.contentHeight
+	thumbY := view.Y + (view.Height-thumbHeight)*s.scroll/(s.contentHeight-view.Height)
+	thumb := Rect{X: view.X + view.Width, Y: thumbY, Width: scrollbarWidth, Height: thumbHeight}
+	primitives.FillRect(rectCenter(thumb), thumb.Width, thumb.Height, screenStyle(shaded(borderColor, state)))
+}
+
+// Scrolls just enough to show rect (a child's rect from the last layout)
+func (s *ScrollList) reveal(rect Rect) {
+	view := s.view()
+	if rect.Y < view.Y {
+		s.scroll -= view.Y - rect.Y
+	} else if rect.Y+rect.Height > view.Y+view.Height {
+		s.scroll += rect.Y + rect.Height - (view.Y + view.Height)
+	}
+}
+
+func (s *ScrollList) onScroll(amount float32) {
+	// wheel up (positive) moves toward the top
+	s.scroll -= amount * scrollStep
+}
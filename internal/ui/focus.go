@@ -0,0 +1,247 @@
+package ui
+
+// Keyboard and gamepad navigation. Any widget that uses the mouse can also have focus; the arrow
+// keys (or D-pad) move it, confirm presses the focused widget and cancel backs out (stops typing,
+// or calls the focused root's OnCancel). Where focus goes is a widget's FocusUp/Down/Left/Right if
+// set, otherwise the closest widget that way.
+
+import (
+	"math"
+	"slices"
+
+	"github.com/PatrickKoch07/game-proj/internal/inputs"
+	"github.com/PatrickKoch07/game-proj/internal/primitives"
+)
+
+// how far outside the focused widget its highlight is drawn
+const focusMargin float32 = 4.0
+
+// how much being off to the side counts against a widget, compared to being further away
+const focusSidePenalty float32 = 2.0
+
+type direction struct {
+	x float32
+	y float32
+}
+
+var navigationKeys = map[inputs.Key]direction{
+	inputs.KeyUp:            {x: 0.0, y: -1.0},
+	inputs.KeyDown:          {x: 0.0, y: 1.0},
+	inputs.KeyLeft:          {x: -1.0, y: 0.0},
+	inputs.KeyRight:         {x: 1.0, y: 0.0},
+	inputs.GamepadDpadUp:    {x: 0.0, y: -1.0},
+	inputs.GamepadDpadDown:  {x: 0.0, y: 1.0},
+	inputs.GamepadDpadLeft:  {x: -1.0, y: 0.0},
+	inputs.GamepadDpadRight: {x: 1.0, y: 0.0},
+}
+
+func isConfirmKey(key inputs.Key) bool {
+	return key == inputs.KeyEnter || key == inputs.GamepadA
+}
+
+func isCancelKey(key inputs.Key) bool {
+	return key == inputs.KeyEscape || key == inputs.GamepadB
+}
+
+// Implemented by widgets that do something when confirm is pressed on them
+type activator interface {
+	activate()
+}
+
+// Implemented by widgets that use left/right themselves while focused (ex. sliders)
+type adjuster interface {
+	adjust(steps int)
+}
+
+// The declared neighbor that way, if any
+func (b *Base) neighbor(dir direction) Widget {
+	switch {
+	case dir.y < 0.0:
+		return b.FocusUp
+	case dir.y > 0.0:
+		return b.FocusDown
+	case dir.x < 0.0:
+		return b.FocusLeft
+	default:
+		return b.FocusRight
+	}
+}
+
+// Everything in w that can take focus, in tree order. Scrolled out widgets can, their scroll list
+// scrolls to them.
+func focusables(w Widget, found []Widget) []Widget {
+	b := w.base()
+	if b.Hidden || b.Disabled {
+		return found
+	}
+	if _, ok := w.(pointerHandler); ok {
+		found = append(found, w)
+	}
+	for _, child := range w.children() {
+		found = focusables(child, found)
+	}
+	return found
+}
+
+// The focusable widget closest to from in the direction, preferring ones lined up with it.
+// Distances are between the widgets' edges, so a small widget next to a wide one still counts as
+// lined up with it.
+func closestFocusable(candidates []Widget, from Widget, dir direction) Widget {
+	fromRect := from.base().rect
+	start := fromRect.center()
+	var closest Widget
+	bestScore := float32(math.Inf(1))
+	for _, candidate := range candidates {
+		if candidate == from {
+			continue
+		}
+		rect := candidate.base().rect
+		center := rect.center()
+		// has to be that way at all (by its center)
+		if (center.X-start.X)*dir.x+(center.Y-start.Y)*dir.y <= 0.0 {
+			continue
+		}
+		gapX := max(rect.X-(fromRect.X+fromRect.Width), fromRect.X-(rect.X+rect.Width), 0.0)
+		gapY := max(rect.Y-(fromRect.Y+fromRect.Height), fromRect.Y-(rect.Y+rect.Height), 0.0)
+		along, across := gapY, gapX
+		if dir.x != 0.0 {
+			along, across = gapX, gapY
+		}
+		if score := along + focusSidePenalty*across; score < bestScore {
+			bestScore = score
+			closest = candidate
+		}
+	}
+	return closest
+}
+
+// should only be called in the main thread
+func (m *manager) setFocus(r *Root, w Widget) {
+	if r == nil {
+		w = nil
+	}
+	// moving focus with the keyboard/gamepad is their hover
+	if hovered, ok := w.(hoverHandler); ok && m.showFocus && w != m.focused {
+		hovered.onHover()
+	}
+	m.focused = w
+	m.focusedRoot = r
+	if w != nil {
+		revealIn(r.Content, w)
+	}
+}
+
+// Drops focus from widgets that can't have it anymore (hidden, disabled, removed from the tree,
+// or in a root that isn't getting input). Typing stops the same way.
+// should only be called in the main thread
+func (m *manager) checkFocus(roots []*Root) {
+	if m.typing != nil && rootOf(roots, m.typing) == nil {
+		m.setTyping(nil)
+	}
+	if m.focused == nil || m.focusedRoot == nil {
+		return
+	}
+	if !slices.Contains(roots, m.focusedRoot) {
+		m.setFocus(nil, nil)
+		return
+	}
+	for _, w := range focusables(m.focusedRoot.Content, nil) {
+		if w == m.focused {
+			return
+		}
+	}
+	m.setFocus(nil, nil)
+}
+
+// should only be called in the main thread
+func (m *manager) navigate(roots []*Root, dir direction) {
+	m.showFocus = true
+	if m.focused == nil {
+		m.focusFirst(roots)
+		return
+	}
+	if adjusted, ok := m.focused.(adjuster); ok && dir.x != 0.0 {
+		adjusted.adjust(int(dir.x))
+		return
+	}
+
+	candidates := focusables(m.focusedRoot.Content, nil)
+	next := m.focused.base().neighbor(dir)
+	for _, candidate := range candidates {
+		if next != nil && candidate == next {
+			m.setFocus(m.focusedRoot, next)
+			return
+		}
+	}
+	if next = closestFocusable(candidates, m.focused, dir); next != nil {
+		m.setFocus(m.focusedRoot, next)
+	}
+}
+
+// Focuses the first focusable widget of the top most root that has one
+// should only be called in the main thread
+func (m *manager) focusFirst(roots []*Root) {
+	for i := len(roots) - 1; i >= 0; i-- {
+		if candidates := focusables(roots[i].Content, nil); len(candidates) > 0 {
+			m.setFocus(roots[i], candidates[0])
+			return
+		}
+	}
+}
+
+// should only be called in the main thread
+func (m *manager) confirm(roots []*Root) {
+	m.showFocus = true
+	if m.focused == nil {
+		m.focusFirst(roots)
+		return
+	}
+	if _, ok := m.focused.(textHandler); ok {
+		m.setTyping(m.focused)
+		m.focused.(pointerHandler).onPress(m.focused.base().rect.center())
+	} else if activated, ok := m.focused.(activator); ok {
+		activated.activate()
+	}
+}
+
+// should only be called in the main thread
+func (m *manager) cancel(roots []*Root) {
+	if m.typing != nil {
+		m.setTyping(nil)
+		return
+	}
+	r := m.focusedRoot
+	if r == nil && len(roots) > 0 {
+		r = roots[len(roots)-1]
+	}
+	if r != nil && r.OnCancel != nil {
+		r.OnCancel()
+	}
+}
+
+// should only be called in the main thread
+func (m *manager) drawFocus() {
+	if !m.showFocus || m.focused == nil {
+		return
+	}
+	rect := m.focused.base().rect.inset(-focusMargin)
+	style := screenStyle(accentColor)
+	style.Thickness = 3.0
+	primitives.DrawRect(rectCenter(rect), rect.Width, rect.Height, style)
+}
+
+// Scrolls any scroll list holding target so target is in view. Returns if target is in w.
+func revealIn(w Widget, target Widget) bool {
+	if w == target {
+		return true
+	}
+	for _, child := range w.children() {
+		if revealIn(child, target) {
+			if list, ok := w.(*ScrollList); ok {
+				list.reveal(target.base().rect)
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,249 @@
+package ui
+
+// Containers that only place their children (see Panel and ScrollList for ones that draw too)
+
+type AnchorPoint int
+
+const (
+	TopLeft AnchorPoint = iota
+	Top
+	TopRight
+	Left
+	Center
+	Right
+	BottomLeft
+	Bottom
+	BottomRight
+)
+
+type anchored struct {
+	widget  Widget
+	anchor  AnchorPoint
+	offsetX float32
+	offsetY float32
+}
+
+// Pins each child to a point of its own rect (corner, edge middle or center), so the layout
+// doesn't depend on the screen size. Children can overlap, the last added is on top.
+type Anchor struct {
+	Base
+	items []anchored
+}
+
+func NewAnchor() *Anchor {
+	return new(Anchor)
+}
+
+// The child's matching point goes on the anchor's point, then moves by the offset (ex. Bottom
+// puts the child's bottom middle on the bottom middle of the anchor). Returns the anchor so adds
+// can be chained.
+func (a *Anchor) Add(child Widget, anchor AnchorPoint, offsetX float32, offsetY float32) *Anchor {
+	a.items = append(a.items, anchored{widget: child, anchor: anchor, offsetX: offsetX, offsetY: offsetY})
+	return a
+}
+
+// fills whatever it's given, so it has no size of its own
+func (a *Anchor) preferredSize() (float32, float32) {
+	return a.Width, a.Height
+}
+
+func (a *Anchor) layout(rect Rect) {
+	a.rect = rect
+	for _, item := range a.items {
+		width, height := sizeOf(item.widget)
+		// 0, 0.5 or 1 of the way across and down
+		alongX := float32(item.anchor%3) / 2.0
+		alongY := float32(item.anchor/3) / 2.0
+		item.widget.layout(Rect{
+			X:      rect.X + (rect.Width-width)*alongX + item.offsetX,
+			Y:      rect.Y + (rect.Height-height)*alongY + item.offsetY,
+			Width:  width,
+			Height: height,
+		})
+	}
+}
+
+func (a *Anchor) children() []Widget {
+	children := make([]Widget, len(a.items))
+	for i, item := range a.items {
+		children[i] = item.widget
+	}
+	return children
+}
+
+type Direction int
+
+const (
+	Vertical Direction = iota
+	Horizontal
+)
+
+// Where children go across a stack (left to right in a vertical one)
+type Align int
+
+const (
+	AlignStart Align = iota
+	AlignCenter
+	AlignEnd
+	// as wide (or tall) as the stack
+	AlignStretch
+)
+
+// Children one after another, top to bottom or left to right
+type Stack struct {
+	Base
+	Direction Direction
+	// between children
+	Spacing float32
+	// around all the children
+	Padding float32
+	Align   Align
+
+	Children []Widget
+}
+
+func NewStack(direction Direction, spacing float32, children ...Widget) *Stack {
+	return &Stack{Direction: direction, Spacing: spacing, Children: children}
+}
+
+func (s *Stack) Add(children ...Widget) *Stack {
+	s.Children = append(s.Children, children...)
+	return s
+}
+
+func (s *Stack) preferredSize() (float32, float32) {
+	var along, across float32
+	shown := 0
+	for _, child := range s.Children {
+		if child.base().Hidden {
+			continue
+		}
+		width, height := sizeOf(child)
+		if s.Direction == Horizontal {
+			width, height = height, width
+		}
+		along += height
+		across = max(across, width)
+		shown++
+	}
+	if shown > 1 {
+		along += s.Spacing * float32(shown-1)
+	}
+	if s.Direction == Horizontal {
+		return along + 2.0*s.Padding, across + 2.0*s.Padding
+	}
+	return across + 2.0*s.Padding, along + 2.0*s.Padding
+}
+
+func (s *Stack) layout(rect Rect) {
+	s.rect = rect
+	inner := rect.inset(s.Padding)
+	next := inner.Y
+	if s.Direction == Horizontal {
+		next = inner.X
+	}
+	for _, child := range s.Children {
+		if child.base().Hidden {
+			continue
+		}
+		width, height := sizeOf(child)
+		if s.Direction == Vertical {
+			x, width := align(s.Align, inner.X, inner.Width, width)
+			child.layout(Rect{X: x, Y: next, Width: width, Height: height})
+			next += height + s.Spacing
+		} else {
+			y, height := align(s.Align, inner.Y, inner.Height, height)
+			child.layout(Rect{X: next, Y: y, Width: width, Height: height})
+			next += width + s.Spacing
+		}
+	}
+}
+
+func (s *Stack) children() []Widget {
+	return s.Children
+}
+
+// start and size of something size long placed in a space length long
+func align(alignment Align, start float32, length float32, size float32) (float32, float32) {
+	switch alignment {
+	case AlignCenter:
+		return start + (length-size)/2.0, size
+	case AlignEnd:
+		return start + length - size, size
+	case AlignStretch:
+		return start, length
+	default:
+		return start, size
+	}
+}
+
+// Children in rows of Columns, left to right then top to bottom. Every cell is the same size.
+type Grid struct {
+	Base
+	Columns int
+	// Optional. 0 makes cells fit the biggest child
+	CellWidth  float32
+	CellHeight float32
+	Spacing    float32
+	Padding    float32
+
+	Children []Widget
+}
+
+func NewGrid(columns int, spacing float32, children ...Widget) *Grid {
+	return &Grid{Columns: columns, Spacing: spacing, Children: children}
+}
+
+func (g *Grid) Add(children ...Widget) *Grid {
+	g.Children = append(g.Children, children...)
+	return g
+}
+
+func (g *Grid) cellSize() (float32, float32) {
+	width, height := g.CellWidth, g.CellHeight
+	for _, child := range g.Children {
+		childWidth, childHeight := sizeOf(child)
+		if g.CellWidth == 0.0 {
+			width = max(width, childWidth)
+		}
+		if g.CellHeight == 0.0 {
+			height = max(height, childHeight)
+		}
+	}
+	return width, height
+}
+
+func (g *Grid) preferredSize() (float32, float32) {
+	columns := max(g.Columns, 1)
+	rows := (len(g.Children) + columns - 1) / columns
+	columns = min(columns, len(g.Children))
+	if rows == 0 {
+		return 2.0 * g.Padding, 2.0 * g.Padding
+	}
+	cellWidth, cellHeight := g.cellSize()
+	return float32(columns)*cellWidth + float32(columns-1)*g.Spacing + 2.0*g.Padding,
+		float32(rows)*cellHeight + float32(rows-1)*g.Spacing + 2.0*g.Padding
+}
+
+// children are centered in their cells. Hidden children still hold their cell
+func (g *Grid) layout(rect Rect) {
+	g.rect = rect
+	columns := max(g.Columns, 1)
+	cellWidth, cellHeight := g.cellSize()
+	inner := rect.inset(g.Padding)
+	for i, child := range g.Children {
+		column := i % columns
+		row := i / columns
+		width, height := sizeOf(child)
+		child.layout(Rect{
+			X:      inner.X + float32(column)*(cellWidth+g.Spacing) + (cellWidth-width)/2.0,
+			Y:      inner.Y + float32(row)*(cellHeight+g.Spacing) + (cellHeight-height)/2.0,
+			Width:  width,
+			Height: height,
+		})
+	}
+}
+
+func (g *Grid) children() []Widget {
+	return g.Children
+}
@@ -0,0 +1,279 @@
+package ui
+
+// Package level state held by private singleton initialized at program start.
+// Routes input to widgets. The manager is the only thing subscribed to the mouse button; every
+// frame it finds the widget under the cursor (in the top most root that has one), keeps the
+// hovered/pressed states up to date and hands presses, drags, releases, typing and the mouse wheel
+// to the widgets that want them, and moves keyboard/gamepad focus (see focus.go). Then it lays out
+// and draws every root.
+
+import (
+	"slices"
+	"sync"
+	"weak"
+
+	"github.com/PatrickKoch07/game-proj/internal/cursor"
+	"github.com/PatrickKoch07/game-proj/internal/inputs"
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+	"github.com/PatrickKoch07/game-proj/internal/scenes"
+	"github.com/PatrickKoch07/game-proj/internal/sprites"
+)
+
+// keys the manager listens to
+var routedKeys = []inputs.Key{
+	inputs.LMB, inputs.KeyBackspace, inputs.KeyEnter, inputs.KeyEscape,
+	inputs.KeyUp, inputs.KeyDown, inputs.KeyLeft, inputs.KeyRight,
+	inputs.GamepadA, inputs.GamepadB,
+	inputs.GamepadDpadUp, inputs.GamepadDpadDown, inputs.GamepadDpadLeft, inputs.GamepadDpadRight,
+}
+
+type manager struct {
+	roots []*Root
+	// the deepest widget under the cursor that uses the mouse
+	hovered Widget
+	// the widget the mouse button went down on, until it goes back up
+	pressed Widget
+	// the widget getting typing
+	typing Widget
+	// the widget keyboard/gamepad input goes to, and its root
+	focused     Widget
+	focusedRoot *Root
+	// the focus highlight is only shown once the keyboard or gamepad is used, until the mouse is
+	showFocus bool
+
+	screen        Rect
+	inputListener inputs.InputListener
+	// key actions from Notify, handled in Update (after the game objects)
+	keyActions []inputs.KeyAction
+	mu         sync.Mutex
+}
+
+var activeManager *manager
+var once sync.Once
+
+func initManager() {
+	logger.LOG.Info().Msg("Creating UI manager")
+	activeManager = new(manager)
+	activeManager.inputListener = inputs.InputListener(activeManager)
+	for _, key := range routedKeys {
+		ok := inputs.GetInputManager().Subscribe(key, weak.Make(&activeManager.inputListener))
+		if !ok {
+			logger.LOG.Error().Msgf("UI manager couldn't subscribe to key %v", key)
+		}
+	}
+}
+
+func getManager() *manager {
+	once.Do(initManager)
+	return activeManager
+}
+
+// The manager's only public part, for the main loop
+func GetManager() *manager {
+	return getManager()
+}
+
+// should only be called in the main thread
+func SetScreenSize(sWidth int, sHeight int) {
+	getManager().screen = Rect{Width: float32(sWidth), Height: float32(sHeight)}
+}
+
+// thread safe by locking
+func (m *manager) OnKeyAction(keyAction inputs.KeyAction) {
+	m.mu.Lock()
+	m.keyActions = append(m.keyActions, keyAction)
+	m.mu.Unlock()
+}
+
+// thread safe by locking
+func (m *manager) addRoot(r *Root) {
+	m.mu.Lock()
+	r.fresh = true
+	m.roots = append(m.roots, r)
+	m.mu.Unlock()
+}
+
+// Routes this frame's input, then lays out and draws every root.
+// should only be called in the main thread, after the game objects updated
+func (m *manager) Update() {
+	m.mu.Lock()
+	keyActions := m.keyActions
+	m.keyActions = nil
+	m.removeDeadRoots()
+	allRoots := slices.Clone(m.roots)
+	// roots added since the last update missed the input queued before they existed (ex. the
+	// press that opened a menu), so they only start getting it next frame. Roots in scenes under
+	// an overlay that blocks input are only drawn.
+	roots := slices.DeleteFunc(slices.Clone(allRoots), func(r *Root) bool {
+		return r.fresh || !scenes.GetGlobalScene().GetsInput(r)
+	})
+	for _, r := range allRoots {
+		r.fresh = false
+	}
+	m.mu.Unlock()
+
+	for _, r := range allRoots {
+		r.Content.layout(m.screen)
+	}
+
+	m.checkFocus(roots)
+	point := cursor.ScreenPosition()
+	m.setHovered(topmost[pointerHandler](roots, point))
+
+	inputManager := inputs.GetInputManager()
+	for _, keyAction := range keyActions {
+		m.routeKey(roots, keyAction, point)
+	}
+	if m.pressed != nil {
+		m.pressed.(pointerHandler).onDrag(point)
+	}
+	if typing, ok := m.typing.(textHandler); ok {
+		for _, char := range inputManager.TakeTyped() {
+			typing.onText(char)
+		}
+	} else {
+		inputManager.TakeTyped()
+	}
+	if amount := inputManager.TakeScroll(); amount != 0.0 {
+		if scrolled := topmost[scrollHandler](roots, point); scrolled != nil {
+			scrolled.(scrollHandler).onScroll(amount)
+		}
+	}
+
+	// callbacks and focus changes could have moved things
+	for _, r := range allRoots {
+		r.Content.layout(m.screen)
+		drawTree(r, r.Content, false, false)
+	}
+	m.drawFocus()
+}
+
+// should only be called in the main thread
+func (m *manager) routeKey(roots []*Root, keyAction inputs.KeyAction, point sprites.ScreenCoords) {
+	switch {
+	case keyAction.Key == inputs.LMB && keyAction.Action == inputs.Press:
+		m.setTyping(nil)
+		m.showFocus = false
+		if m.hovered == nil {
+			return
+		}
+		m.pressed = m.hovered
+		m.pressed.base().pressed = true
+		m.setFocus(rootOf(roots, m.pressed), m.pressed)
+		if _, ok := m.pressed.(textHandler); ok {
+			m.setTyping(m.pressed)
+		}
+		m.pressed.(pointerHandler).onPress(point)
+	case keyAction.Key == inputs.LMB && keyAction.Action == inputs.Release:
+		if m.pressed == nil {
+			return
+		}
+		pressed := m.pressed
+		m.pressed = nil
+		pressed.base().pressed = false
+		pressed.(pointerHandler).onRelease(point, pressed.base().rect.Contains(point))
+	case keyAction.Action != inputs.Press:
+		return
+	case m.typing != nil && (keyAction.Key == inputs.KeyBackspace || keyAction.Key == inputs.KeyEnter):
+		m.typing.(textHandler).onTextKey(keyAction.Key)
+		if keyAction.Key == inputs.KeyEnter {
+			m.setTyping(nil)
+		}
+	case isConfirmKey(keyAction.Key):
+		m.confirm(roots)
+	case isCancelKey(keyAction.Key):
+		m.cancel(roots)
+	default:
+		if dir, ok := navigationKeys[keyAction.Key]; ok {
+			m.setTyping(nil)
+			m.navigate(roots, dir)
+		}
+	}
+}
+
+// should only be called in the main thread
+func (m *manager) setHovered(w Widget) {
+	if m.hovered == w {
+		return
+	}
+	if m.hovered != nil {
+		m.hovered.base().hovered = false
+	}
+	m.hovered = w
+	if w != nil {
+		w.base().hovered = true
+	}
+	if hovered, ok := w.(hoverHandler); ok {
+		hovered.onHover()
+	}
+}
+
+// should only be called in the main thread
+func (m *manager) setTyping(w Widget) {
+	if m.typing == w {
+		return
+	}
+	if typing, ok := m.typing.(textHandler); ok {
+		typing.onBlur()
+	}
+	m.typing = w
+}
+
+// The deepest widget implementing T under point, in the top most root that has one. Roots without
+// one there (ex. only labels under the cursor) let the mouse through to the roots below.
+func topmost[T any](roots []*Root, point sprites.ScreenCoords) Widget {
+	for i := len(roots) - 1; i >= 0; i-- {
+		if w := deepest[T](hitTest(roots[i].Content, point)); w != nil {
+			return w
+		}
+	}
+	return nil
+}
+
+// Must be locked. Dead roots' widgets lose any hover/press/typing they had.
+func (m *manager) removeDeadRoots() {
+	m.roots = slices.DeleteFunc(m.roots, func(r *Root) bool {
+		if !r.IsDead() {
+			return false
+		}
+		walk(r.Content, func(w Widget) {
+			if w == m.hovered {
+				m.hovered = nil
+			}
+			if w == m.pressed {
+				m.pressed = nil
+			}
+			if w == m.typing {
+				m.typing = nil
+			}
+			if w == m.focused {
+				m.focused = nil
+				m.focusedRoot = nil
+			}
+		})
+		r.clear()
+		return true
+	})
+}
+
+// The root w is in
+func rootOf(roots []*Root, w Widget) *Root {
+	for _, r := range roots {
+		found := false
+		walk(r.Content, func(child Widget) { found = found || child == w })
+		if found {
+			return r
+		}
+	}
+	return nil
+}
+
+// The last widget in path implementing T
+func deepest[T any](path []Widget) Widget {
+	for i := len(path) - 1; i >= 0; i-- {
+		if _, ok := path[i].(T); ok {
+			return path[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"slices"
+	"sync/atomic"
+	"weak"
+
+	"github.com/PatrickKoch07/game-proj/internal/audio"
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+	"github.com/PatrickKoch07/game-proj/internal/scenes"
+	"github.com/PatrickKoch07/game-proj/internal/sprites"
+)
+
+// Top of a widget tree, laid out over the whole screen. Add it to a scene like any game object
+// (ex. scenes.InitOnScene) and it lives as long as the scene does. Roots added later are on top.
+type Root struct {
+	Content Widget
+	// Optional. Called when cancel (escape, gamepad B) is pressed while this root has focus or is
+	// on top (ex. close the menu)
+	OnCancel func()
+
+	// everything the widgets made, so it can all be cleaned up with the root
+	sprites      []*sprites.Sprite
+	audioPlayers []audio.Player
+	dead         atomic.Bool
+	// added since the manager last updated
+	fresh bool
+}
+
+func NewRoot(content Widget) *Root {
+	return &Root{Content: content}
+}
+
+// Sprites and audio are made the first time the widgets are drawn (on the main thread), so none
+// are handed to the scene here. The root cleans them up itself.
+func (r *Root) InitInstance() ([]scenes.GameObject, []*sprites.Sprite, []audio.Player, bool) {
+	if r.Content == nil {
+		logger.LOG.Error().Msg("UI root has no content")
+		return nil, nil, nil, false
+	}
+	getManager().addRoot(r)
+	return []scenes.GameObject{r}, []*sprites.Sprite{}, []audio.Player{}, true
+}
+
+// the manager updates roots on the main thread instead
+func (r *Root) ShouldSkipUpdate() bool {
+	return true
+}
+
+func (r *Root) Update() {}
+
+// The manager cleans up on the main thread, the next time it updates
+func (r *Root) Kill() {
+	r.dead.Store(true)
+}
+
+func (r *Root) IsDead() bool {
+	return r.dead.Load()
+}
+
+// Starts drawing a sprite made by one of the root's widgets
+// should only be called in the main thread
+func (r *Root) addSprites(newSprites ...*sprites.Sprite) {
+	for _, sprite := range newSprites {
+		if sprite == nil {
+			continue
+		}
+		r.sprites = append(r.sprites, sprite)
+		sprites.GetDrawQueue().AddToQueue(weak.Make(sprite))
+	}
+}
+
+// Stops drawing and forgets sprites the widgets don't need anymore (ex. old text)
+// should only be called in the main thread
+func (r *Root) removeSprites(oldSprites ...*sprites.Sprite) {
+	for _, sprite := range oldSprites {
+		if sprite == nil {
+			continue
+		}
+		err := sprite.Clear()
+		if err != nil {
+			logger.LOG.Warn().Err(err).Msg("Trying to continue anyway")
+		}
+	}
+	r.sprites = slices.DeleteFunc(r.sprites, func(sprite *sprites.Sprite) bool {
+		return slices.Contains(oldSprites, sprite)
+	})
+}
+
+// should only be called in the main thread
+func (r *Root) addAudio(audioPlayer audio.Player) {
+	r.audioPlayers = append(r.audioPlayers, audioPlayer)
+}
+
+// should only be called in the main thread
+func (r *Root) clear() {
+	r.removeSprites(r.sprites...)
+	for _, audioPlayer := range r.audioPlayers {
+		err := audioPlayer.Clear()
+		if err != nil {
+			logger.LOG.Warn().Err(err).Msg("Trying to continue anyway")
+		}
+	}
+	r.audioPlayers = nil
+}
@@ -0,0 +1,202 @@
+package ui
+
+// Retained mode UI. A menu is a tree of widgets that sticks around between frames: containers
+// (Anchor, Stack, Grid, Panel, ScrollList) place their children every frame, and the manager
+// (see manager.go) routes the mouse and typing to whatever widget is under the cursor, so widgets
+// never hit test on their own.
+//
+// Widgets are NOT thread safe. Build the tree before its Root is added to a scene, then only
+// change widgets from game object Updates or from widget callbacks (those run on the main thread).
+
+import (
+	"github.com/PatrickKoch07/game-proj/internal/inputs"
+	"github.com/PatrickKoch07/game-proj/internal/sprites"
+)
+
+// In screen pixels. X, Y is the top left corner
+type Rect struct {
+	X      float32
+	Y      float32
+	Width  float32
+	Height float32
+}
+
+func (r Rect) Contains(point sprites.ScreenCoords) bool {
+	return point.X >= r.X && point.X <= r.X+r.Width && point.Y >= r.Y && point.Y <= r.Y+r.Height
+}
+
+func (r Rect) center() sprites.ScreenCoords {
+	return sprites.ScreenCoords{X: r.X + r.Width/2.0, Y: r.Y + r.Height/2.0}
+}
+
+func (r Rect) inset(padding float32) Rect {
+	return Rect{
+		X:      r.X + padding,
+		Y:      r.Y + padding,
+		Width:  max(r.Width-2.0*padding, 0.0),
+		Height: max(r.Height-2.0*padding, 0.0),
+	}
+}
+
+// if all of inner is inside r
+func (r Rect) holds(inner Rect) bool {
+	return inner.X >= r.X && inner.Y >= r.Y &&
+		inner.X+inner.Width <= r.X+r.Width && inner.Y+inner.Height <= r.Y+r.Height
+}
+
+type State int
+
+const (
+	Normal State = iota
+	Hovered
+	Pressed
+	Disabled
+)
+
+type Widget interface {
+	// the parts every widget has, see Base
+	base() *Base
+	// size the widget would like. Its parent decides what it actually gets
+	preferredSize() (float32, float32)
+	// places the widget (and its children) in rect
+	layout(rect Rect)
+	// draws the widget itself, not its children. Called every frame, on the main thread
+	draw(r *Root, state State, hidden bool)
+	children() []Widget
+}
+
+// Embedded in every widget
+type Base struct {
+	// Optional. Fixed size, 0 lets the widget's content decide
+	Width  float32
+	Height float32
+	// disabled widgets (and everything in them) ignore input and are drawn dimmed
+	Disabled bool
+	// hidden widgets (and everything in them) aren't drawn, take no space and ignore input
+	Hidden bool
+	// Optional. Where keyboard/gamepad focus goes from this widget, nil picks the closest widget
+	// that way (see focus.go)
+	FocusUp    Widget
+	FocusDown  Widget
+	FocusLeft  Widget
+	FocusRight Widget
+
+	rect Rect
+	// set by the manager
+	hovered bool
+	pressed bool
+	// set by scroll lists on children scrolled out of view
+	clipped bool
+}
+
+func (b *Base) base() *Base {
+	return b
+}
+
+// Where the widget was placed this frame
+func (b *Base) Rect() Rect {
+	return b.rect
+}
+
+func (b *Base) preferredSize() (float32, float32) {
+	return b.Width, b.Height
+}
+
+func (b *Base) layout(rect Rect) {
+	b.rect = rect
+}
+
+func (b *Base) draw(r *Root, state State, hidden bool) {}
+
+func (b *Base) children() []Widget {
+	return nil
+}
+
+// Implemented by widgets that use the mouse
+type pointerHandler interface {
+	onPress(point sprites.ScreenCoords)
+	// every frame the button is held after pressing this widget, wherever the cursor is
+	onDrag(point sprites.ScreenCoords)
+	// inside is if the cursor is still over the widget
+	onRelease(point sprites.ScreenCoords, inside bool)
+}
+
+// Implemented by widgets that take typing. They get it after being clicked, until something
+// else is clicked
+type textHandler interface {
+	onText(char rune)
+	onTextKey(key inputs.Key)
+	// typing moved to another widget
+	onBlur()
+}
+
+// Implemented by widgets that react to the cursor, or keyboard/gamepad focus, arriving on them
+type hoverHandler interface {
+	onHover()
+}
+
+// Implemented by widgets that use the mouse wheel
+type scrollHandler interface {
+	onScroll(amount float32)
+}
+
+// The widget's size after its fixed Width/Height. Hidden widgets take no space.
+func sizeOf(w Widget) (float32, float32) {
+	b := w.base()
+	if b.Hidden {
+		return 0.0, 0.0
+	}
+	width, height := w.preferredSize()
+	if b.Width > 0.0 {
+		width = b.Width
+	}
+	if b.Height > 0.0 {
+		height = b.Height
+	}
+	return width, height
+}
+
+// Draws w and its children, parents first so children end up on top
+// should only be called in the main thread
+func drawTree(r *Root, w Widget, disabled bool, hidden bool) {
+	b := w.base()
+	disabled = disabled || b.Disabled
+	hidden = hidden || b.Hidden || b.clipped
+
+	state := Normal
+	if disabled {
+		state = Disabled
+	} else if b.pressed {
+		state = Pressed
+	} else if b.hovered {
+		state = Hovered
+	}
+	w.draw(r, state, hidden)
+	for _, child := range w.children() {
+		drawTree(r, child, disabled, hidden)
+	}
+}
+
+// The widgets under point from w down to the deepest one, skipping anything hidden or disabled.
+// Later children are drawn on top, so they are checked first.
+func hitTest(w Widget, point sprites.ScreenCoords) []Widget {
+	b := w.base()
+	if b.Hidden || b.clipped || b.Disabled || !b.rect.Contains(point) {
+		return nil
+	}
+	children := w.children()
+	for i := len(children) - 1; i >= 0; i-- {
+		if path := hitTest(children[i], point); path != nil {
+			return append([]Widget{w}, path...)
+		}
+	}
+	return []Widget{w}
+}
+
+// Calls visit on w and everything in it
+func walk(w Widget, visit func(Widget)) {
+	visit(w)
+	for _, child := range w.children() {
+		walk(child, visit)
+	}
+}
@@ -0,0 +1,253 @@
+package ui
+
+import (
+	"github.com/PatrickKoch07/game-proj/internal/logger"
+	"github.com/PatrickKoch07/game-proj/internal/primitives"
+	"github.com/PatrickKoch07/game-proj/internal/sprites"
+	"github.com/PatrickKoch07/game-proj/internal/text"
+)
+
+// Panel backgrounds go behind the UI sprites (buttons, images), everything else the widgets draw
+// with primitives uses the usual screen space depth: in front of UI sprites, behind text.
+const backgroundDepth float32 = -0.85
+
+// the font is square
+const glyphSize float32 = 16.0
+
+var panelColor = sprites.Color{R: 0.1, G: 0.1, B: 0.15, A: 0.85}
+var borderColor = sprites.Color{R: 0.85, G: 0.85, B: 0.85, A: 1.0}
+var fieldColor = sprites.Color{R: 0.05, G: 0.05, B: 0.05, A: 0.9}
+var accentColor = sprites.Color{R: 0.35, G: 0.65, B: 1.0, A: 1.0}
+
+// how much a state darkens what the widget draws (tints can't make things brighter, so hover is
+// the full color and everything else is darker)
+func stateShade(state State) float32 {
+	switch state {
+	case Hovered:
+		return 1.0
+	case Pressed:
+		return 0.7
+	case Disabled:
+		return 0.45
+	default:
+		return 0.85
+	}
+}
+
+func shaded(color sprites.Color, state State) sprites.Color {
+	shade := stateShade(state)
+	return sprites.Color{R: color.R * shade, G: color.G * shade, B: color.B * shade, A: color.A}
+}
+
+func screenStyle(color sprites.Color) primitives.Style {
+	return primitives.Style{Color: color, Space: primitives.ScreenSpace}
+}
+
+func rectCenter(rect Rect) primitives.Point {
+	return primitives.Point(rect.center())
+}
+
+// should only be called in the main thread (gl)
+func createUiSprite(textureRelPath string) (*sprites.Sprite, error) {
+	return sprites.CreateSprite(
+		&sprites.SpriteInitParams{
+			ShaderRelPaths: sprites.ShaderFiles{
+				VertexPath:   "uiShader.vs",
+				FragmentPath: "alphaTextureShader.fs",
+			},
+			TextureRelPath: textureRelPath,
+			TextureCoords:  sprites.TexCoordOneSpritePerImg,
+			SpriteCenter:   sprites.SpriteCoords{X: 0.0, Y: 0.0},
+			// Tex Dim is set to the widget's size every frame anyway
+			StretchX: 1.0,
+			StretchY: 1.0,
+		},
+	)
+}
+
+// Stretches the sprite over rect
+func placeSprite(sprite *sprites.Sprite, rect Rect, tint sprites.Color, state State, hidden bool) {
+	sprite.ScreenCenter = sprites.ScreenCoords{X: rect.X, Y: rect.Y}
+	sprite.Tex.DimX = rect.Width
+	sprite.Tex.DimY = rect.Height
+	sprite.Tint = shaded(tint, state)
+	sprite.Opacity = 1.0
+	if hidden {
+		sprite.Opacity = 0.0
+	}
+}
+
+// Background (and optional border) around one child
+type Panel struct {
+	Base
+	Color sprites.Color
+	// Optional. Not drawn if see-through
+	BorderColor sprites.Color
+	// between the edge and the content
+	Padding float32
+	// Optional. Gets all the space inside the padding
+	Content Widget
+}
+
+func NewPanel(content Widget) *Panel {
+	return &Panel{Color: panelColor, BorderColor: borderColor, Padding: 16.0, Content: content}
+}
+
+func (p *Panel) preferredSize() (float32, float32) {
+	if p.Content == nil {
+		return 2.0 * p.Padding, 2.0 * p.Padding
+	}
+	width, height := sizeOf(p.Content)
+	return width + 2.0*p.Padding, height + 2.0*p.Padding
+}
+
+func (p *Panel) layout(rect Rect) {
+	p.rect = rect
+	if p.Content != nil {
+		p.Content.layout(rect.inset(p.Padding))
+	}
+}
+
+func (p *Panel) children() []Widget {
+	if p.Content == nil {
+		return nil
+	}
+	return []Widget{p.Content}
+}
+
+// panels don't react to the mouse, so they only look disabled (never hovered or pressed)
+func (p *Panel) draw(r *Root, state State, hidden bool) {
+	if hidden {
+		return
+	}
+	if state != Disabled {
+		state = Hovered
+	}
+	style := screenStyle(shaded(p.Color, state))
+	style.Depth = backgroundDepth
+	primitives.FillRect(rectCenter(p.rect), p.rect.Width, p.rect.Height, style)
+	if p.BorderColor.A > 0.0 {
+		style.Color = shaded(p.BorderColor, state)
+		style.Thickness = 2.0
+		primitives.DrawRect(rectCenter(p.rect), p.rect.Width, p.rect.Height, style)
+	}
+}
+
+// Text. Only has the characters in the font (see text.HasGlyph)
+type Label struct {
+	Base
+	Scale float32
+	// Multiplied with the font's color
+	Color sprites.Color
+	// where the text goes across the label's rect. Always centered top to bottom
+	Align Align
+	// characters per line before wrapping
+	MaxLineLength int
+
+	text string
+	// sprites for shownText, placed at shownAt
+	glyphs    []*sprites.Sprite
+	shownText string
+	shownAt   sprites.ScreenCoords
+}
+
+// Centered white text on one line
+func NewLabel(message string, scale float32) *Label {
+	return &Label{
+		Scale:         scale,
+		Color:         sprites.Color{R: 1.0, G: 1.0, B: 1.0, A: 1.0},
+		Align:         AlignCenter,
+		MaxLineLength: 1000,
+		text:          message,
+	}
+}
+
+func (l *Label) Text() string {
+	return l.text
+}
+
+// The sprites are remade on the next draw
+func (l *Label) SetText(message string) {
+	l.text = message
+}
+
+func (l *Label) preferredSize() (float32, float32) {
+	return text.MeasureText(l.text, l.Scale, l.MaxLineLength)
+}
+
+// remakes the glyph sprites when the text changed and moves them when the label did
+func (l *Label) draw(r *Root, state State, hidden bool) {
+	width, height := l.preferredSize()
+	x, _ := align(l.Align, l.rect.X, l.rect.Width, width)
+	topLeft := sprites.ScreenCoords{X: x, Y: l.rect.Y + (l.rect.Height-height)/2.0}
+
+	if l.text != l.shownText || (l.glyphs == nil && l.text != "") {
+		r.removeSprites(l.glyphs...)
+		glyphs, ok := text.TextToSprites(l.text, topLeft, l.Scale, l.MaxLineLength)
+		if !ok {
+			logger.LOG.Error().Msgf("failed to make some of the sprites for %q. trying anyway", l.text)
+		}
+		r.addSprites(glyphs...)
+		l.glyphs = glyphs
+		l.shownText = l.text
+		l.shownAt = topLeft
+	}
+
+	for _, glyph := range l.glyphs {
+		if glyph == nil {
+			continue
+		}
+		glyph.ScreenCenter.X += topLeft.X - l.shownAt.X
+		glyph.ScreenCenter.Y += topLeft.Y - l.shownAt.Y
+		glyph.Tint = l.Color
+		if state == Disabled {
+			glyph.Tint = shaded(l.Color, Disabled)
+		}
+		glyph.Opacity = 1.0
+		if hidden {
+			glyph.Opacity = 0.0
+		}
+	}
+	l.shownAt = topLeft
+}
+
+// A texture stretched over the widget
+type Image struct {
+	Base
+	TextureRelPath string
+	// Multiplied with the texture color
+	Tint sprites.Color
+
+	sprite *sprites.Sprite
+	// so a missing texture is only complained about once
+	failed bool
+}
+
+func NewImage(textureRelPath string, width float32, height float32) *Image {
+	return &Image{
+		Base:           Base{Width: width, Height: height},
+		TextureRelPath: textureRelPath,
+		Tint:           sprites.Color{R: 1.0, G: 1.0, B: 1.0, A: 1.0},
+	}
+}
+
+func (i *Image) draw(r *Root, state State, hidden bool) {
+	if i.sprite == nil && !i.failed {
+		sprite, err := createUiSprite(i.TextureRelPath)
+		if err != nil {
+			logger.LOG.Error().Err(err).Msgf("UI image %v", i.TextureRelPath)
+			i.failed = true
+			return
+		}
+		i.sprite = sprite
+		r.addSprites(sprite)
+	}
+	if i.sprite == nil {
+		return
+	}
+	// images don't react to the mouse, so they only look disabled
+	if state != Disabled {
+		state = Hovered
+	}
+	placeSprite(i.sprite, i.rect, i.Tint, state, hidden)
+}
@@ -1,17 +1,24 @@
 package main
 
 import (
+	"flag"
 	"runtime"
 	"time"
 
+	"github.com/PatrickKoch07/game-proj/internal/assets"
 	"github.com/PatrickKoch07/game-proj/internal/camera"
+	"github.com/PatrickKoch07/game-proj/internal/colliders"
 	"github.com/PatrickKoch07/game-proj/internal/cursor"
+	"github.com/PatrickKoch07/game-proj/internal/debugOverlay"
 	"github.com/PatrickKoch07/game-proj/internal/gameState"
 	"github.com/PatrickKoch07/game-proj/internal/inputs"
 	"github.com/PatrickKoch07/game-proj/internal/logger"
+	"github.com/PatrickKoch07/game-proj/internal/physics"
 	"github.com/PatrickKoch07/game-proj/internal/scenes"
 	"github.com/PatrickKoch07/game-proj/internal/sprites"
+	"github.com/PatrickKoch07/game-proj/internal/ui"
 
+	"github.com/PatrickKoch07/game-proj/internal/myGame/gameCharacters"
 	"github.com/PatrickKoch07/game-proj/internal/myGame/gameScenes"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
@@ -22,6 +29,10 @@ const TARGET_FPS float64 = 60.0
 const SCREEN_X int = 1280
 const SCREEN_Y int = 960
 
+// reloads shaders, textures and audio when they change on disk. Off unless run with -dev, so
+// release builds never watch the assets
+var DEV_MODE = flag.Bool("dev", false, "reload assets when they change on disk")
+
 func init() {
 	logger.LOG.Info().Msg("Init main")
 	// for rendering & window
@@ -43,42 +54,88 @@ func initGLFW() {
 }
 
 func main() {
+	flag.Parse()
 	defer glfw.Terminate()
 	window := createWindow()
 
 	// holds current scene and game objects
 	InputManager := inputs.GetInputManager()
 	DrawQueue := sprites.GetDrawQueue()
+	PostProcessor := sprites.GetPostProcessor()
+	setupPostProcessing()
+	colliders.SetCollisionRules(gameCharacters.CollisionRules)
 	GlobalScene := scenes.GetGlobalScene()
+	GlobalScene.SetSceneAssets(gameScenes.GetSceneAssets())
 	GlobalScene.InitializeGlobalScene(
 		gameScenes.GetSceneMap(),
 		gameState.TitleScene,
 		gameState.LoadingScene,
 	)
 	GameState := gameState.GetCurrentGameState()
+	PhysicsWorld := physics.GetWorld()
+	DebugOverlay := debugOverlay.GetOverlay()
+	UiManager := ui.GetManager()
+	var assetWatcher *assets.Watcher
+	if *DEV_MODE {
+		assetWatcher = assets.WatchForChanges("assets", 500*time.Millisecond)
+		defer assetWatcher.Stop()
+	}
 	// Logger to sample fps every second
 	for capFPS := setupFramerateCap(); !window.ShouldClose(); capFPS() {
 		// deal with inputs
 		glfw.PollEvents()
+		InputManager.PollGamepad()
 		InputManager.Notify()
 
+		if assetWatcher != nil {
+			assetWatcher.ApplyChanges()
+		}
+
 		// set any changes to the gamestate since the last scene update
 		GameState.UpdateCurrentContext()
 
+		// move bodies, so game objects see where they ended up
+		PhysicsWorld.Update()
+
 		// update objects
 		GlobalScene.Update()
+		// then move their colliders, in the same order every frame
+		colliders.ResolveMoves()
+		// menus get the mouse and typing after the game objects are done changing them
+		UiManager.Update()
 
-		// clear previous rendering
+		// clear previous rendering (of the offscreen framebuffer if post processing)
+		PostProcessor.BeginFrame()
 		gl.Clear(gl.COLOR_BUFFER_BIT)
 		gl.Clear(gl.DEPTH_BUFFER_BIT)
 		// draw
+		DebugOverlay.Draw()
 		DrawQueue.Draw()
+		PostProcessor.EndFrame()
 		window.SwapBuffers()
 	}
 
 	GlobalScene.Kill()
 }
 
+// Screen effects game objects can turn on with GetPostProcessor().GetPass(name). They all start
+// disabled, so frames skip the offscreen framebuffer until something is turned on.
+func setupPostProcessing() {
+	passes := [][2]string{
+		{"colorGrading", "postProcess/colorGrading.fs"},
+		{"vignette", "postProcess/vignette.fs"},
+		{"crt", "postProcess/crt.fs"},
+	}
+	for _, pass := range passes {
+		postProcessPass, err := sprites.GetPostProcessor().AddPass(pass[0], pass[1])
+		if err != nil {
+			logger.LOG.Error().Err(err).Msgf("Failed to make %v post process pass", pass[0])
+			continue
+		}
+		postProcessPass.SetEnabled(false)
+	}
+}
+
 func createWindow() *glfw.Window {
 	logger.LOG.Info().Msg("Creating new window")
 
@@ -101,12 +158,15 @@ func createWindow() *glfw.Window {
 	cursor.SetScreenSize(SCREEN_X, SCREEN_Y)
 	sprites.SetScreenSize(SCREEN_X, SCREEN_Y)
 	camera.InitializeCamera(SCREEN_X, SCREEN_Y)
+	ui.SetScreenSize(SCREEN_X, SCREEN_Y)
 
 	logger.LOG.Info().Msg("Setting window callbacks")
 	window.SetFocusCallback(captureMouseFocusCallback)
 	window.SetCursorPosCallback(cursor.UpdateMousePosCallback)
 	window.SetKeyCallback(inputs.InputKeysCallback)
 	window.SetMouseButtonCallback(inputs.InputMouseCallback)
+	window.SetCharCallback(inputs.InputCharCallback)
+	window.SetScrollCallback(inputs.InputScrollCallback)
 
 	window.Focus()
 